@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"elephant/internal/core"
+	"elephant/internal/features"
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `usage:
+  elephant                  open the notes in ELEPHANT_NOTES_DIR
+  elephant import SOURCE    copy the notes at SOURCE into ELEPHANT_NOTES_DIR
+  elephant export TARGET    copy the notes in ELEPHANT_NOTES_DIR to TARGET
+
+SOURCE and TARGET are a notes directory or a URL such as sqlite:///home/me/notes.db`
+
+// runCommand - run the command line subcommand in args and return the exit code
+func runCommand(args []string) int {
+	if len(args) != 2 || (args[0] != "import" && args[0] != "export") {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	notes, err := features.NotesLocation()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "elephant:", err)
+		return 1
+	}
+
+	other, err := core.ParseLocation(args[1], core.BackendFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "elephant:", err)
+		return 1
+	}
+
+	from, to := other, notes
+	if args[0] == "export" {
+		from, to = notes, other
+	}
+
+	copied, err := copyNotes(from, to)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "elephant:", err)
+		return 1
+	}
+
+	fmt.Printf("Copied %d notes from %s to %s\n", copied, from, to)
+	return 0
+}
+
+func copyNotes(from, to core.Location) (int, error) {
+	source, err := core.OpenRepository(from)
+	if err != nil {
+		return 0, err
+	}
+	defer closeRepository(source)
+
+	target, err := core.OpenRepository(to)
+	if err != nil {
+		return 0, err
+	}
+	defer closeRepository(target)
+
+	return core.CopyNotes(context.Background(), source, target)
+}
+
+func closeRepository(repository core.Repository) {
+	if closer, ok := repository.(io.Closer); ok {
+		_ = closer.Close()
+	}
+}
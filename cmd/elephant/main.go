@@ -2,6 +2,7 @@ package main
 
 import (
 	"elephant/internal/app"
+	"fmt"
 	"log"
 	"log/slog"
 	"os"
@@ -12,10 +13,24 @@ import (
 func main() {
 	setupLogging()
 
-	model := app.NewModel()
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
+	model, err := app.NewModel()
+	if err != nil {
+		slog.Error("failed to open the notes", "err", err)
+		fmt.Fprintln(os.Stderr, "elephant:", err)
+		os.Exit(1)
+	}
 	program := tea.NewProgram(&model, tea.WithAltScreen())
 
-	if _, err := program.Run(); err != nil {
+	_, err = program.Run()
+	if closeErr := model.Close(); closeErr != nil {
+		slog.Error("failed to release the notes directory", "err", closeErr)
+	}
+
+	if err != nil {
 		slog.Error("Something went wrong, exiting...", "err", err)
 		os.Exit(1)
 	}
@@ -9,12 +9,15 @@ type Model struct {
 	notesFeature *features.NotesFeature
 }
 
-func NewModel() Model {
-	notesFeature := features.NewFeature()
+func NewModel() (Model, error) {
+	notesFeature, err := features.NewFeature()
+	if err != nil {
+		return Model{}, err
+	}
 
 	return Model{
 		notesFeature: &notesFeature,
-	}
+	}, nil
 }
 
 func (m *Model) Init() tea.Cmd {
@@ -29,6 +32,11 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	)
 }
 
+// Close - release what the features hold on to once the program exits
+func (m *Model) Close() error {
+	return m.notesFeature.Close()
+}
+
 func (m *Model) View() string {
 	return m.notesFeature.View()
 }
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"sort"
+	"time"
+)
+
+// AgendaGroup - when an open task is due, relative to today
+type AgendaGroup int
+
+const (
+	Overdue AgendaGroup = iota
+	DueToday
+	DueThisWeek
+	DueLater
+)
+
+func (g AgendaGroup) String() string {
+	switch g {
+	case Overdue:
+		return "Overdue"
+	case DueToday:
+		return "Today"
+	case DueThisWeek:
+		return "This week"
+	default:
+		return "Later"
+	}
+}
+
+// AgendaSection - the tasks of an agenda group
+type AgendaSection struct {
+	Group AgendaGroup
+	Tasks []Task
+}
+
+// Agenda - the open tasks with a due date, grouped by when they are due relative to today, this week running up to
+// Sunday; ordered by due date, then by priority, highest first, then by note and line. Empty groups are left out
+func Agenda(tasks []Task, today time.Time) []AgendaSection {
+	today = Today(today)
+	endOfWeek := today.AddDate(0, 0, (7-int(today.Weekday()))%7)
+
+	var due []Task
+	for _, task := range tasks {
+		if !task.Done && !task.Due.IsZero() {
+			due = append(due, task)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		a, b := due[i], due[j]
+		switch {
+		case !a.Due.Equal(b.Due):
+			return a.Due.Before(b.Due)
+		case a.Priority != b.Priority:
+			return a.Priority > b.Priority
+		case a.Note.RelativePath() != b.Note.RelativePath():
+			return a.Note.RelativePath() < b.Note.RelativePath()
+		}
+		return a.Line < b.Line
+	})
+
+	var sections []AgendaSection
+	for _, task := range due {
+		group := DueLater
+		switch {
+		case task.Due.Before(today):
+			group = Overdue
+		case task.Due.Equal(today):
+			group = DueToday
+		case !task.Due.After(endOfWeek):
+			group = DueThisWeek
+		}
+
+		if len(sections) == 0 || sections[len(sections)-1].Group != group {
+			sections = append(sections, AgendaSection{Group: group})
+		}
+		sections[len(sections)-1].Tasks = append(sections[len(sections)-1].Tasks, task)
+	}
+
+	return sections
+}
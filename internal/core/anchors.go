@@ -0,0 +1,152 @@
+package core
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ErrNoAnchor - the note has no heading or block the link fragment names
+var ErrNoAnchor = errors.New("heading or block not found")
+
+var (
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	// blockIDPattern - a ^block id ending a line, or standing on a line of its own after the block it names
+	blockIDPattern = regexp.MustCompile(`(?:^|\s)\^([A-Za-z0-9-]+)\s*$`)
+)
+
+// Section - the part of body the fragment of a link points to: a heading with the lines below it up to the next
+// heading of the same or a higher level, or the paragraph or list item a ^block id ends; ErrNoAnchor when body has none
+func Section(body, fragment string) (string, error) {
+	lines := strings.Split(body, "\n")
+	code := fencedLines(lines)
+
+	if id, ok := strings.CutPrefix(fragment, "^"); ok {
+		return blockSection(lines, code, id)
+	}
+
+	wanted := headingSlug(fragment)
+	for i, line := range lines {
+		level, text, ok := heading(line)
+		if code[i] || !ok || headingSlug(text) != wanted {
+			continue
+		}
+
+		end := len(lines)
+		for j := i + 1; j < len(lines); j++ {
+			if next, _, ok := heading(lines[j]); ok && !code[j] && next <= level {
+				end = j
+				break
+			}
+		}
+
+		return strings.TrimRight(strings.Join(lines[i:end], "\n"), "\n"), nil
+	}
+
+	return "", ErrNoAnchor
+}
+
+// blockSection - the block ^id names: the list item or paragraph ending with it, or the one before a line holding
+// only the id
+func blockSection(lines []string, code []bool, id string) (string, error) {
+	for i, line := range lines {
+		match := blockIDPattern.FindStringSubmatch(line)
+		if code[i] || match == nil || match[1] != id {
+			continue
+		}
+
+		end := i
+		if strings.TrimSpace(line) == "^"+id {
+			for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+				end--
+			}
+			end--
+			if end < 0 {
+				return "", ErrNoAnchor
+			}
+		}
+
+		start := end
+		if !listItemPattern.MatchString(lines[end]) {
+			for start > 0 && !code[start-1] && strings.TrimSpace(lines[start-1]) != "" && !listItemPattern.MatchString(lines[start-1]) {
+				if _, _, ok := heading(lines[start-1]); ok {
+					break
+				}
+				start--
+			}
+		}
+
+		return StripBlockIDs(strings.Join(lines[start:end+1], "\n")), nil
+	}
+
+	return "", ErrNoAnchor
+}
+
+var listItemPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s`)
+
+// StripBlockIDs - text without the ^block ids ending its lines, which only serve as link targets; lines holding only
+// an id are dropped and code is left alone
+func StripBlockIDs(text string) string {
+	lines := strings.Split(text, "\n")
+	code := fencedLines(lines)
+
+	kept := lines[:0]
+	for i, line := range lines {
+		if !code[i] {
+			if location := blockIDPattern.FindStringIndex(line); location != nil {
+				if strings.TrimSpace(line[:location[0]]) == "" {
+					continue
+				}
+				line = strings.TrimRight(line[:location[0]], " \t")
+			}
+		}
+		kept = append(kept, line)
+	}
+
+	return strings.Join(kept, "\n")
+}
+
+// fencedLines - which of lines are fenced code, fences included
+func fencedLines(lines []string) []bool {
+	code := make([]bool, len(lines))
+
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			code[i] = true
+			inFence = !inFence
+			continue
+		}
+		code[i] = inFence
+	}
+
+	return code
+}
+
+// heading - the level and text of the ATX heading on line
+func heading(line string) (int, string, bool) {
+	match := headingPattern.FindStringSubmatch(line)
+	if match == nil {
+		return 0, "", false
+	}
+
+	return len(match[1]), match[2], true
+}
+
+// headingSlug - heading text reduced to lower cased letters, digits and dashes, so [[Note#My Heading]] and
+// [text](note.md#my-heading) both name "## My heading"
+func headingSlug(text string) string {
+	var slug strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			slug.WriteRune(r)
+		case unicode.IsSpace(r):
+			slug.WriteRune('-')
+		}
+	}
+
+	return slug.String()
+}
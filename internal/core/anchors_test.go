@@ -0,0 +1,48 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSection(t *testing.T) {
+	body := "# Plan\nIntro.\n\n## Goals\nShip it.\n\n### Stretch\nMore.\n\n```\n## Not a heading\n```\n\n## Risks\n" +
+		"Delays happen\nfrom time to time. ^delays\n\n- first item\n- second item ^second\n\nA table\n\n^table\n"
+
+	for _, test := range []struct {
+		fragment string
+		expected string
+	}{
+		{"Goals", "## Goals\nShip it.\n\n### Stretch\nMore.\n\n```\n## Not a heading\n```"},
+		{"stretch", "### Stretch\nMore.\n\n```\n## Not a heading\n```"},
+		{"my-heading", ""},
+		{"^delays", "Delays happen\nfrom time to time."},
+		{"^second", "- second item"},
+		{"^table", "A table"},
+		{"^missing", ""},
+	} {
+		section, err := Section(body, test.fragment)
+		if test.expected == "" {
+			if !errors.Is(err, ErrNoAnchor) {
+				t.Errorf("Expected %q to name no section, got %q, %v", test.fragment, section, err)
+			}
+			continue
+		}
+
+		if err != nil || section != test.expected {
+			t.Errorf("Expected %q to name %q, got %q, %v", test.fragment, test.expected, section, err)
+		}
+	}
+
+	if section, err := Section("## My Heading\nText", "my-heading"); err != nil || section != "## My Heading\nText" {
+		t.Errorf("Expected a markdown anchor to name the heading, got %q, %v", section, err)
+	}
+}
+
+func TestStripBlockIDs(t *testing.T) {
+	text := "A paragraph ^para\n\n^alone\n```\ncode ^kept\n```\nNot an id^word"
+
+	if stripped := StripBlockIDs(text); stripped != "A paragraph\n\n```\ncode ^kept\n```\nNot an id^word" {
+		t.Errorf("Expected the block ids to be left out, got %q", stripped)
+	}
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+const maxSymlinkDepth = 40
+
+// writeFileAtomic - replace the file at filePath through a synced temporary file and a rename, so a crash or a
+// full disk leaves either the old or the new content; symlinks are followed and the mode and owner are kept
+func writeFileAtomic(filePath string, content []byte, defaultMode fs.FileMode) error {
+	targetPath, err := resolveSymlinks(filePath)
+	if err != nil {
+		return err
+	}
+
+	mode := defaultMode
+	info, err := os.Stat(targetPath)
+	if err == nil {
+		mode = info.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(targetPath), "."+filepath.Base(targetPath)+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := tmpFile.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tmpFile.Close()
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmpFile.Write(content); err != nil {
+		return err
+	}
+
+	if err := tmpFile.Sync(); err != nil {
+		return err
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return err
+	}
+
+	if info != nil {
+		if err := copyOwner(info, tmpPath); err != nil {
+			slog.Warn("failed to keep note owner", "file", targetPath, "error", err)
+		}
+	}
+
+	if err := os.Rename(tmpPath, targetPath); err != nil {
+		return err
+	}
+
+	committed = true
+
+	if err := syncDirectory(filepath.Dir(targetPath)); err != nil {
+		slog.Warn("failed to sync note folder", "file", targetPath, "error", err)
+	}
+
+	return nil
+}
+
+// resolveSymlinks - follow filePath through any symlinks to the file that should actually be written,
+// which may not exist yet
+func resolveSymlinks(filePath string) (string, error) {
+	for range maxSymlinkDepth {
+		info, err := os.Lstat(filePath)
+		if os.IsNotExist(err) {
+			return filePath, nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if info.Mode()&fs.ModeSymlink == 0 {
+			return filePath, nil
+		}
+
+		link, err := os.Readlink(filePath)
+		if err != nil {
+			return "", err
+		}
+
+		if !filepath.IsAbs(link) {
+			link = filepath.Join(filepath.Dir(filePath), link)
+		}
+		filePath = link
+	}
+
+	return "", fmt.Errorf("too many levels of symbolic links: %s", filePath)
+}
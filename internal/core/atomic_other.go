@@ -0,0 +1,15 @@
+//go:build !unix
+
+package core
+
+import "io/fs"
+
+// copyOwner - ownership is not carried over on platforms without unix owners
+func copyOwner(_ fs.FileInfo, _ string) error {
+	return nil
+}
+
+// syncDirectory - directories cannot be synced on platforms without unix semantics
+func syncDirectory(_ string) error {
+	return nil
+}
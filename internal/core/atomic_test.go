@@ -0,0 +1,104 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	t.Run("keeps the file mode", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+
+		filePath := filepath.Join(tmpDir, "private.md")
+		err := os.WriteFile(filePath, []byte("old"), 0600)
+		if err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		err = writeFileAtomic(filePath, []byte("new"), 0644)
+		if err != nil {
+			t.Fatalf("writeFileAtomic failed: %v", err)
+		}
+
+		info, err := os.Stat(filePath)
+		if err != nil {
+			t.Fatalf("Failed to stat file: %v", err)
+		}
+
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("Expected mode 0600, got %o", info.Mode().Perm())
+		}
+
+		assertNoTempFiles(t, tmpDir)
+	})
+
+	t.Run("writes through symlinks to their targets", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+
+		targetPath := filepath.Join(tmpDir, "target.md")
+		linkPath := filepath.Join(tmpDir, "link.md")
+
+		err := os.WriteFile(targetPath, []byte("old"), 0644)
+		if err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		err = os.Symlink("target.md", linkPath)
+		if err != nil {
+			t.Skipf("Symlinks are not supported: %v", err)
+		}
+
+		err = writeFileAtomic(linkPath, []byte("new"), 0644)
+		if err != nil {
+			t.Fatalf("writeFileAtomic failed: %v", err)
+		}
+
+		info, err := os.Lstat(linkPath)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			t.Error("Expected the symlink to be kept")
+		}
+
+		content, _ := os.ReadFile(targetPath)
+		if string(content) != "new" {
+			t.Errorf("Expected target content 'new', got '%s'", string(content))
+		}
+	})
+
+	t.Run("leaves the old content and no temp files on failure", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+
+		filePath := filepath.Join(tmpDir, "busy.md")
+		err := os.MkdirAll(filepath.Join(filePath, "child"), 0755)
+		if err != nil {
+			t.Fatalf("Failed to create test folder: %v", err)
+		}
+
+		err = writeFileAtomic(filePath, []byte("new"), 0644)
+		if err == nil {
+			t.Fatal("Expected writeFileAtomic to fail when the target cannot be replaced")
+		}
+
+		if _, err := os.Stat(filepath.Join(filePath, "child")); err != nil {
+			t.Error("Expected the existing target to be left intact")
+		}
+
+		assertNoTempFiles(t, tmpDir)
+	})
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, ".*.tmp-*"))
+	if err != nil {
+		t.Fatalf("Failed to list temp files: %v", err)
+	}
+
+	if len(files) != 0 {
+		t.Errorf("Expected no temp files to be left behind, got %v", files)
+	}
+}
@@ -0,0 +1,34 @@
+//go:build unix
+
+package core
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// copyOwner - give filePath the owner and group of the file described by info
+func copyOwner(info fs.FileInfo, filePath string) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	if int(stat.Uid) == os.Getuid() && int(stat.Gid) == os.Getgid() {
+		return nil
+	}
+
+	return os.Chown(filePath, int(stat.Uid), int(stat.Gid))
+}
+
+// syncDirectory - flush a rename in dir to disk
+func syncDirectory(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return file.Sync()
+}
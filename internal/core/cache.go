@@ -0,0 +1,274 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// NoteCache - the notes read through one repository, kept until they change through it or Invalidate is called;
+// only changes made through the repository are seen, so changes made elsewhere must be reported with Invalidate
+type NoteCache struct {
+	mu sync.Mutex
+	// generation - bumped on every change so a load that raced with it does not fill the cache with stale notes
+	generation uint64
+	notes      map[string]Note
+	complete   bool
+}
+
+func NewNoteCache() *NoteCache {
+	return &NoteCache{notes: make(map[string]Note)}
+}
+
+// Invalidate - forget every cached note, so the next reads go to the repository
+func (c *NoteCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.notes = make(map[string]Note)
+	c.complete = false
+}
+
+// WithCache - serve reads from cache, filling it on the way through
+func WithCache(cache *NoteCache) Middleware {
+	return func(repository Repository) Repository {
+		return &cachingRepository{RepositoryWrapper: RepositoryWrapper{Repository: repository}, cache: cache}
+	}
+}
+
+// all - every note of the vault, when the cache holds them all
+func (c *NoteCache) all() ([]Note, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.complete {
+		return nil, c.generation, false
+	}
+
+	notes := make([]Note, 0, len(c.notes))
+	for _, note := range c.notes {
+		notes = append(notes, note)
+	}
+	SortNotes(notes)
+
+	return notes, c.generation, true
+}
+
+// get - the cached note at relativePath, when it was cached with its content
+func (c *NoteCache) get(relativePath string) (Note, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	note, ok := c.notes[relativePath]
+	return note, c.generation, ok && note.Loaded()
+}
+
+// fill - cache notes loaded at generation, unless the cache changed since
+func (c *NoteCache) fill(generation uint64, complete bool, notes ...Note) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	for _, note := range notes {
+		if cached, ok := c.notes[note.RelativePath()]; ok && cached.Loaded() && cached.Version() == note.Version() {
+			continue
+		}
+		c.notes[note.RelativePath()] = note
+	}
+	c.complete = c.complete || complete
+}
+
+// update - record a change made through the repository
+func (c *NoteCache) update(removed []Note, current ...Note) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, note := range removed {
+		delete(c.notes, note.RelativePath())
+	}
+	for _, note := range current {
+		c.notes[note.RelativePath()] = note
+	}
+}
+
+type cachingRepository struct {
+	RepositoryWrapper
+	cache *NoteCache
+}
+
+func (r *cachingRepository) GetAllNotes(ctx context.Context) ([]Note, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	notes, generation, ok := r.cache.all()
+	if ok {
+		return notes, nil
+	}
+
+	notes, err := r.Repository.GetAllNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.fill(generation, true, notes...)
+	return slices.Clone(notes), nil
+}
+
+func (r *cachingRepository) StreamNotes(ctx context.Context, batch func([]Note)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	notes, generation, ok := r.cache.all()
+	if ok {
+		if len(notes) > 0 {
+			batch(notes)
+		}
+		return nil
+	}
+
+	var loaded []Note
+	err := r.RepositoryWrapper.StreamNotes(ctx, func(notes []Note) {
+		loaded = append(loaded, notes...)
+		batch(slices.Clone(notes))
+	})
+	if err != nil {
+		return err
+	}
+
+	r.cache.fill(generation, true, loaded...)
+	return nil
+}
+
+func (r *cachingRepository) GetNoteByTitle(ctx context.Context, title string) (Note, error) {
+	if err := ctx.Err(); err != nil {
+		return Note{}, err
+	}
+
+	note, generation, ok := r.cache.get(title + ".md")
+	if ok {
+		return note, nil
+	}
+
+	note, err := r.Repository.GetNoteByTitle(ctx, title)
+	if err != nil {
+		return Note{}, err
+	}
+
+	r.cache.fill(generation, false, note)
+	return note, nil
+}
+
+func (r *cachingRepository) GetNotesByTag(ctx context.Context, tag string) ([]Note, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	notes, _, ok := r.cache.all()
+	if !ok {
+		return r.Repository.GetNotesByTag(ctx, tag)
+	}
+
+	var tagged []Note
+	for _, note := range notes {
+		if note.HasTag(tag) {
+			tagged = append(tagged, note)
+		}
+	}
+
+	return tagged, nil
+}
+
+func (r *cachingRepository) SaveNote(ctx context.Context, note Note) (Note, error) {
+	saved, err := r.Repository.SaveNote(ctx, note)
+	if err != nil {
+		r.forget(err, note)
+		return Note{}, err
+	}
+
+	r.cache.update(nil, saved)
+	return saved, nil
+}
+
+func (r *cachingRepository) CreateEmptyNote(ctx context.Context, filename string) (Note, error) {
+	note, err := r.Repository.CreateEmptyNote(ctx, filename)
+	if err != nil {
+		return Note{}, err
+	}
+
+	r.cache.update(nil, note)
+	return note, nil
+}
+
+func (r *cachingRepository) DeleteNote(ctx context.Context, note Note) error {
+	err := r.Repository.DeleteNote(ctx, note)
+	if err != nil {
+		r.forget(err, note)
+		return err
+	}
+
+	r.cache.update([]Note{note})
+	return nil
+}
+
+func (r *cachingRepository) RenameNote(ctx context.Context, note Note, newName string) (Note, error) {
+	renamed, err := r.Repository.RenameNote(ctx, note, newName)
+	if err != nil {
+		r.forget(err, note)
+		return Note{}, err
+	}
+
+	r.cache.update([]Note{note}, renamed)
+	return renamed, nil
+}
+
+func (r *cachingRepository) MoveNote(ctx context.Context, note Note, folder string) (Note, error) {
+	moved, err := r.Repository.MoveNote(ctx, note, folder)
+	if err != nil {
+		r.forget(err, note)
+		return Note{}, err
+	}
+
+	r.cache.update([]Note{note}, moved)
+	return moved, nil
+}
+
+func (r *cachingRepository) RestoreNote(ctx context.Context, id string) (Note, error) {
+	restored, err := r.RepositoryWrapper.RestoreNote(ctx, id)
+	if err != nil {
+		return Note{}, err
+	}
+
+	r.cache.update(nil, restored)
+	return restored, nil
+}
+
+func (r *cachingRepository) RestoreRevision(ctx context.Context, note Note, hash string) (Note, error) {
+	restored, err := r.RepositoryWrapper.RestoreRevision(ctx, note, hash)
+	if err != nil {
+		r.forget(err, note)
+		return Note{}, err
+	}
+
+	r.cache.update(nil, restored)
+	return restored, nil
+}
+
+// forget - drop the cached copy of a note a change failed on, since the failure may come from the copy being stale;
+// a conflict tells what the note holds now and a missing note is gone
+func (r *cachingRepository) forget(err error, note Note) {
+	var conflict *ConflictError
+	switch {
+	case errors.As(err, &conflict):
+		r.cache.update([]Note{note}, conflict.Theirs)
+	case errors.Is(err, ErrNotFound):
+		r.cache.update([]Note{note})
+	}
+}
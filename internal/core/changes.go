@@ -0,0 +1,234 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// ChangeKind - what happened to a note
+type ChangeKind int
+
+const (
+	NoteCreated ChangeKind = iota
+	NoteUpdated
+	NoteDeleted
+	NoteRenamed
+)
+
+// NoteChange - a note that was created, updated, renamed or deleted; Note only has its path for deletions and
+// Previous is the note before it was renamed or moved
+type NoteChange struct {
+	Kind     ChangeKind
+	Note     Note
+	Previous Note
+}
+
+// ChangeFeed - publishes the changes made to a vault, whether through the application or found by a watcher,
+// to every subscriber; publishing never waits for subscribers, whose pending changes are batched together instead
+type ChangeFeed struct {
+	mu            sync.Mutex
+	subscriptions map[*subscription]struct{}
+	closed        bool
+}
+
+func NewChangeFeed() *ChangeFeed {
+	return &ChangeFeed{subscriptions: make(map[*subscription]struct{})}
+}
+
+type subscription struct {
+	changes chan []NoteChange
+	wake    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+
+	mu      sync.Mutex
+	pending []NoteChange
+}
+
+// Subscribe - receive every change published from now on in batches, in the order they were published, until
+// unsubscribe is called or the feed is closed, which closes the channel
+func (f *ChangeFeed) Subscribe() (<-chan []NoteChange, func()) {
+	s := &subscription{
+		changes: make(chan []NoteChange),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		close(s.changes)
+		return s.changes, func() {}
+	}
+
+	f.subscriptions[s] = struct{}{}
+	go s.run()
+
+	return s.changes, func() {
+		f.mu.Lock()
+		delete(f.subscriptions, s)
+		f.mu.Unlock()
+
+		s.stop()
+	}
+}
+
+// Publish - send changes to every subscriber
+func (f *ChangeFeed) Publish(changes ...NoteChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for s := range f.subscriptions {
+		s.push(changes)
+	}
+}
+
+// Close - end every subscription
+func (f *ChangeFeed) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	for s := range f.subscriptions {
+		s.stop()
+	}
+	clear(f.subscriptions)
+}
+
+func (s *subscription) push(changes []NoteChange) {
+	s.mu.Lock()
+	s.pending = append(s.pending, changes...)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *subscription) stop() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// run - deliver the pending changes whenever there are some, until the subscription stops
+func (s *subscription) run() {
+	defer close(s.changes)
+
+	for {
+		select {
+		case <-s.wake:
+		case <-s.done:
+			return
+		}
+
+		s.mu.Lock()
+		batch := s.pending
+		s.pending = nil
+		s.mu.Unlock()
+
+		if len(batch) == 0 {
+			continue
+		}
+
+		select {
+		case s.changes <- batch:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// WithChangeFeed - publish every change made through the repository to feed once it succeeded
+func WithChangeFeed(feed *ChangeFeed) Middleware {
+	return func(repository Repository) Repository {
+		return &publishingRepository{RepositoryWrapper: RepositoryWrapper{Repository: repository}, feed: feed}
+	}
+}
+
+type publishingRepository struct {
+	RepositoryWrapper
+	feed *ChangeFeed
+}
+
+func (r *publishingRepository) SaveNote(ctx context.Context, note Note) (Note, error) {
+	saved, err := r.Repository.SaveNote(ctx, note)
+	if err != nil {
+		return Note{}, err
+	}
+
+	r.feed.Publish(NoteChange{Kind: NoteUpdated, Note: saved})
+	return saved, nil
+}
+
+func (r *publishingRepository) CreateEmptyNote(ctx context.Context, filename string) (Note, error) {
+	note, err := r.Repository.CreateEmptyNote(ctx, filename)
+	if err != nil {
+		return Note{}, err
+	}
+
+	r.feed.Publish(NoteChange{Kind: NoteCreated, Note: note})
+	return note, nil
+}
+
+func (r *publishingRepository) DeleteNote(ctx context.Context, note Note) error {
+	if err := r.Repository.DeleteNote(ctx, note); err != nil {
+		return err
+	}
+
+	r.feed.Publish(NoteChange{Kind: NoteDeleted, Note: note})
+	return nil
+}
+
+func (r *publishingRepository) RenameNote(ctx context.Context, note Note, newName string) (Note, error) {
+	renamed, err := r.Repository.RenameNote(ctx, note, newName)
+	if err != nil {
+		return Note{}, err
+	}
+
+	r.publishRelocation(note, renamed)
+	return renamed, nil
+}
+
+func (r *publishingRepository) MoveNote(ctx context.Context, note Note, folder string) (Note, error) {
+	moved, err := r.Repository.MoveNote(ctx, note, folder)
+	if err != nil {
+		return Note{}, err
+	}
+
+	r.publishRelocation(note, moved)
+	return moved, nil
+}
+
+func (r *publishingRepository) RestoreNote(ctx context.Context, id string) (Note, error) {
+	restored, err := r.RepositoryWrapper.RestoreNote(ctx, id)
+	if err != nil {
+		return Note{}, err
+	}
+
+	r.feed.Publish(NoteChange{Kind: NoteCreated, Note: restored})
+	return restored, nil
+}
+
+func (r *publishingRepository) RestoreRevision(ctx context.Context, note Note, hash string) (Note, error) {
+	restored, err := r.RepositoryWrapper.RestoreRevision(ctx, note, hash)
+	if err != nil {
+		return Note{}, err
+	}
+
+	r.feed.Publish(NoteChange{Kind: NoteUpdated, Note: restored})
+	return restored, nil
+}
+
+// publishRelocation - a note staying where it was is not a change
+func (r *publishingRepository) publishRelocation(previous, note Note) {
+	if previous.FilePath() == note.FilePath() {
+		return
+	}
+
+	r.feed.Publish(NoteChange{Kind: NoteRenamed, Note: note, Previous: previous})
+}
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// receive - the changes of the next batches until count changes arrived
+func receive(t *testing.T, changes <-chan []NoteChange, count int) []NoteChange {
+	t.Helper()
+
+	var received []NoteChange
+	for len(received) < count {
+		select {
+		case batch, ok := <-changes:
+			if !ok {
+				t.Fatalf("Expected %d changes, the feed closed after %d", count, len(received))
+			}
+			received = append(received, batch...)
+		case <-time.After(time.Second):
+			t.Fatalf("Expected %d changes, got %d", count, len(received))
+		}
+	}
+
+	return received
+}
+
+func TestChangeFeed(t *testing.T) {
+	t.Run("every subscriber receives the changes in order", func(t *testing.T) {
+		feed := NewChangeFeed()
+		defer feed.Close()
+
+		first, unsubscribeFirst := feed.Subscribe()
+		defer unsubscribeFirst()
+		second, unsubscribeSecond := feed.Subscribe()
+		defer unsubscribeSecond()
+
+		for _, name := range []string{"a.md", "b.md", "c.md"} {
+			feed.Publish(NoteChange{Kind: NoteCreated, Note: NewNote(name, "")})
+		}
+
+		for _, changes := range []<-chan []NoteChange{first, second} {
+			received := receive(t, changes, 3)
+			for i, name := range []string{"a.md", "b.md", "c.md"} {
+				if received[i].Note.FilePath() != name {
+					t.Errorf("Expected change %d to be about %s, got %s", i, name, received[i].Note.FilePath())
+				}
+			}
+		}
+	})
+
+	t.Run("publishing does not wait for subscribers", func(t *testing.T) {
+		feed := NewChangeFeed()
+		defer feed.Close()
+
+		changes, unsubscribe := feed.Subscribe()
+		defer unsubscribe()
+
+		done := make(chan struct{})
+		go func() {
+			for range 100 {
+				feed.Publish(NoteChange{Kind: NoteUpdated, Note: NewNote("note.md", "")})
+			}
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Expected publishing to a subscriber that is not reading to return")
+		}
+
+		if received := receive(t, changes, 100); len(received) != 100 {
+			t.Errorf("Expected 100 changes, got %d", len(received))
+		}
+	})
+
+	t.Run("unsubscribing closes the channel", func(t *testing.T) {
+		feed := NewChangeFeed()
+		defer feed.Close()
+
+		changes, unsubscribe := feed.Subscribe()
+		unsubscribe()
+		feed.Publish(NoteChange{Kind: NoteCreated, Note: NewNote("note.md", "")})
+
+		select {
+		case _, ok := <-changes:
+			if ok {
+				t.Error("Expected no changes after unsubscribing")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected the channel to be closed")
+		}
+	})
+
+	t.Run("closing the feed ends every subscription", func(t *testing.T) {
+		feed := NewChangeFeed()
+		changes, unsubscribe := feed.Subscribe()
+		defer unsubscribe()
+
+		feed.Close()
+
+		select {
+		case _, ok := <-changes:
+			if ok {
+				t.Error("Expected no changes after closing")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected the channel to be closed")
+		}
+
+		late, _ := feed.Subscribe()
+		if _, ok := <-late; ok {
+			t.Error("Expected subscribing to a closed feed to return a closed channel")
+		}
+	})
+}
+
+func TestWithChangeFeed(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("changes made through the repository are published", func(t *testing.T) {
+		feed := NewChangeFeed()
+		defer feed.Close()
+		changes, unsubscribe := feed.Subscribe()
+		defer unsubscribe()
+
+		repository := ChainRepository(NewMemoryRepository(), WithChangeFeed(feed))
+
+		note, _ := repository.CreateEmptyNote(ctx, "draft")
+		saved, _ := repository.SaveNote(ctx, note.WithContent("# draft\nchanged"))
+		renamed, _ := repository.RenameNote(ctx, saved, "final")
+		moved, _ := repository.MoveNote(ctx, renamed, "archive")
+		_ = repository.DeleteNote(ctx, moved)
+
+		received := receive(t, changes, 5)
+		expected := []ChangeKind{NoteCreated, NoteUpdated, NoteRenamed, NoteRenamed, NoteDeleted}
+		for i, kind := range expected {
+			if received[i].Kind != kind {
+				t.Errorf("Expected change %d to be %v, got %v", i, kind, received[i].Kind)
+			}
+		}
+
+		if received[2].Previous.Title() != "draft" || received[2].Note.Title() != "final" {
+			t.Errorf("Expected draft renamed to final, got %s to %s", received[2].Previous.Title(), received[2].Note.Title())
+		}
+	})
+
+	t.Run("failed changes and notes staying in place are not published", func(t *testing.T) {
+		feed := NewChangeFeed()
+		defer feed.Close()
+		changes, unsubscribe := feed.Subscribe()
+		defer unsubscribe()
+
+		note := NewNote("note.md", "# note")
+		memory := NewMemoryRepository(note)
+		repository := ChainRepository(memory, WithChangeFeed(feed))
+
+		if _, err := repository.CreateEmptyNote(ctx, "note"); err == nil {
+			t.Fatal("Expected creating an existing note to fail")
+		}
+		existing, _ := repository.GetNoteByTitle(ctx, "note")
+		if _, err := repository.RenameNote(ctx, existing, "note"); err != nil {
+			t.Fatalf("RenameNote failed: %v", err)
+		}
+		_ = repository.DeleteNote(ctx, existing)
+
+		received := receive(t, changes, 1)
+		if len(received) != 1 || received[0].Kind != NoteDeleted {
+			t.Errorf("Expected only the deletion to be published, got %v", received)
+		}
+	})
+}
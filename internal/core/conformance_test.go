@@ -0,0 +1,84 @@
+package core_test
+
+import (
+	"elephant/internal/core"
+	"elephant/internal/core/coretest"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepositoryConformance(t *testing.T) {
+	t.Run("NoteRepository", func(t *testing.T) {
+		coretest.TestRepository(t, func(t *testing.T) core.Repository {
+			repo := core.NewNoteRepository(t.TempDir())
+			return &repo
+		})
+	})
+
+	t.Run("SQLiteRepository", func(t *testing.T) {
+		coretest.TestRepository(t, func(t *testing.T) core.Repository {
+			repo, err := core.NewSQLiteRepository(filepath.Join(t.TempDir(), "notes.db"))
+			if err != nil {
+				t.Fatalf("NewSQLiteRepository failed: %v", err)
+			}
+			t.Cleanup(func() { _ = repo.Close() })
+
+			return repo
+		})
+	})
+
+	t.Run("MemoryRepository", func(t *testing.T) {
+		coretest.TestRepository(t, func(t *testing.T) core.Repository {
+			return core.NewMemoryRepository()
+		})
+	})
+
+	t.Run("LockingRepository", func(t *testing.T) {
+		coretest.TestRepository(t, func(t *testing.T) core.Repository {
+			basePath := t.TempDir()
+			repo := core.NewNoteRepository(basePath)
+			locks := core.NewNoteLocks(basePath)
+			t.Cleanup(func() { _ = locks.Close() })
+
+			return core.NewLockingRepository(&repo, locks)
+		})
+	})
+
+	t.Run("Middleware", func(t *testing.T) {
+		coretest.TestRepository(t, func(t *testing.T) core.Repository {
+			basePath := t.TempDir()
+			repo := core.NewNoteRepository(basePath)
+			locks := core.NewNoteLocks(basePath)
+			t.Cleanup(func() { _ = locks.Close() })
+
+			return core.ChainRepository(&repo,
+				core.WithLogging(slog.Default()),
+				core.WithCounters(core.NewOperationCounters()),
+				core.WithReadOnly(&core.ReadOnlyGuard{}),
+				core.WithNoteLocks(locks),
+				core.WithCache(core.NewNoteCache()),
+				core.WithFaults(core.NewFaultInjector()),
+			)
+		})
+	})
+
+	t.Run("GitRepository", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git is not installed")
+		}
+
+		coretest.TestRepository(t, func(t *testing.T) core.Repository {
+			basePath := t.TempDir()
+			repo := core.NewNoteRepository(basePath)
+			gitRepo, err := core.NewGitRepository(&repo, basePath)
+			if err != nil {
+				t.Fatalf("NewGitRepository failed: %v", err)
+			}
+			t.Cleanup(func() { _ = gitRepo.Close() })
+
+			return gitRepo
+		})
+	})
+}
@@ -0,0 +1,31 @@
+package core
+
+import "context"
+
+// awaitContext - run op in its own goroutine and stop waiting once ctx is done, so a hung filesystem
+// cannot block the caller past its deadline
+func awaitContext[T any](ctx context.Context, op func() (T, error)) (T, error) {
+	var zero T
+
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		value, err := op()
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
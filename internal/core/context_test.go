@@ -0,0 +1,37 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAwaitContext(t *testing.T) {
+	t.Run("returns the result when the operation finishes first", func(t *testing.T) {
+		value, err := awaitContext(context.Background(), func() (string, error) {
+			return "done", nil
+		})
+
+		if err != nil || value != "done" {
+			t.Errorf("Expected 'done' without error, got '%s' and %v", value, err)
+		}
+	})
+
+	t.Run("stops waiting for a hung operation once the deadline passes", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		release := make(chan struct{})
+		defer close(release)
+
+		_, err := awaitContext(ctx, func() (string, error) {
+			<-release
+			return "too late", nil
+		})
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// CopyNotes - copy every note from one repository into another, replacing notes that already exist there,
+// and return how many notes were copied
+func CopyNotes(ctx context.Context, from, to Repository) (int, error) {
+	notes, err := from.GetAllNotes(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	copied := 0
+	for _, note := range notes {
+		loaded, err := LoadNote(ctx, from, note)
+		if err != nil {
+			slog.Error("failed to copy note", "note", note.RelativePath(), "error", err)
+			return copied, fmt.Errorf("copy %s: %w", note.RelativePath(), err)
+		}
+		note = loaded
+
+		target, err := to.CreateEmptyNote(ctx, note.RelativePath())
+		if errors.Is(err, ErrAlreadyExists) {
+			target, err = to.GetNoteByTitle(ctx, strings.TrimSuffix(note.RelativePath(), ".md"))
+		}
+		if err != nil {
+			slog.Error("failed to copy note", "note", note.RelativePath(), "error", err)
+			return copied, fmt.Errorf("copy %s: %w", note.RelativePath(), err)
+		}
+
+		if _, err := to.SaveNote(ctx, target.WithContent(note.FileContent())); err != nil {
+			slog.Error("failed to copy note", "note", note.RelativePath(), "error", err)
+			return copied, fmt.Errorf("copy %s: %w", note.RelativePath(), err)
+		}
+		copied++
+	}
+
+	slog.Info("copied notes", "count", copied)
+	return copied, nil
+}
@@ -0,0 +1,375 @@
+// Package coretest - checks shared by every implementation of the core interfaces, so fakes and real backends
+// cannot drift apart
+package coretest
+
+import (
+	"context"
+	"elephant/internal/core"
+	"errors"
+	"testing"
+)
+
+// TestRepository - run the conformance suite against the repositories made by newRepository, which must return
+// an empty vault on every call
+func TestRepository(t *testing.T, newRepository func(t *testing.T) core.Repository) {
+	t.Run("CreateEmptyNote", func(t *testing.T) { testCreateEmptyNote(t, newRepository(t)) })
+	t.Run("GetNoteByTitle", func(t *testing.T) { testGetNoteByTitle(t, newRepository(t)) })
+	t.Run("GetAllNotes", func(t *testing.T) { testGetAllNotes(t, newRepository(t)) })
+	t.Run("GetNotesByTag", func(t *testing.T) { testGetNotesByTag(t, newRepository(t)) })
+	t.Run("StreamNotes", func(t *testing.T) { testStreamNotes(t, newRepository(t)) })
+	t.Run("SaveNote", func(t *testing.T) { testSaveNote(t, newRepository(t)) })
+	t.Run("DeleteNote", func(t *testing.T) { testDeleteNote(t, newRepository(t)) })
+	t.Run("RenameNote", func(t *testing.T) { testRenameNote(t, newRepository(t)) })
+	t.Run("MoveNote", func(t *testing.T) { testMoveNote(t, newRepository(t)) })
+	t.Run("Cancellation", func(t *testing.T) { testCancellation(t, newRepository(t)) })
+}
+
+// createNote - create the note named name holding content, failing the test when the repository refuses
+func createNote(t *testing.T, repo core.Repository, name, content string) core.Note {
+	t.Helper()
+	ctx := context.Background()
+
+	note, err := repo.CreateEmptyNote(ctx, name)
+	if err != nil {
+		t.Fatalf("CreateEmptyNote(%q) failed: %v", name, err)
+	}
+
+	note, err = repo.SaveNote(ctx, note.WithContent(content))
+	if err != nil {
+		t.Fatalf("SaveNote(%q) failed: %v", name, err)
+	}
+
+	return note
+}
+
+func relativePaths(notes []core.Note) []string {
+	paths := make([]string, len(notes))
+	for i, note := range notes {
+		paths[i] = note.RelativePath()
+	}
+
+	return paths
+}
+
+func expectPaths(t *testing.T, notes []core.Note, expected ...string) {
+	t.Helper()
+
+	paths := relativePaths(notes)
+	if len(paths) != len(expected) {
+		t.Fatalf("Expected notes %v, got %v", expected, paths)
+	}
+
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Fatalf("Expected notes %v, got %v", expected, paths)
+		}
+	}
+}
+
+func testCreateEmptyNote(t *testing.T, repo core.Repository) {
+	ctx := context.Background()
+
+	note, err := repo.CreateEmptyNote(ctx, "projects/alpha")
+	if err != nil {
+		t.Fatalf("CreateEmptyNote failed: %v", err)
+	}
+
+	if note.RelativePath() != "projects/alpha.md" || note.Folder() != "projects" || note.Title() != "alpha" {
+		t.Errorf("Expected projects/alpha.md titled alpha, got %q in %q titled %q", note.RelativePath(), note.Folder(), note.Title())
+	}
+	if note.FileContent() != "# alpha.md" {
+		t.Errorf("Expected the content to be '# alpha.md', got %q", note.FileContent())
+	}
+	if note.Version() == "" {
+		t.Error("Expected the created note to carry its stored version")
+	}
+
+	if _, err := repo.CreateEmptyNote(ctx, "beta.md"); err != nil {
+		t.Errorf("Expected a name with the .md extension to be accepted, got %v", err)
+	}
+
+	if _, err := repo.CreateEmptyNote(ctx, "projects/alpha"); !errors.Is(err, core.ErrAlreadyExists) {
+		t.Errorf("Expected ErrAlreadyExists, got %v", err)
+	}
+
+	for _, name := range []string{"", "../escape", ".hidden", "projects//alpha", "/absolute"} {
+		if _, err := repo.CreateEmptyNote(ctx, name); !errors.Is(err, core.ErrInvalidName) {
+			t.Errorf("Expected ErrInvalidName for %q, got %v", name, err)
+		}
+	}
+}
+
+func testGetNoteByTitle(t *testing.T, repo core.Repository) {
+	ctx := context.Background()
+	createNote(t, repo, "projects/alpha", "alpha content")
+
+	note, err := repo.GetNoteByTitle(ctx, "projects/alpha")
+	if err != nil {
+		t.Fatalf("GetNoteByTitle failed: %v", err)
+	}
+	if note.FileContent() != "alpha content" || note.Version() != core.HashContent("alpha content") {
+		t.Errorf("Expected the stored note, got %q at version %q", note.FileContent(), note.Version())
+	}
+
+	if _, err := repo.GetNoteByTitle(ctx, "missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if _, err := repo.GetNoteByTitle(ctx, "../escape"); !errors.Is(err, core.ErrInvalidName) {
+		t.Errorf("Expected ErrInvalidName, got %v", err)
+	}
+}
+
+func testGetAllNotes(t *testing.T, repo core.Repository) {
+	ctx := context.Background()
+
+	notes, err := repo.GetAllNotes(ctx)
+	if err != nil || len(notes) != 0 {
+		t.Fatalf("Expected an empty vault, got %v, %v", relativePaths(notes), err)
+	}
+
+	createNote(t, repo, "zebra", "z")
+	createNote(t, repo, "projects/beta", "b")
+	createNote(t, repo, "apple", "a")
+	createNote(t, repo, "projects/alpha", "a")
+
+	notes, err = repo.GetAllNotes(ctx)
+	if err != nil {
+		t.Fatalf("GetAllNotes failed: %v", err)
+	}
+
+	expectPaths(t, notes, "apple.md", "zebra.md", "projects/alpha.md", "projects/beta.md")
+}
+
+func testStreamNotes(t *testing.T, repo core.Repository) {
+	ctx := context.Background()
+	createNote(t, repo, "apple", "# Apple\nred")
+	createNote(t, repo, "projects/alpha", "# Alpha\nfirst")
+
+	var streamed []core.Note
+	err := core.StreamNotes(ctx, repo, func(batch []core.Note) { streamed = append(streamed, batch...) })
+	if err != nil {
+		t.Fatalf("StreamNotes failed: %v", err)
+	}
+	if len(streamed) != 2 {
+		t.Fatalf("Expected 2 streamed notes, got %v", relativePaths(streamed))
+	}
+
+	notes, err := repo.GetAllNotes(ctx)
+	if err != nil {
+		t.Fatalf("GetAllNotes failed: %v", err)
+	}
+
+	for _, note := range notes {
+		loaded, err := core.LoadNote(ctx, repo, note)
+		if err != nil || !loaded.Loaded() || loaded.Description() != note.Description() {
+			t.Errorf("Expected %s to load with its content, got %q, %v", note.RelativePath(), loaded.FileContent(), err)
+		}
+	}
+
+	// a listed note may come without its content, which renaming it must keep all the same
+	renamed, err := repo.RenameNote(ctx, notes[0], "pear")
+	if err != nil {
+		t.Fatalf("RenameNote failed: %v", err)
+	}
+	if renamed.FileContent() != "# Apple\nred" {
+		t.Errorf("Expected the renamed note to keep its content, got %q", renamed.FileContent())
+	}
+}
+
+func testGetNotesByTag(t *testing.T, repo core.Repository) {
+	ctx := context.Background()
+	createNote(t, repo, "front", "---\ntags: [Work]\n---\nfront matter tag")
+	createNote(t, repo, "inline", "an inline #work tag and a #work/meeting tag")
+	createNote(t, repo, "untagged", "no tags here")
+
+	notes, err := repo.GetNotesByTag(ctx, "work")
+	if err != nil {
+		t.Fatalf("GetNotesByTag failed: %v", err)
+	}
+	expectPaths(t, notes, "front.md", "inline.md")
+
+	notes, err = repo.GetNotesByTag(ctx, "#WORK/meeting")
+	if err != nil {
+		t.Fatalf("GetNotesByTag failed: %v", err)
+	}
+	expectPaths(t, notes, "inline.md")
+
+	notes, err = repo.GetNotesByTag(ctx, "#Work")
+	if err != nil {
+		t.Fatalf("GetNotesByTag failed: %v", err)
+	}
+	expectPaths(t, notes, "front.md", "inline.md")
+
+	notes, err = repo.GetNotesByTag(ctx, "missing")
+	if err != nil || len(notes) != 0 {
+		t.Errorf("Expected no notes for an unknown tag, got %v, %v", relativePaths(notes), err)
+	}
+}
+
+func testSaveNote(t *testing.T, repo core.Repository) {
+	ctx := context.Background()
+	note := createNote(t, repo, "note", "first")
+
+	saved, err := repo.SaveNote(ctx, note.WithContent("second"))
+	if err != nil {
+		t.Fatalf("SaveNote failed: %v", err)
+	}
+	if saved.FileContent() != "second" || saved.Version() != core.HashContent("second") || saved.BaseContent() != "second" {
+		t.Errorf("Expected the saved note to be stored at its new content, got %q at version %q", saved.FileContent(), saved.Version())
+	}
+
+	loaded, err := repo.GetNoteByTitle(ctx, "note")
+	if err != nil || loaded.FileContent() != "second" {
+		t.Errorf("Expected the saved content to be read back, got %q, %v", loaded.FileContent(), err)
+	}
+
+	_, err = repo.SaveNote(ctx, note.WithContent("stale"))
+	var conflict *core.ConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, core.ErrConflict) {
+		t.Fatalf("Expected a ConflictError when saving over a changed note, got %v", err)
+	}
+	if conflict.Mine.FileContent() != "stale" || conflict.Theirs.FileContent() != "second" {
+		t.Errorf("Expected mine to be 'stale' and theirs 'second', got %q and %q", conflict.Mine.FileContent(), conflict.Theirs.FileContent())
+	}
+
+	if _, err := repo.SaveNote(ctx, saved.WithContent("third")); err != nil {
+		t.Errorf("Expected saving on top of the saved note to succeed, got %v", err)
+	}
+
+	deleted := createNote(t, repo, "deleted", "content")
+	if err := repo.DeleteNote(ctx, deleted); err != nil {
+		t.Fatalf("DeleteNote failed: %v", err)
+	}
+	if _, err := repo.SaveNote(ctx, deleted.WithContent("changed")); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound when saving a note deleted since it was loaded, got %v", err)
+	}
+	if _, err := repo.GetNoteByTitle(ctx, "deleted"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("Expected the deleted note not to be recreated, got %v", err)
+	}
+
+	if _, err := repo.SaveNote(ctx, core.NewNote("../escape.md", "outside")); err == nil {
+		t.Error("Expected saving a note with a path leaving the vault to fail")
+	}
+	notes, _ := repo.GetAllNotes(ctx)
+	expectPaths(t, notes, "note.md")
+}
+
+func testDeleteNote(t *testing.T, repo core.Repository) {
+	ctx := context.Background()
+	note := createNote(t, repo, "note", "content")
+	createNote(t, repo, "other", "content")
+
+	if err := repo.DeleteNote(ctx, note); err != nil {
+		t.Fatalf("DeleteNote failed: %v", err)
+	}
+
+	if _, err := repo.GetNoteByTitle(ctx, "note"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("Expected the deleted note to be gone, got %v", err)
+	}
+
+	notes, _ := repo.GetAllNotes(ctx)
+	expectPaths(t, notes, "other.md")
+
+	if err := repo.DeleteNote(ctx, note); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound when deleting twice, got %v", err)
+	}
+}
+
+func testRenameNote(t *testing.T, repo core.Repository) {
+	ctx := context.Background()
+	note := createNote(t, repo, "inbox/draft", "draft content")
+	createNote(t, repo, "inbox/taken", "taken")
+
+	renamed, err := repo.RenameNote(ctx, note, "final")
+	if err != nil {
+		t.Fatalf("RenameNote failed: %v", err)
+	}
+	if renamed.RelativePath() != "inbox/final.md" || renamed.FileContent() != "draft content" {
+		t.Errorf("Expected inbox/final.md with the note content, got %q with %q", renamed.RelativePath(), renamed.FileContent())
+	}
+
+	if _, err := repo.GetNoteByTitle(ctx, "inbox/draft"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("Expected the old name to be gone, got %v", err)
+	}
+
+	same, err := repo.RenameNote(ctx, renamed, "final.md")
+	if err != nil || same.RelativePath() != "inbox/final.md" {
+		t.Errorf("Expected renaming to the same name to keep the note, got %q, %v", same.RelativePath(), err)
+	}
+
+	if _, err := repo.RenameNote(ctx, renamed, "taken"); !errors.Is(err, core.ErrAlreadyExists) {
+		t.Errorf("Expected ErrAlreadyExists, got %v", err)
+	}
+	if _, err := repo.RenameNote(ctx, renamed, "elsewhere/final"); !errors.Is(err, core.ErrInvalidName) {
+		t.Errorf("Expected ErrInvalidName for a name with a folder, got %v", err)
+	}
+	if _, err := repo.RenameNote(ctx, renamed, ".hidden"); !errors.Is(err, core.ErrInvalidName) {
+		t.Errorf("Expected ErrInvalidName for a hidden name, got %v", err)
+	}
+	if _, err := repo.RenameNote(ctx, note, "other"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a note that no longer exists, got %v", err)
+	}
+}
+
+func testMoveNote(t *testing.T, repo core.Repository) {
+	ctx := context.Background()
+	note := createNote(t, repo, "note", "content")
+	createNote(t, repo, "archive/2024/taken", "taken")
+
+	moved, err := repo.MoveNote(ctx, note, "archive/2024/")
+	if err != nil {
+		t.Fatalf("MoveNote failed: %v", err)
+	}
+	if moved.RelativePath() != "archive/2024/note.md" || moved.Folder() != "archive/2024" || moved.FileContent() != "content" {
+		t.Errorf("Expected archive/2024/note.md with the note content, got %q with %q", moved.RelativePath(), moved.FileContent())
+	}
+
+	back, err := repo.MoveNote(ctx, moved, "")
+	if err != nil || back.RelativePath() != "note.md" {
+		t.Fatalf("Expected the note to move back to the root, got %q, %v", back.RelativePath(), err)
+	}
+
+	taken := createNote(t, repo, "taken", "root")
+	if _, err := repo.MoveNote(ctx, taken, "archive/2024"); !errors.Is(err, core.ErrAlreadyExists) {
+		t.Errorf("Expected ErrAlreadyExists, got %v", err)
+	}
+	if _, err := repo.MoveNote(ctx, back, "../outside"); !errors.Is(err, core.ErrInvalidName) {
+		t.Errorf("Expected ErrInvalidName, got %v", err)
+	}
+	if _, err := repo.MoveNote(ctx, moved, "elsewhere"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a note that no longer exists, got %v", err)
+	}
+}
+
+func testCancellation(t *testing.T, repo core.Repository) {
+	note := createNote(t, repo, "note", "content")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := map[string]func() error{
+		"GetAllNotes": func() error { _, err := repo.GetAllNotes(ctx); return err },
+		"StreamNotes": func() error { return core.StreamNotes(ctx, repo, func([]core.Note) {}) },
+		"GetNoteByTitle": func() error {
+			_, err := repo.GetNoteByTitle(ctx, "note")
+			return err
+		},
+		"GetNotesByTag":   func() error { _, err := repo.GetNotesByTag(ctx, "tag"); return err },
+		"SaveNote":        func() error { _, err := repo.SaveNote(ctx, note.WithContent("changed")); return err },
+		"CreateEmptyNote": func() error { _, err := repo.CreateEmptyNote(ctx, "new"); return err },
+		"DeleteNote":      func() error { return repo.DeleteNote(ctx, note) },
+		"RenameNote":      func() error { _, err := repo.RenameNote(ctx, note, "renamed"); return err },
+		"MoveNote":        func() error { _, err := repo.MoveNote(ctx, note, "folder"); return err },
+	}
+
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected %s to fail with context.Canceled, got %v", name, err)
+		}
+	}
+
+	loaded, err := repo.GetNoteByTitle(context.Background(), "note")
+	if err != nil || loaded.FileContent() != "content" {
+		t.Errorf("Expected cancelled calls to leave the vault untouched, got %q, %v", loaded.FileContent(), err)
+	}
+}
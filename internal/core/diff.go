@@ -0,0 +1,153 @@
+package core
+
+import "strings"
+
+// DiffKind - whether a diff line is kept, removed from the old text or added by the new text
+type DiffKind int
+
+const (
+	DiffEqual DiffKind = iota
+	DiffDelete
+	DiffInsert
+)
+
+// DiffLine - a single line of a line based diff
+type DiffLine struct {
+	Kind DiffKind
+	Text string
+}
+
+// DiffLines - the line based diff turning oldText into newText
+func DiffLines(oldText, newText string) []DiffLine {
+	oldLines, newLines := splitLines(oldText), splitLines(newText)
+	oldMatches, _ := matchLines(oldLines, newLines)
+
+	var diff []DiffLine
+	j := 0
+	for i, line := range oldLines {
+		if oldMatches[i] < 0 {
+			diff = append(diff, DiffLine{Kind: DiffDelete, Text: line})
+			continue
+		}
+
+		for ; j < oldMatches[i]; j++ {
+			diff = append(diff, DiffLine{Kind: DiffInsert, Text: newLines[j]})
+		}
+
+		diff = append(diff, DiffLine{Kind: DiffEqual, Text: line})
+		j++
+	}
+
+	for ; j < len(newLines); j++ {
+		diff = append(diff, DiffLine{Kind: DiffInsert, Text: newLines[j]})
+	}
+
+	return diff
+}
+
+// Merge3 - merge the changes mine and theirs each made to base, marking the lines both changed differently
+// with conflict markers; conflicted reports whether any markers were written
+func Merge3(base, mine, theirs string) (merged string, conflicted bool) {
+	baseLines, mineLines, theirLines := splitLines(base), splitLines(mine), splitLines(theirs)
+	mineMatches, _ := matchLines(baseLines, mineLines)
+	theirMatches, _ := matchLines(baseLines, theirLines)
+
+	var out []string
+	b, m, t := 0, 0, 0
+
+	flush := func(bEnd, mEnd, tEnd int) {
+		baseChunk, mineChunk, theirChunk := baseLines[b:bEnd], mineLines[m:mEnd], theirLines[t:tEnd]
+
+		switch {
+		case equalLines(mineChunk, baseChunk):
+			out = append(out, theirChunk...)
+		case equalLines(theirChunk, baseChunk), equalLines(mineChunk, theirChunk):
+			out = append(out, mineChunk...)
+		default:
+			conflicted = true
+			out = append(out, "<<<<<<< mine")
+			out = append(out, mineChunk...)
+			out = append(out, "=======")
+			out = append(out, theirChunk...)
+			out = append(out, ">>>>>>> theirs")
+		}
+	}
+
+	for i := range baseLines {
+		if mineMatches[i] < 0 || theirMatches[i] < 0 {
+			continue
+		}
+
+		flush(i, mineMatches[i], theirMatches[i])
+		out = append(out, baseLines[i])
+		b, m, t = i+1, mineMatches[i]+1, theirMatches[i]+1
+	}
+
+	flush(len(baseLines), len(mineLines), len(theirLines))
+
+	return strings.Join(out, "\n"), conflicted
+}
+
+// matchLines - the longest common subsequence of a and b, as the index in b matched by every line of a
+// and the other way around, -1 for lines that are not part of it
+func matchLines(a, b []string) ([]int, []int) {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	aMatches, bMatches := make([]int, len(a)), make([]int, len(b))
+	for i := range aMatches {
+		aMatches[i] = -1
+	}
+	for j := range bMatches {
+		bMatches[j] = -1
+	}
+
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			aMatches[i], bMatches[j] = j, i
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return aMatches, bMatches
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(text, "\n")
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
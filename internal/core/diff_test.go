@@ -0,0 +1,56 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	diff := DiffLines("a\nb\nc", "a\nc\nd")
+
+	expected := []DiffLine{
+		{Kind: DiffEqual, Text: "a"},
+		{Kind: DiffDelete, Text: "b"},
+		{Kind: DiffEqual, Text: "c"},
+		{Kind: DiffInsert, Text: "d"},
+	}
+
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("Expected diff %v, got %v", expected, diff)
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	t.Run("changes to different lines are merged", func(t *testing.T) {
+		merged, conflicted := Merge3("a\nb\nc", "A\nb\nc", "a\nb\nC")
+
+		if conflicted {
+			t.Error("Expected no conflict")
+		}
+
+		if merged != "A\nb\nC" {
+			t.Errorf("Expected 'A\\nb\\nC', got '%s'", merged)
+		}
+	})
+
+	t.Run("identical changes are merged once", func(t *testing.T) {
+		merged, conflicted := Merge3("a\nb", "a\nB\nnew", "a\nB\nnew")
+
+		if conflicted || merged != "a\nB\nnew" {
+			t.Errorf("Expected 'a\\nB\\nnew' without conflict, got '%s' (%v)", merged, conflicted)
+		}
+	})
+
+	t.Run("changes to the same line are marked", func(t *testing.T) {
+		merged, conflicted := Merge3("a\nb\nc", "a\nmine\nc", "a\ntheirs\nc")
+
+		if !conflicted {
+			t.Error("Expected a conflict")
+		}
+
+		expected := "a\n<<<<<<< mine\nmine\n=======\ntheirs\n>>>>>>> theirs\nc"
+		if merged != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, merged)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	// ErrNotFound - the requested note does not exist
+	ErrNotFound = errors.New("note not found")
+	// ErrAlreadyExists - another note already exists at the target path
+	ErrAlreadyExists = errors.New("note already exists")
+	// ErrInvalidName - the note name or folder is empty, reserved or contains forbidden characters
+	ErrInvalidName = errors.New("invalid note name")
+	// ErrOutsideVault - the path resolves to a location outside of the vault
+	ErrOutsideVault = errors.New("path is outside the vault")
+	// ErrConflict - the note changed in storage since it was loaded, see ConflictError
+	ErrConflict = errors.New("note changed since it was loaded")
+	// ErrLocked - another elephant instance holds the lock on the vault or note, see LockedError
+	ErrLocked = errors.New("locked by another instance")
+	// ErrReadOnly - the vault was opened read-only and cannot be changed
+	ErrReadOnly = errors.New("vault is read-only")
+)
+
+// ConflictError - saving mine would overwrite the changes stored as theirs since mine was loaded
+type ConflictError struct {
+	Mine, Theirs Note
+}
+
+func (e *ConflictError) Error() string {
+	return ErrConflict.Error() + ": " + e.Mine.RelativePath()
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// LockedError - the lock at Path is held by Owner
+type LockedError struct {
+	Path  string
+	Owner LockInfo
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s: %s (pid %d on %s since %s)", ErrLocked, e.Path, e.Owner.PID, e.Owner.Host, e.Owner.AcquiredAt.Format(time.RFC3339))
+}
+
+func (e *LockedError) Unwrap() error {
+	return ErrLocked
+}
+
+const (
+	reservedCharacters = `<>:"\|?*`
+	maxSegmentLength   = 255
+)
+
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// ValidateName - check a slash separated note name or folder, such as projects/alpha/kickoff, is safe to use inside a vault
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: name is empty", ErrInvalidName)
+	}
+
+	if strings.HasPrefix(name, "/") || filepath.IsAbs(name) {
+		return fmt.Errorf("%w: %q is an absolute path", ErrInvalidName, name)
+	}
+
+	for _, segment := range strings.Split(name, "/") {
+		if err := validateSegment(segment); err != nil {
+			return fmt.Errorf("%w: %q %s", ErrInvalidName, name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func validateSegment(segment string) error {
+	switch {
+	case segment == "":
+		return errors.New("has an empty path segment")
+	case segment == "." || segment == "..":
+		return errors.New("contains a relative path segment")
+	case strings.HasPrefix(segment, "."):
+		return errors.New("contains a hidden path segment")
+	case strings.HasSuffix(segment, " ") || strings.HasSuffix(segment, "."):
+		return errors.New("has a segment ending in a space or dot")
+	case len(segment) > maxSegmentLength:
+		return errors.New("has a segment that is too long")
+	case reservedNames[strings.ToUpper(strings.TrimSuffix(segment, filepath.Ext(segment)))]:
+		return errors.New("uses a reserved name")
+	}
+
+	for _, r := range segment {
+		if unicode.IsControl(r) || strings.ContainsRune(reservedCharacters, r) {
+			return fmt.Errorf("contains the forbidden character %q", r)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	valid := []string{"note", "note.md", "projects/alpha/kickoff", "Today’s Menu", "2026-10-18 standup"}
+	for _, name := range valid {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("Expected '%s' to be valid, got %v", name, err)
+		}
+	}
+
+	invalid := []string{
+		"", "/etc/passwd", "../../etc/passwd", "projects/../../secret", "./note", "projects//note",
+		"projects/", ".trash/note", "note.", "note ", "what?", "a:b", "back\\slash", "tab\there", "CON", "nul.md",
+	}
+	for _, name := range invalid {
+		if err := ValidateName(name); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("Expected '%s' to be invalid, got %v", name, err)
+		}
+	}
+}
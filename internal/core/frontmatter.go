@@ -0,0 +1,131 @@
+package core
+
+import (
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+const (
+	yamlDelimiter = "---"
+	tomlDelimiter = "+++"
+)
+
+// Metadata - the typed key/value pairs parsed from the front matter of a note
+type Metadata map[string]any
+
+// String - the value of key when it is a scalar, formatted as a string
+func (m Metadata) String(key string) (string, bool) {
+	value, ok := m[key]
+	if !ok || value == nil {
+		return "", false
+	}
+
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case []any, map[string]any, Metadata:
+		return "", false
+	default:
+		return fmt.Sprint(value), true
+	}
+}
+
+// Strings - the value of key as a list, accepting either a sequence or a comma separated string
+func (m Metadata) Strings(key string) []string {
+	var values []string
+
+	switch value := m[key].(type) {
+	case []any:
+		for _, item := range value {
+			if item != nil {
+				values = append(values, strings.TrimSpace(fmt.Sprint(item)))
+			}
+		}
+	case []string:
+		values = append(values, value...)
+	case string:
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+
+	return values
+}
+
+// Keys - the metadata keys in alphabetical order
+func (m Metadata) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// splitFrontMatter - separate the raw front matter block, delimiters included, from the body of the content
+func splitFrontMatter(content string) (frontMatter, delimiter, body string) {
+	lines := strings.SplitAfter(content, "\n")
+
+	for _, delimiter := range []string{yamlDelimiter, tomlDelimiter} {
+		if trimLineEnding(lines[0]) != delimiter {
+			continue
+		}
+
+		offset := len(lines[0])
+		for _, line := range lines[1:] {
+			offset += len(line)
+			if trimLineEnding(line) == delimiter {
+				return content[:offset], delimiter, content[offset:]
+			}
+		}
+	}
+
+	return "", "", content
+}
+
+// parseFrontMatter - decode a raw front matter block as YAML or TOML depending on its delimiter
+func parseFrontMatter(frontMatter, delimiter string) (Metadata, error) {
+	inner := strings.TrimPrefix(trimLineEnding(frontMatter), delimiter)
+	inner = strings.TrimSuffix(inner, delimiter)
+
+	metadata := Metadata{}
+
+	switch delimiter {
+	case yamlDelimiter:
+		if err := yaml.Unmarshal([]byte(inner), &metadata); err != nil {
+			return nil, err
+		}
+	case tomlDelimiter:
+		if _, err := toml.Decode(inner, &metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	return metadata, nil
+}
+
+func extractFrontMatter(filePath, content string) (frontMatter string, metadata Metadata, body string) {
+	frontMatter, delimiter, body := splitFrontMatter(content)
+	if frontMatter == "" {
+		return "", nil, content
+	}
+
+	metadata, err := parseFrontMatter(frontMatter, delimiter)
+	if err != nil {
+		slog.Warn("failed to parse front matter", "file", filePath, "error", err)
+		return "", nil, content
+	}
+
+	return frontMatter, metadata, body
+}
+
+func trimLineEnding(line string) string {
+	return strings.TrimRight(line, "\r\n")
+}
@@ -0,0 +1,572 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gitIgnored - what a vault keeps out of git, written to the .gitignore of the vault when it is missing there; the
+// vault lock pattern also covers the guard taking a stale lock over
+var gitIgnored = []string{trashDirectory + "/", historyDirectory + "/", noteLocksDir + "/", vaultLockName + "*", vaultIndexName}
+
+// gitChange - the content of a path, relative to the vault, as of when the change was made
+type gitChange struct {
+	path    string
+	content string
+	removed bool
+}
+
+// gitCommit - a pending commit of changes, or a marker closing done once every earlier commit is finished
+type gitCommit struct {
+	changes []gitChange
+	message string
+	done    chan struct{}
+}
+
+// GitRepository - a repository that commits every change to git in the background and reads the history of
+// notes from the git log, using the system git binary on a local repository
+type GitRepository struct {
+	Repository
+	basePath string
+	prefix   string
+	identity []string
+
+	// mu - guards closed and pending, the commits queued for the background loop, which wake tells about; saves
+	// never wait on the loop however slow git is
+	mu      sync.Mutex
+	closed  bool
+	pending []gitCommit
+	wake    chan struct{}
+	stopped chan struct{}
+}
+
+// NewGitRepository - wrap repository, whose notes live in basePath, initializing a git repository there
+// unless the vault already is inside one
+func NewGitRepository(repository Repository, basePath string) (*GitRepository, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git is not installed: %w", err)
+	}
+
+	r := &GitRepository{
+		Repository: repository,
+		basePath:   basePath,
+		wake:       make(chan struct{}, 1),
+		stopped:    make(chan struct{}),
+	}
+
+	err := r.init(context.Background())
+	if err != nil {
+		slog.Error("failed to initialize git repository", "path", basePath, "error", err)
+		return nil, err
+	}
+
+	go r.commitLoop()
+
+	return r, nil
+}
+
+func (r *GitRepository) init(ctx context.Context) error {
+	err := os.MkdirAll(r.basePath, 0755)
+	if err != nil {
+		return err
+	}
+
+	message := "Ignore elephant files"
+	if _, err := r.git(ctx, "rev-parse", "--is-inside-work-tree"); err != nil {
+		if _, err := r.git(ctx, "init", "--quiet"); err != nil {
+			return err
+		}
+		message = "Initialize notes repository"
+	}
+
+	prefix, err := r.git(ctx, "rev-parse", "--show-prefix")
+	if err != nil {
+		return err
+	}
+	r.prefix = strings.TrimSpace(prefix)
+
+	content, changed, err := r.updateGitIgnore()
+	if err != nil {
+		return err
+	}
+	if changed {
+		r.enqueue(gitCommit{changes: []gitChange{{path: ".gitignore", content: content}}, message: message})
+	}
+
+	// commits need an identity, fall back to one of our own when the user has not configured any
+	if name, _ := r.git(ctx, "config", "user.name"); strings.TrimSpace(name) == "" {
+		r.identity = append(r.identity, "-c", "user.name=elephant")
+	}
+	if email, _ := r.git(ctx, "config", "user.email"); strings.TrimSpace(email) == "" {
+		r.identity = append(r.identity, "-c", "user.email=elephant@localhost")
+	}
+
+	return nil
+}
+
+// updateGitIgnore - add what the vault keeps out of git to the .gitignore of the vault, returning its content and
+// whether anything was missing
+func (r *GitRepository) updateGitIgnore() (string, bool, error) {
+	path := filepath.Join(r.basePath, ".gitignore")
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", false, err
+	}
+
+	content := string(existing)
+	present := map[string]bool{}
+	for _, line := range strings.Split(content, "\n") {
+		present[strings.TrimSpace(line)] = true
+	}
+
+	changed := false
+	for _, pattern := range gitIgnored {
+		if present[pattern] {
+			continue
+		}
+
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += pattern + "\n"
+		changed = true
+	}
+
+	if !changed {
+		return content, false, nil
+	}
+
+	return content, true, writeFileAtomic(path, []byte(content), 0644)
+}
+
+func (r *GitRepository) StreamNotes(ctx context.Context, batch func([]Note)) error {
+	return StreamNotes(ctx, r.Repository, batch)
+}
+
+func (r *GitRepository) SaveNote(ctx context.Context, note Note) (Note, error) {
+	saved, err := r.Repository.SaveNote(ctx, note)
+	if err != nil {
+		return Note{}, err
+	}
+
+	r.commitNotes("Update "+saved.RelativePath(), nil, saved)
+	return saved, nil
+}
+
+func (r *GitRepository) CreateEmptyNote(ctx context.Context, filename string) (Note, error) {
+	created, err := r.Repository.CreateEmptyNote(ctx, filename)
+	if err != nil {
+		return Note{}, err
+	}
+
+	r.commitNotes("Create "+created.RelativePath(), nil, created)
+	return created, nil
+}
+
+func (r *GitRepository) DeleteNote(ctx context.Context, note Note) error {
+	err := r.Repository.DeleteNote(ctx, note)
+	if err != nil {
+		return err
+	}
+
+	r.commitNotes("Delete "+note.RelativePath(), []Note{note})
+	return nil
+}
+
+func (r *GitRepository) RenameNote(ctx context.Context, note Note, newName string) (Note, error) {
+	renamed, err := r.Repository.RenameNote(ctx, note, newName)
+	if err != nil {
+		return Note{}, err
+	}
+
+	r.commitNotes("Rename "+note.RelativePath()+" to "+renamed.RelativePath(), []Note{note}, renamed)
+	return renamed, nil
+}
+
+func (r *GitRepository) MoveNote(ctx context.Context, note Note, folder string) (Note, error) {
+	moved, err := r.Repository.MoveNote(ctx, note, folder)
+	if err != nil {
+		return Note{}, err
+	}
+
+	r.commitNotes("Move "+note.RelativePath()+" to "+moved.RelativePath(), []Note{note}, moved)
+	return moved, nil
+}
+
+func (r *GitRepository) GetTrashedNotes(ctx context.Context) ([]TrashedNote, error) {
+	trash, err := r.trash()
+	if err != nil {
+		return nil, err
+	}
+
+	return trash.GetTrashedNotes(ctx)
+}
+
+func (r *GitRepository) RestoreNote(ctx context.Context, id string) (Note, error) {
+	trash, err := r.trash()
+	if err != nil {
+		return Note{}, err
+	}
+
+	restored, err := trash.RestoreNote(ctx, id)
+	if err != nil {
+		return Note{}, err
+	}
+
+	r.commitNotes("Restore "+restored.RelativePath()+" from the trash", nil, restored)
+	return restored, nil
+}
+
+func (r *GitRepository) PurgeNote(ctx context.Context, id string) error {
+	trash, err := r.trash()
+	if err != nil {
+		return err
+	}
+
+	return trash.PurgeNote(ctx, id)
+}
+
+func (r *GitRepository) EmptyTrash(ctx context.Context, olderThan time.Duration) (int, error) {
+	trash, err := r.trash()
+	if err != nil {
+		return 0, err
+	}
+
+	return trash.EmptyTrash(ctx, olderThan)
+}
+
+func (r *GitRepository) trash() (Trash, error) {
+	trash, ok := r.Repository.(Trash)
+	if !ok {
+		return nil, fmt.Errorf("repository %T has no trash", r.Repository)
+	}
+
+	return trash, nil
+}
+
+// GetRevisions - the commits that changed note, following it across renames, newest first
+func (r *GitRepository) GetRevisions(ctx context.Context, note Note) ([]Revision, error) {
+	return awaitContext(ctx, func() ([]Revision, error) {
+		return r.getRevisions(ctx, note)
+	})
+}
+
+func (r *GitRepository) getRevisions(ctx context.Context, note Note) ([]Revision, error) {
+	r.flush(ctx)
+
+	relativePath, err := r.relativePath(note)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := r.git(ctx, "-c", "core.quotePath=false", "log", "--follow", "--relative", "--name-only",
+		"--format=%x1e%H%x00%ct%x00%s", "--", relativePath)
+	if err != nil {
+		slog.Error("failed to read git log", "file", note.FilePath(), "error", err)
+		return nil, err
+	}
+
+	var revisions []Revision
+	for _, record := range strings.Split(out, "\x1e") {
+		header, names, _ := strings.Cut(record, "\n")
+		fields := strings.SplitN(header, "\x00", 3)
+		if len(fields) < 3 {
+			continue
+		}
+
+		seconds, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		// the path of the note at this commit, which differs from relativePath before a rename
+		pathAtCommit := relativePath
+		if name, _, _ := strings.Cut(strings.TrimSpace(names), "\n"); name != "" {
+			pathAtCommit = name
+		}
+
+		content, err := r.git(ctx, "show", fields[0]+":./"+pathAtCommit)
+		if err != nil {
+			// the commit deleted the note
+			continue
+		}
+
+		revisions = append(revisions, Revision{
+			Hash:    fields[0],
+			SavedAt: time.Unix(seconds, 0),
+			Message: fields[2],
+			Content: content,
+		})
+	}
+
+	return revisions, nil
+}
+
+// RestoreRevision - save the note as it was at the commit with the given hash, committing the revert
+func (r *GitRepository) RestoreRevision(ctx context.Context, note Note, hash string) (Note, error) {
+	if err := ctx.Err(); err != nil {
+		return Note{}, err
+	}
+
+	revisions, err := r.getRevisions(ctx, note)
+	if err != nil {
+		return Note{}, err
+	}
+
+	for _, revision := range revisions {
+		if revision.Hash != hash {
+			continue
+		}
+
+		saved, err := r.Repository.SaveNote(ctx, note.WithContent(revision.Content))
+		if err != nil {
+			return Note{}, err
+		}
+
+		r.commitNotes("Revert "+saved.RelativePath()+" to "+hash[:min(7, len(hash))], nil, saved)
+		return saved, nil
+	}
+
+	err = fmt.Errorf("%w: commit %s of %s", ErrNotFound, hash, note.RelativePath())
+	slog.Error("failed to revert note", "file", note.FilePath(), "error", err)
+	return Note{}, err
+}
+
+// Close - finish the pending commits and stop committing
+func (r *GitRepository) Close() error {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+	r.signal()
+
+	<-r.stopped
+
+	return nil
+}
+
+// commitNotes - commit in the background that the removed notes are gone and the current notes have their
+// content as of now
+func (r *GitRepository) commitNotes(message string, removed []Note, current ...Note) {
+	var changes []gitChange
+	for i, note := range append(removed, current...) {
+		relativePath, err := r.relativePath(note)
+		if err != nil {
+			slog.Warn("not committing note outside the vault", "file", note.FilePath(), "error", err)
+			continue
+		}
+
+		changes = append(changes, gitChange{path: relativePath, content: note.FileContent(), removed: i < len(removed)})
+	}
+
+	r.enqueue(gitCommit{changes: changes, message: message})
+}
+
+// enqueue - queue commit for the background loop without waiting for it
+func (r *GitRepository) enqueue(commit gitCommit) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		slog.Warn("not committing after the repository was closed", "message", commit.message)
+		if commit.done != nil {
+			close(commit.done)
+		}
+		return
+	}
+	r.pending = append(r.pending, commit)
+	r.mu.Unlock()
+
+	r.signal()
+}
+
+// signal - wake the background loop unless it is already due to look at the queue
+func (r *GitRepository) signal() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// nextCommit - the oldest queued commit, waiting for one; false once the repository is closed and the queue is empty
+func (r *GitRepository) nextCommit() (gitCommit, bool) {
+	for {
+		r.mu.Lock()
+		if len(r.pending) > 0 {
+			commit := r.pending[0]
+			r.pending[0] = gitCommit{}
+			r.pending = r.pending[1:]
+			r.mu.Unlock()
+			return commit, true
+		}
+		closed := r.closed
+		r.mu.Unlock()
+
+		if closed {
+			return gitCommit{}, false
+		}
+		<-r.wake
+	}
+}
+
+// flush - wait until every commit queued so far is done
+func (r *GitRepository) flush(ctx context.Context) {
+	done := make(chan struct{})
+	r.enqueue(gitCommit{done: done})
+
+	select {
+	case <-done:
+	case <-r.stopped:
+	case <-ctx.Done():
+	}
+}
+
+func (r *GitRepository) commitLoop() {
+	defer close(r.stopped)
+
+	for {
+		commit, ok := r.nextCommit()
+		if !ok {
+			return
+		}
+
+		if commit.done != nil {
+			close(commit.done)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		if err := r.commit(ctx, commit); err != nil {
+			slog.Error("failed to commit", "message", commit.message, "error", err)
+		}
+		cancel()
+	}
+}
+
+// commit - record the changes on top of HEAD through a temporary index, so that neither later changes to the
+// working tree nor anything the user staged end up in the commit
+func (r *GitRepository) commit(ctx context.Context, commit gitCommit) error {
+	index, err := os.CreateTemp("", "elephant-index-*")
+	if err != nil {
+		return err
+	}
+	index.Close()
+	os.Remove(index.Name())
+	defer os.Remove(index.Name())
+
+	indexEnv := "GIT_INDEX_FILE=" + index.Name()
+
+	head, headErr := r.git(ctx, "rev-parse", "--verify", "--quiet", "HEAD")
+	head = strings.TrimSpace(head)
+	if headErr == nil {
+		_, err = r.gitWith(ctx, []string{indexEnv}, "", "read-tree", head)
+	} else {
+		_, err = r.gitWith(ctx, []string{indexEnv}, "", "read-tree", "--empty")
+	}
+	if err != nil {
+		return err
+	}
+
+	var indexInfo strings.Builder
+	var paths []string
+	for _, change := range commit.changes {
+		paths = append(paths, change.path)
+
+		if change.removed {
+			fmt.Fprintf(&indexInfo, "0 %s\t%s\n", strings.Repeat("0", 40), r.prefix+change.path)
+			continue
+		}
+
+		blob, err := r.gitWith(ctx, nil, change.content, "hash-object", "-w", "--stdin")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&indexInfo, "100644 %s\t%s\n", strings.TrimSpace(blob), r.prefix+change.path)
+	}
+
+	if _, err := r.gitWith(ctx, []string{indexEnv}, indexInfo.String(), "update-index", "--index-info"); err != nil {
+		return err
+	}
+
+	tree, err := r.gitWith(ctx, []string{indexEnv}, "", "write-tree")
+	if err != nil {
+		return err
+	}
+	tree = strings.TrimSpace(tree)
+
+	if headErr == nil {
+		headTree, err := r.git(ctx, "rev-parse", head+"^{tree}")
+		if err == nil && strings.TrimSpace(headTree) == tree {
+			return nil
+		}
+	}
+
+	args := append(append([]string{}, r.identity...), "commit-tree", tree, "-m", commit.message)
+	updateRef := []string{"update-ref", "-m", "elephant: " + commit.message, "HEAD"}
+	if headErr == nil {
+		args = append(args, "-p", head)
+	}
+
+	created, err := r.git(ctx, args...)
+	if err != nil {
+		return err
+	}
+	updateRef = append(updateRef, strings.TrimSpace(created))
+	if headErr == nil {
+		updateRef = append(updateRef, head)
+	}
+
+	if _, err := r.git(ctx, updateRef...); err != nil {
+		return err
+	}
+
+	// bring the index of the user in line with the new commit for the committed paths
+	if _, err := r.git(ctx, append([]string{"reset", "--quiet", "--"}, paths...)...); err != nil {
+		slog.Warn("failed to update the git index", "error", err)
+	}
+
+	slog.Info("committed", "message", commit.message)
+	return nil
+}
+
+func (r *GitRepository) relativePath(note Note) (string, error) {
+	relativePath, err := filepath.Rel(r.basePath, note.FilePath())
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrOutsideVault, note.FilePath())
+	}
+
+	return filepath.ToSlash(relativePath), nil
+}
+
+// git - run git in the vault and return its output
+func (r *GitRepository) git(ctx context.Context, args ...string) (string, error) {
+	return r.gitWith(ctx, nil, "", args...)
+}
+
+// gitWith - run git in the vault with extra environment variables and input
+func (r *GitRepository) gitWith(ctx context.Context, env []string, input string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.basePath
+	cmd.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), env...)
+	cmd.Stdin = strings.NewReader(input)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return stdout.String(), fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
@@ -0,0 +1,248 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestGitRepository(t *testing.T, basePath string) (*GitRepository, *NoteRepository) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	noteRepository := NewNoteRepository(basePath)
+	repo, err := NewGitRepository(&noteRepository, basePath)
+	if err != nil {
+		t.Fatalf("NewGitRepository failed: %v", err)
+	}
+
+	return repo, &noteRepository
+}
+
+func gitLog(t *testing.T, repo *GitRepository) []string {
+	t.Helper()
+
+	repo.flush(context.Background())
+	out, err := repo.git(context.Background(), "log", "--format=%s")
+	if err != nil {
+		t.Fatalf("git log failed: %v", err)
+	}
+
+	return strings.Split(strings.TrimSpace(out), "\n")
+}
+
+func TestGitRepository(t *testing.T) {
+	t.Run("commits create, save, rename, move and delete", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		repo, _ := newTestGitRepository(t, tempDir)
+		defer repo.Close()
+
+		note, err := repo.CreateEmptyNote(ctx, "note")
+		if err != nil {
+			t.Fatalf("CreateEmptyNote failed: %v", err)
+		}
+		note, _ = repo.SaveNote(ctx, note.WithContent("changed"))
+		note, _ = repo.RenameNote(ctx, note, "renamed")
+		note, _ = repo.MoveNote(ctx, note, "archive")
+		if err := repo.DeleteNote(ctx, note); err != nil {
+			t.Fatalf("DeleteNote failed: %v", err)
+		}
+
+		expected := []string{
+			"Delete archive/renamed.md",
+			"Move renamed.md to archive/renamed.md",
+			"Rename note.md to renamed.md",
+			"Update note.md",
+			"Create note.md",
+			"Initialize notes repository",
+		}
+		if log := gitLog(t, repo); strings.Join(log, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("Expected commits %v, got %v", expected, log)
+		}
+
+		status, _ := repo.git(ctx, "status", "--porcelain")
+		if status != "" {
+			t.Errorf("Expected a clean working tree with internal folders ignored, got %q", status)
+		}
+	})
+
+	t.Run("saving without changes does not commit", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		repo, _ := newTestGitRepository(t, tempDir)
+		defer repo.Close()
+
+		note, _ := repo.CreateEmptyNote(ctx, "note")
+		_, _ = repo.SaveNote(ctx, note)
+
+		if log := gitLog(t, repo); len(log) != 2 {
+			t.Errorf("Expected no commit for an unchanged save, got %v", log)
+		}
+	})
+
+	t.Run("revisions follow the note across renames and can be reverted", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		repo, _ := newTestGitRepository(t, tempDir)
+		defer repo.Close()
+
+		note, _ := repo.CreateEmptyNote(ctx, "note")
+		note, _ = repo.SaveNote(ctx, note.WithContent("first"))
+		note, _ = repo.SaveNote(ctx, note.WithContent("second"))
+		note, _ = repo.RenameNote(ctx, note, "renamed")
+
+		revisions, err := repo.GetRevisions(ctx, note)
+		if err != nil {
+			t.Fatalf("GetRevisions failed: %v", err)
+		}
+
+		var contents []string
+		for _, revision := range revisions {
+			contents = append(contents, revision.Content)
+		}
+		expected := []string{"second", "second", "first", "# note.md"}
+		if strings.Join(contents, "|") != strings.Join(expected, "|") {
+			t.Fatalf("Expected revisions %v, got %v", expected, contents)
+		}
+		if revisions[2].Message != "Update note.md" {
+			t.Errorf("Expected the commit message, got %q", revisions[2].Message)
+		}
+
+		reverted, err := repo.RestoreRevision(ctx, note, revisions[2].Hash)
+		if err != nil {
+			t.Fatalf("RestoreRevision failed: %v", err)
+		}
+		if reverted.FileContent() != "first" {
+			t.Errorf("Expected the note to be reverted, got %q", reverted.FileContent())
+		}
+
+		log := gitLog(t, repo)
+		if !strings.HasPrefix(log[0], "Revert renamed.md to "+revisions[2].Hash[:7]) {
+			t.Errorf("Expected a revert commit, got %q", log[0])
+		}
+
+		if _, err := repo.RestoreRevision(ctx, note, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for an unknown commit, got %v", err)
+		}
+	})
+
+	t.Run("works in a vault inside a larger repository", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		if out, err := exec.Command("git", "init", "--quiet", tempDir).CombinedOutput(); err != nil {
+			t.Fatalf("git init failed: %v: %s", err, out)
+		}
+		if err := os.WriteFile(filepath.Join(tempDir, "unrelated.txt"), []byte("untouched"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+
+		vault := filepath.Join(tempDir, "notes")
+		repo, _ := newTestGitRepository(t, vault)
+		defer repo.Close()
+
+		note, _ := repo.CreateEmptyNote(ctx, "note")
+		note, _ = repo.SaveNote(ctx, note.WithContent("changed"))
+
+		revisions, err := repo.GetRevisions(ctx, note)
+		if err != nil || len(revisions) != 2 || revisions[0].Content != "changed" {
+			t.Errorf("Expected 2 revisions of the nested note, got %+v, %v", revisions, err)
+		}
+
+		ignored, _ := os.ReadFile(filepath.Join(vault, ".gitignore"))
+		if !strings.Contains(string(ignored), historyDirectory+"/") || !strings.Contains(string(ignored), vaultIndexName) {
+			t.Errorf("Expected the internal files to be ignored in an existing repository, got %q", ignored)
+		}
+		if status, _ := repo.git(ctx, "status", "--porcelain", "--", "."); status != "" {
+			t.Errorf("Expected a clean vault with internal folders ignored, got %q", status)
+		}
+
+		status, _ := repo.git(ctx, "status", "--porcelain", "--", "../unrelated.txt")
+		if !strings.HasPrefix(status, "??") {
+			t.Errorf("Expected unrelated files not to be committed, got %q", status)
+		}
+	})
+
+	t.Run("commits still happen for notes restored from the trash", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		repo, _ := newTestGitRepository(t, tempDir)
+		defer repo.Close()
+
+		note, _ := repo.CreateEmptyNote(ctx, "note")
+		_ = repo.DeleteNote(ctx, note)
+
+		trashed, _ := repo.GetTrashedNotes(ctx)
+		if _, err := repo.RestoreNote(ctx, trashed[0].ID); err != nil {
+			t.Fatalf("RestoreNote failed: %v", err)
+		}
+
+		if log := gitLog(t, repo); log[0] != "Restore note.md from the trash" {
+			t.Errorf("Expected a restore commit, got %q", log[0])
+		}
+	})
+
+	t.Run("the ignore entries missing from an existing .gitignore are added once", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		if err := os.WriteFile(filepath.Join(tempDir, ".gitignore"), []byte("*.bak\n"+trashDirectory+"/"), 0644); err != nil {
+			t.Fatalf("Failed to write .gitignore: %v", err)
+		}
+
+		repo, _ := newTestGitRepository(t, tempDir)
+		repo.Close()
+		repo, _ = newTestGitRepository(t, tempDir)
+		defer repo.Close()
+
+		ignored, _ := os.ReadFile(filepath.Join(tempDir, ".gitignore"))
+		expected := "*.bak\n" + strings.Join(gitIgnored, "\n") + "\n"
+		if string(ignored) != expected {
+			t.Errorf("Expected %q, got %q", expected, ignored)
+		}
+
+		if log := gitLog(t, repo); len(log) != 1 {
+			t.Errorf("Expected a single commit of the .gitignore, got %v", log)
+		}
+	})
+
+	t.Run("queueing commits does not wait for git", func(t *testing.T) {
+		// no loop takes the commits, as when git hangs
+		repo := &GitRepository{wake: make(chan struct{}, 1), stopped: make(chan struct{})}
+
+		queued := make(chan struct{})
+		go func() {
+			for range 1000 {
+				repo.enqueue(gitCommit{message: "Update note.md"})
+			}
+			close(queued)
+		}()
+
+		select {
+		case <-queued:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected commits to be queued without blocking")
+		}
+		if len(repo.pending) != 1000 {
+			t.Errorf("Expected 1000 queued commits, got %d", len(repo.pending))
+		}
+	})
+}
@@ -0,0 +1,396 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	historyDirectory = ".history"
+	historyLockName  = ".lock"
+)
+
+// historyLockRetry - how long to wait before trying again to take the history lock held by another instance
+var historyLockRetry = 10 * time.Millisecond
+
+// historyLockTimeout - how long to wait for the history lock before the revision is not recorded
+var historyLockTimeout = 5 * time.Second
+
+// History - earlier versions of notes, recorded whenever a note is saved
+type History interface {
+	// GetRevisions - the recorded versions of note, newest first
+	GetRevisions(ctx context.Context, note Note) ([]Revision, error)
+	// RestoreRevision - save the content of the revision with the given hash as the current version of note
+	RestoreRevision(ctx context.Context, note Note, hash string) (Note, error)
+}
+
+// Revision - the content of a note as it was saved at one point in time, with a message when the history
+// keeps one
+type Revision struct {
+	Hash    string
+	SavedAt time.Time
+	Message string
+	Content string
+}
+
+// HistoryRetention - how many revisions are kept per note and for how long, where zero means no limit;
+// the newest revision of a note is always kept
+type HistoryRetention struct {
+	MaxRevisions int
+	MaxAge       time.Duration
+}
+
+// DefaultHistoryRetention - the retention policy of a repository unless it is changed
+var DefaultHistoryRetention = HistoryRetention{MaxRevisions: 50, MaxAge: 90 * 24 * time.Hour}
+
+type historyEntry struct {
+	Hash    string    `json:"hash"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// SetHistoryRetention - change how many revisions are kept from the next save on
+func (r *NoteRepository) SetHistoryRetention(retention HistoryRetention) {
+	r.retention = retention
+}
+
+// DisableHistory - stop recording revisions and moving them along with their notes, for when another history such
+// as git keeps them; the revisions recorded so far can still be read
+func (r *NoteRepository) DisableHistory() {
+	r.historyDisabled = true
+}
+
+func (r *NoteRepository) historyPath(names ...string) string {
+	return filepath.Join(append([]string{r.basePath, historyDirectory}, names...)...)
+}
+
+// historyLogPath - the revision log of the note at filePath, named after its path relative to the vault
+func (r *NoteRepository) historyLogPath(filePath string) (string, error) {
+	relativePath, err := filepath.Rel(r.basePath, filePath)
+	if err != nil {
+		return "", err
+	}
+
+	return r.historyPath("notes", HashContent(filepath.ToSlash(relativePath))+".json"), nil
+}
+
+// objectPath - where content with the given hash is stored, shared by every revision with that content
+func (r *NoteRepository) objectPath(hash string) string {
+	return r.historyPath("objects", hash[:2], hash)
+}
+
+func (r *NoteRepository) GetRevisions(ctx context.Context, note Note) ([]Revision, error) {
+	return awaitContext(ctx, func() ([]Revision, error) {
+		return r.getRevisions(ctx, note)
+	})
+}
+
+func (r *NoteRepository) getRevisions(ctx context.Context, note Note) ([]Revision, error) {
+	if err := r.checkInVault(note.FilePath()); err != nil {
+		return nil, err
+	}
+
+	entries, err := r.readHistoryLog(note.FilePath())
+	if err != nil {
+		slog.Error("failed to read note history", "file", note.FilePath(), "error", err)
+		return nil, err
+	}
+
+	revisions := make([]Revision, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		content, err := os.ReadFile(r.objectPath(entries[i].Hash))
+		if err != nil {
+			slog.Warn("failed to read revision", "file", note.FilePath(), "hash", entries[i].Hash, "error", err)
+			continue
+		}
+
+		revisions = append(revisions, Revision{Hash: entries[i].Hash, SavedAt: entries[i].SavedAt, Content: string(content)})
+	}
+
+	return revisions, nil
+}
+
+func (r *NoteRepository) RestoreRevision(ctx context.Context, note Note, hash string) (Note, error) {
+	if err := ctx.Err(); err != nil {
+		return Note{}, err
+	}
+
+	revisions, err := r.getRevisions(ctx, note)
+	if err != nil {
+		return Note{}, err
+	}
+
+	for _, revision := range revisions {
+		if revision.Hash == hash {
+			return r.SaveNote(ctx, note.WithContent(revision.Content))
+		}
+	}
+
+	err = fmt.Errorf("%w: revision %s of %s", ErrNotFound, hash, note.RelativePath())
+	slog.Error("failed to restore revision", "file", note.FilePath(), "error", err)
+	return Note{}, err
+}
+
+// recordRevision - add content to the history of the note at filePath unless it is already its newest revision,
+// then apply the retention policy
+func (r *NoteRepository) recordRevision(filePath, content string, savedAt time.Time) error {
+	if r.historyDisabled {
+		return nil
+	}
+
+	unlock, err := r.lockHistory()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	logPath, err := r.historyLogPath(filePath)
+	if err != nil {
+		return err
+	}
+
+	entries, err := r.readHistoryLog(filePath)
+	if err != nil {
+		return err
+	}
+
+	hash := HashContent(content)
+	if len(entries) > 0 && entries[len(entries)-1].Hash == hash {
+		return nil
+	}
+
+	err = r.writeObject(hash, content)
+	if err != nil {
+		return err
+	}
+
+	entries = append(entries, historyEntry{Hash: hash, SavedAt: savedAt})
+	kept := r.retention.apply(entries, time.Now())
+
+	err = writeHistoryLog(logPath, kept)
+	if err != nil {
+		return err
+	}
+
+	if len(kept) < len(entries) {
+		return r.collectObjects()
+	}
+
+	return nil
+}
+
+// snapshotNote - record the stored content of the note at filePath, so that content changed outside the
+// application is not lost when it gets overwritten; it is recorded as saved now rather than at the modification
+// time of the file, which other tools may set to anything, so that the revisions of a note stay in order
+func (r *NoteRepository) snapshotNote(filePath string) error {
+	if r.historyDisabled {
+		return nil
+	}
+
+	content, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return r.recordRevision(filePath, string(content), time.Now())
+}
+
+// moveHistory - keep the history of a renamed or moved note
+func (r *NoteRepository) moveHistory(oldPath, newPath string) error {
+	if r.historyDisabled {
+		return nil
+	}
+
+	unlock, err := r.lockHistory()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	oldLog, err := r.historyLogPath(oldPath)
+	if err != nil {
+		return err
+	}
+	newLog, err := r.historyLogPath(newPath)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(oldLog, newLog)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// lockHistory - take the history store for this repository and, through a lock file, for the other instances
+// sharing the vault, so that none of them collects an object another one is recording a revision for; returns the
+// function releasing it
+func (r *NoteRepository) lockHistory() (func(), error) {
+	r.historyMu.Lock()
+
+	lockPath := r.historyPath(historyLockName)
+	if err := acquireHistoryLock(lockPath); err != nil {
+		r.historyMu.Unlock()
+		return nil, err
+	}
+
+	return func() {
+		if err := os.Remove(lockPath); err != nil {
+			slog.Warn("failed to remove history lock", "file", lockPath, "error", err)
+		}
+		r.historyMu.Unlock()
+	}, nil
+}
+
+// acquireHistoryLock - create the lock file at lockPath once no other instance holds it, removing a lock left
+// behind by an instance that died once it is older than lockStaleAfter
+func acquireHistoryLock(lockPath string) error {
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(historyLockTimeout)
+	for {
+		err := writeNewFile(lockPath, nil)
+		if !errors.Is(err, ErrAlreadyExists) {
+			return err
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			slog.Warn("removing stale history lock", "file", lockPath)
+			if err := os.Remove(lockPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: %s", ErrLocked, lockPath)
+		}
+		time.Sleep(historyLockRetry)
+	}
+}
+
+func (r *NoteRepository) readHistoryLog(filePath string) ([]historyEntry, error) {
+	logPath, err := r.historyLogPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(logPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []historyEntry
+	err = json.Unmarshal(data, &entries)
+	return entries, err
+}
+
+func writeHistoryLog(logPath string, entries []historyEntry) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(logPath), 0755)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(logPath, data, 0644)
+}
+
+func (r *NoteRepository) writeObject(hash, content string) error {
+	objectPath := r.objectPath(hash)
+	if _, err := os.Stat(objectPath); err == nil {
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(objectPath), 0755)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(objectPath, []byte(content), 0644)
+}
+
+// collectObjects - remove stored content no revision of any note refers to anymore
+func (r *NoteRepository) collectObjects() error {
+	logs, err := filepath.Glob(r.historyPath("notes", "*.json"))
+	if err != nil {
+		return err
+	}
+
+	referenced := map[string]bool{}
+	for _, logPath := range logs {
+		data, err := os.ReadFile(logPath)
+		if err != nil {
+			return err
+		}
+
+		var entries []historyEntry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			// keep everything rather than losing content a damaged log may still refer to
+			return fmt.Errorf("failed to read history log %s: %w", logPath, err)
+		}
+
+		for _, entry := range entries {
+			referenced[entry.Hash] = true
+		}
+	}
+
+	objects, err := filepath.Glob(r.historyPath("objects", "*", "*"))
+	if err != nil {
+		return err
+	}
+
+	for _, objectPath := range objects {
+		name := filepath.Base(objectPath)
+		if referenced[name] || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		if err := os.Remove(objectPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// apply - the entries kept under the policy, oldest first, always including the newest one
+func (p HistoryRetention) apply(entries []historyEntry, now time.Time) []historyEntry {
+	start := 0
+	if p.MaxRevisions > 0 && len(entries) > p.MaxRevisions {
+		start = len(entries) - p.MaxRevisions
+	}
+
+	if p.MaxAge > 0 {
+		cutoff := now.Add(-p.MaxAge)
+		for start < len(entries)-1 && entries[start].SavedAt.Before(cutoff) {
+			start++
+		}
+	}
+
+	return entries[start:]
+}
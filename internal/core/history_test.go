@@ -0,0 +1,345 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	t.Run("every save records a revision, newest first", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		repo := NewNoteRepository(tempDir)
+		note, _ := repo.CreateEmptyNote(ctx, "note")
+
+		for _, content := range []string{"first", "second"} {
+			saved, err := repo.SaveNote(ctx, note.WithContent(content))
+			if err != nil {
+				t.Fatalf("SaveNote failed: %v", err)
+			}
+			note = saved
+		}
+
+		revisions, err := repo.GetRevisions(ctx, note)
+		if err != nil {
+			t.Fatalf("GetRevisions failed: %v", err)
+		}
+
+		var contents []string
+		for _, revision := range revisions {
+			contents = append(contents, revision.Content)
+		}
+
+		expected := []string{"second", "first", "# note.md"}
+		if len(contents) != len(expected) {
+			t.Fatalf("Expected revisions %v, got %v", expected, contents)
+		}
+		for i := range expected {
+			if contents[i] != expected[i] {
+				t.Errorf("Expected revision %d to be %q, got %q", i, expected[i], contents[i])
+			}
+		}
+	})
+
+	t.Run("content changed outside the application is recorded before it is overwritten", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		filePath := filepath.Join(tempDir, "note.md")
+		if err := os.WriteFile(filePath, []byte("written elsewhere"), 0644); err != nil {
+			t.Fatalf("Failed to write note: %v", err)
+		}
+
+		repo := NewNoteRepository(tempDir)
+		note, _ := repo.GetNoteByTitle(ctx, "note")
+		if _, err := repo.SaveNote(ctx, note.WithContent("mine")); err != nil {
+			t.Fatalf("SaveNote failed: %v", err)
+		}
+
+		revisions, _ := repo.GetRevisions(ctx, note)
+		if len(revisions) != 2 || revisions[1].Content != "written elsewhere" {
+			t.Errorf("Expected the outside content to be kept, got %+v", revisions)
+		}
+	})
+
+	t.Run("identical content is stored once", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		repo := NewNoteRepository(tempDir)
+		note, _ := repo.CreateEmptyNote(ctx, "note")
+		for _, content := range []string{"same", "same", "other", "same"} {
+			note, _ = repo.SaveNote(ctx, note.WithContent(content))
+		}
+
+		revisions, _ := repo.GetRevisions(ctx, note)
+		if len(revisions) != 4 {
+			t.Errorf("Expected repeated saves of the same content to be skipped, got %d revisions", len(revisions))
+		}
+
+		objects, _ := filepath.Glob(filepath.Join(tempDir, historyDirectory, "objects", "*", "*"))
+		if len(objects) != 3 {
+			t.Errorf("Expected 3 stored contents, got %d", len(objects))
+		}
+	})
+
+	t.Run("retention drops old revisions and their content", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		repo := NewNoteRepository(tempDir)
+		repo.SetHistoryRetention(HistoryRetention{MaxRevisions: 2})
+		note, _ := repo.CreateEmptyNote(ctx, "note")
+		for _, content := range []string{"one", "two", "three"} {
+			note, _ = repo.SaveNote(ctx, note.WithContent(content))
+		}
+
+		revisions, _ := repo.GetRevisions(ctx, note)
+		if len(revisions) != 2 || revisions[0].Content != "three" || revisions[1].Content != "two" {
+			t.Errorf("Expected only the 2 newest revisions, got %+v", revisions)
+		}
+
+		objects, _ := filepath.Glob(filepath.Join(tempDir, historyDirectory, "objects", "*", "*"))
+		if len(objects) != 2 {
+			t.Errorf("Expected the content of dropped revisions to be removed, got %d objects", len(objects))
+		}
+	})
+
+	t.Run("concurrent saves of different notes keep the content of their newest revisions", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		repo := NewNoteRepository(tempDir)
+		repo.SetHistoryRetention(HistoryRetention{MaxRevisions: 1})
+
+		var notes []Note
+		for _, name := range []string{"a", "b", "c", "d"} {
+			note, _ := repo.CreateEmptyNote(ctx, name)
+			notes = append(notes, note)
+		}
+
+		var wg sync.WaitGroup
+		for _, note := range notes {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range 10 {
+					note, _ = repo.SaveNote(ctx, note.WithContent(fmt.Sprintf("%s %d", note.Title(), i)))
+				}
+			}()
+		}
+		wg.Wait()
+
+		for _, note := range notes {
+			revisions, err := repo.GetRevisions(ctx, note)
+			if err != nil || len(revisions) != 1 || revisions[0].Content != note.Title()+" 9" {
+				t.Errorf("Expected the newest revision of %s to be readable, got %+v, %v", note.Title(), revisions, err)
+			}
+		}
+	})
+
+	t.Run("instances sharing a vault keep the content of each other's newest revisions", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		var wg sync.WaitGroup
+		for _, name := range []string{"a", "b", "c", "d"} {
+			// every instance has its own repository, as separate processes would
+			repo := NewNoteRepository(tempDir)
+			repo.SetHistoryRetention(HistoryRetention{MaxRevisions: 1})
+			note, _ := repo.CreateEmptyNote(ctx, name)
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range 10 {
+					note, _ = repo.SaveNote(ctx, note.WithContent(fmt.Sprintf("%s %d", name, i)))
+				}
+			}()
+		}
+		wg.Wait()
+
+		repo := NewNoteRepository(tempDir)
+		for _, name := range []string{"a", "b", "c", "d"} {
+			revisions, err := repo.GetRevisions(ctx, NewNote(filepath.Join(tempDir, name+".md"), ""))
+			if err != nil || len(revisions) != 1 || revisions[0].Content != name+" 9" {
+				t.Errorf("Expected the newest revision of %s to be readable, got %+v, %v", name, revisions, err)
+			}
+		}
+	})
+
+	t.Run("history waits for another instance holding its lock", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		repo := NewNoteRepository(tempDir)
+		note, _ := repo.CreateEmptyNote(ctx, "note")
+
+		lockPath := filepath.Join(tempDir, historyDirectory, historyLockName)
+		if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+			t.Fatalf("Failed to create history: %v", err)
+		}
+		if err := os.WriteFile(lockPath, nil, 0644); err != nil {
+			t.Fatalf("Failed to write lock: %v", err)
+		}
+
+		saved := make(chan error, 1)
+		go func() {
+			_, err := repo.SaveNote(ctx, note.WithContent("content"))
+			saved <- err
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		if revisions, _ := repo.GetRevisions(ctx, note); len(revisions) != 0 {
+			t.Errorf("Expected no revision recorded while the lock is held, got %+v", revisions)
+		}
+
+		if err := os.Remove(lockPath); err != nil {
+			t.Fatalf("Failed to remove lock: %v", err)
+		}
+		if err := <-saved; err != nil {
+			t.Fatalf("SaveNote failed: %v", err)
+		}
+		if revisions, _ := repo.GetRevisions(ctx, note); len(revisions) != 2 || revisions[0].Content != "content" {
+			t.Errorf("Expected the revision recorded once the lock is released, got %+v", revisions)
+		}
+	})
+
+	t.Run("a history lock left behind by an instance that died is removed", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		repo := NewNoteRepository(tempDir)
+		note, _ := repo.CreateEmptyNote(ctx, "note")
+
+		lockPath := filepath.Join(tempDir, historyDirectory, historyLockName)
+		if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+			t.Fatalf("Failed to create history: %v", err)
+		}
+		stale := time.Now().Add(-2 * lockStaleAfter)
+		if err := os.WriteFile(lockPath, nil, 0644); err != nil {
+			t.Fatalf("Failed to write lock: %v", err)
+		}
+		if err := os.Chtimes(lockPath, stale, stale); err != nil {
+			t.Fatalf("Failed to age lock: %v", err)
+		}
+
+		note, _ = repo.SaveNote(ctx, note.WithContent("content"))
+		if revisions, _ := repo.GetRevisions(ctx, note); len(revisions) != 2 || revisions[0].Content != "content" {
+			t.Errorf("Expected the revision recorded, got %+v", revisions)
+		}
+		if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+			t.Errorf("Expected the lock to be released, got %v", err)
+		}
+	})
+
+	t.Run("a disabled history records nothing", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		repo := NewNoteRepository(tempDir)
+		repo.DisableHistory()
+		note, _ := repo.CreateEmptyNote(ctx, "note")
+		note, _ = repo.SaveNote(ctx, note.WithContent("content"))
+		if _, err := repo.RenameNote(ctx, note, "renamed"); err != nil {
+			t.Fatalf("RenameNote failed: %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(tempDir, historyDirectory)); !os.IsNotExist(err) {
+			t.Errorf("Expected no history to be written, got %v", err)
+		}
+	})
+
+	t.Run("retention by age keeps the newest revision", func(t *testing.T) {
+		now := time.Now()
+		entries := []historyEntry{
+			{Hash: "a", SavedAt: now.Add(-3 * time.Hour)},
+			{Hash: "b", SavedAt: now.Add(-2 * time.Hour)},
+		}
+
+		kept := HistoryRetention{MaxAge: time.Hour}.apply(entries, now)
+		if len(kept) != 1 || kept[0].Hash != "b" {
+			t.Errorf("Expected only the newest revision, got %+v", kept)
+		}
+	})
+
+	t.Run("restoring a revision saves it as the current version", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		repo := NewNoteRepository(tempDir)
+		note, _ := repo.CreateEmptyNote(ctx, "note")
+		note, _ = repo.SaveNote(ctx, note.WithContent("old"))
+		note, _ = repo.SaveNote(ctx, note.WithContent("new"))
+
+		restored, err := repo.RestoreRevision(ctx, note, HashContent("old"))
+		if err != nil {
+			t.Fatalf("RestoreRevision failed: %v", err)
+		}
+
+		content, _ := os.ReadFile(note.FilePath())
+		if string(content) != "old" || restored.FileContent() != "old" {
+			t.Errorf("Expected the old content to be restored, got %q", content)
+		}
+
+		if _, err := repo.RestoreRevision(ctx, restored, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for an unknown revision, got %v", err)
+		}
+	})
+
+	t.Run("history follows renamed and moved notes", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		repo := NewNoteRepository(tempDir)
+		note, _ := repo.CreateEmptyNote(ctx, "note")
+		note, _ = repo.SaveNote(ctx, note.WithContent("kept"))
+
+		renamed, err := repo.RenameNote(ctx, note, "renamed")
+		if err != nil {
+			t.Fatalf("RenameNote failed: %v", err)
+		}
+		moved, err := repo.MoveNote(ctx, renamed, "archive")
+		if err != nil {
+			t.Fatalf("MoveNote failed: %v", err)
+		}
+
+		revisions, _ := repo.GetRevisions(ctx, moved)
+		if len(revisions) != 2 || revisions[0].Content != "kept" {
+			t.Errorf("Expected the history to follow the note, got %+v", revisions)
+		}
+	})
+
+	t.Run("history is hidden from the notes", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		repo := NewNoteRepository(tempDir)
+		note, _ := repo.CreateEmptyNote(ctx, "note")
+		_, _ = repo.SaveNote(ctx, note.WithContent("changed"))
+
+		notes, _ := repo.GetAllNotes(ctx)
+		if len(notes) != 1 {
+			t.Errorf("Expected only the note itself, got %d notes", len(notes))
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	vaultIndexName = ".elephant.index"
+	// indexVersion - bumped whenever the entries change shape, so indexes written by older versions are rebuilt
+	indexVersion = 5
+	// racyWindow - how recently a file may have changed for its modification time to be trusted, since a file
+	// changed again within the resolution of the file system clock keeps its modification time
+	racyWindow = 2 * time.Second
+)
+
+// noteIndex - the summaries of the notes of a vault kept on disk next to them, keyed by path and checked against the
+// modification time and size of each file, so only the files changed since the last load are read again
+type noteIndex struct {
+	mu      sync.Mutex
+	path    string
+	loaded  bool
+	entries map[string]indexEntry
+}
+
+type indexEntry struct {
+	ModTime     time.Time `json:"mod_time"`
+	Size        int64     `json:"size"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Tags        []string  `json:"tags"`
+	Links       []Link    `json:"links,omitempty"`
+	Tasks       []Task    `json:"tasks,omitempty"`
+	Version     string    `json:"version"`
+}
+
+type indexFile struct {
+	Version int                   `json:"version"`
+	Notes   map[string]indexEntry `json:"notes"`
+}
+
+func newNoteIndex(path string) *noteIndex {
+	return &noteIndex{path: path}
+}
+
+// lookup - the indexed note at relativePath, when the file still has the modification time and size it was indexed at
+func (i *noteIndex) lookup(basePath, relativePath string, info fs.FileInfo) (Note, indexEntry, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.load()
+
+	entry, ok := i.entries[relativePath]
+	if !ok || entry.Size != info.Size() || !entry.ModTime.Equal(info.ModTime()) {
+		return Note{}, indexEntry{}, false
+	}
+
+	return entry.note(basePath, relativePath), entry, true
+}
+
+// replace - index exactly entries, writing the index when they differ from the indexed ones
+func (i *noteIndex) replace(entries map[string]indexEntry, changed bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if !changed && len(entries) == len(i.entries) {
+		return
+	}
+
+	i.entries = entries
+	if err := i.save(); err != nil {
+		slog.Warn("failed to write note index", "file", i.path, "error", err)
+	}
+}
+
+// load - read the index once, starting over when it is missing, corrupt or from another version
+func (i *noteIndex) load() {
+	if i.loaded {
+		return
+	}
+	i.loaded = true
+	i.entries = map[string]indexEntry{}
+
+	data, err := os.ReadFile(i.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		slog.Warn("failed to read note index, rebuilding it", "file", i.path, "error", err)
+		return
+	}
+
+	var index indexFile
+	if err := json.Unmarshal(data, &index); err != nil {
+		slog.Warn("note index is corrupt, rebuilding it", "file", i.path, "error", err)
+		return
+	}
+
+	if index.Version != indexVersion {
+		slog.Info("note index is from another version, rebuilding it", "file", i.path, "version", index.Version)
+		return
+	}
+
+	if index.Notes != nil {
+		i.entries = index.Notes
+	}
+}
+
+func (i *noteIndex) save() error {
+	data, err := json.Marshal(indexFile{Version: indexVersion, Notes: i.entries})
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(i.path, data, 0644)
+}
+
+// newIndexEntry - the entry of a note just read from a file, unless the file changed too recently to be trusted
+func newIndexEntry(note Note, info fs.FileInfo, now time.Time) (indexEntry, bool) {
+	if now.Sub(info.ModTime()) < racyWindow {
+		return indexEntry{}, false
+	}
+
+	return indexEntry{
+		ModTime:     info.ModTime(),
+		Size:        info.Size(),
+		Title:       note.title,
+		Description: note.description,
+		Tags:        note.tags,
+		Links:       note.links,
+		Tasks:       note.tasks,
+		Version:     note.version,
+	}, true
+}
+
+// note - the summary of the indexed note
+func (e indexEntry) note(basePath, relativePath string) Note {
+	return Note{
+		title:        e.Title,
+		description:  e.Description,
+		filePath:     filepath.Join(basePath, filepath.FromSlash(relativePath)),
+		relativePath: relativePath,
+		tags:         e.Tags,
+		links:        e.Links,
+		tasks:        e.Tasks,
+		version:      e.Version,
+		lazy:         true,
+	}
+}
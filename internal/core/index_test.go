@@ -0,0 +1,208 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSettledNote - write a note whose modification time is old enough for the index to trust it
+func writeSettledNote(t *testing.T, filePath, content string, modTime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write note: %v", err)
+	}
+	if err := os.Chtimes(filePath, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+}
+
+func readIndexFile(t *testing.T, basePath string) indexFile {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(basePath, vaultIndexName))
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+
+	var index indexFile
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatalf("Failed to parse index: %v", err)
+	}
+
+	return index
+}
+
+func descriptions(notes []Note) map[string]string {
+	result := map[string]string{}
+	for _, note := range notes {
+		result[note.RelativePath()] = note.Description()
+	}
+
+	return result
+}
+
+func TestNoteIndex(t *testing.T) {
+	ctx := context.Background()
+	modTime := time.Now().Add(-time.Hour)
+
+	t.Run("unchanged notes are served from the index", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		notePath := filepath.Join(tempDir, "note.md")
+		writeSettledNote(t, notePath, "# Note\n#work", modTime)
+
+		repository := NewNoteRepository(tempDir)
+		if _, err := repository.GetAllNotes(ctx); err != nil {
+			t.Fatalf("GetAllNotes failed: %v", err)
+		}
+
+		// same size and modification time, so a fresh repository must not read the file again
+		writeSettledNote(t, notePath, "# Edit\n#home", modTime)
+
+		reopened := NewNoteRepository(tempDir)
+		notes, err := reopened.GetAllNotes(ctx)
+		if err != nil {
+			t.Fatalf("GetAllNotes failed: %v", err)
+		}
+
+		if len(notes) != 1 || notes[0].Description() != "Note" || !notes[0].HasTag("work") {
+			t.Errorf("Expected the indexed note, got %v", notes)
+		}
+		if notes[0].FilePath() != notePath || notes[0].Version() != HashContent("# Note\n#work") {
+			t.Errorf("Expected the indexed note at %s with its version, got %s", notePath, notes[0].FilePath())
+		}
+	})
+
+	t.Run("indexed notes keep their links and tasks", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		writeSettledNote(t, filepath.Join(tempDir, "note.md"), "# Note\nSee [[Other|the other]].\n- [ ] Reply", modTime)
+
+		repository := NewNoteRepository(tempDir)
+		if _, err := repository.GetAllNotes(ctx); err != nil {
+			t.Fatalf("GetAllNotes failed: %v", err)
+		}
+
+		reopened := NewNoteRepository(tempDir)
+		notes, err := reopened.GetAllNotes(ctx)
+		if err != nil || len(notes) != 1 {
+			t.Fatalf("Expected one note, got %v, %v", notes, err)
+		}
+
+		links := notes[0].Links()
+		if len(links) != 1 || links[0].Target != "Other" || links[0].Text != "the other" || links[0].Start != 11 {
+			t.Errorf("Expected the indexed link, got %+v", links)
+		}
+
+		tasks := notes[0].Tasks()
+		if len(tasks) != 1 || tasks[0].Text != "Reply" || tasks[0].Line != 3 || notes[0].Loaded() {
+			t.Errorf("Expected the indexed task without reading the note, got %+v", tasks)
+		}
+	})
+
+	t.Run("changed, new and removed notes are picked up", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		writeSettledNote(t, filepath.Join(tempDir, "changed.md"), "# Before", modTime)
+		writeSettledNote(t, filepath.Join(tempDir, "removed.md"), "# Removed", modTime)
+
+		repository := NewNoteRepository(tempDir)
+		if _, err := repository.GetAllNotes(ctx); err != nil {
+			t.Fatalf("GetAllNotes failed: %v", err)
+		}
+
+		writeSettledNote(t, filepath.Join(tempDir, "changed.md"), "# After, longer", modTime)
+		writeSettledNote(t, filepath.Join(tempDir, "added.md"), "# Added", modTime)
+		_ = os.Remove(filepath.Join(tempDir, "removed.md"))
+
+		reopened := NewNoteRepository(tempDir)
+		notes, err := reopened.GetAllNotes(ctx)
+		if err != nil {
+			t.Fatalf("GetAllNotes failed: %v", err)
+		}
+
+		got := descriptions(notes)
+		if len(got) != 2 || got["changed.md"] != "After, longer" || got["added.md"] != "Added" {
+			t.Errorf("Expected the changed and the added note, got %v", got)
+		}
+
+		index := readIndexFile(t, tempDir)
+		if _, ok := index.Notes["removed.md"]; ok || len(index.Notes) != 2 {
+			t.Errorf("Expected the removed note to be dropped from the index, got %v", index.Notes)
+		}
+	})
+
+	t.Run("notes changed too recently are not indexed", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		if err := os.WriteFile(filepath.Join(tempDir, "fresh.md"), []byte("# Fresh"), 0644); err != nil {
+			t.Fatalf("Failed to write note: %v", err)
+		}
+
+		repository := NewNoteRepository(tempDir)
+		if _, err := repository.GetAllNotes(ctx); err != nil {
+			t.Fatalf("GetAllNotes failed: %v", err)
+		}
+
+		if index := readIndexFile(t, tempDir); len(index.Notes) != 0 {
+			t.Errorf("Expected no trusted entries, got %v", index.Notes)
+		}
+	})
+
+	t.Run("a corrupt index is rebuilt", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		writeSettledNote(t, filepath.Join(tempDir, "note.md"), "# Note", modTime)
+		if err := os.WriteFile(filepath.Join(tempDir, vaultIndexName), []byte("{not json"), 0644); err != nil {
+			t.Fatalf("Failed to write index: %v", err)
+		}
+
+		reopened := NewNoteRepository(tempDir)
+		notes, err := reopened.GetAllNotes(ctx)
+		if err != nil || len(notes) != 1 || notes[0].Description() != "Note" {
+			t.Fatalf("Expected the note despite the corrupt index, got %v, %v", notes, err)
+		}
+
+		index := readIndexFile(t, tempDir)
+		if index.Version != indexVersion || len(index.Notes) != 1 {
+			t.Errorf("Expected a rebuilt index, got %+v", index)
+		}
+	})
+
+	t.Run("an index from another version is rebuilt", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		notePath := filepath.Join(tempDir, "note.md")
+		writeSettledNote(t, notePath, "# Note", modTime)
+
+		info, _ := os.Stat(notePath)
+		stale, _ := json.Marshal(indexFile{
+			Version: indexVersion - 1,
+			Notes:   map[string]indexEntry{"note.md": {ModTime: info.ModTime(), Size: info.Size(), Description: "Stale"}},
+		})
+		if err := os.WriteFile(filepath.Join(tempDir, vaultIndexName), stale, 0644); err != nil {
+			t.Fatalf("Failed to write index: %v", err)
+		}
+
+		reopened := NewNoteRepository(tempDir)
+		notes, err := reopened.GetAllNotes(ctx)
+		if err != nil || len(notes) != 1 || notes[0].Description() != "Note" {
+			t.Fatalf("Expected the note read from disk, got %v, %v", notes, err)
+		}
+
+		if index := readIndexFile(t, tempDir); index.Version != indexVersion {
+			t.Errorf("Expected the index to be rewritten at version %d, got %d", indexVersion, index.Version)
+		}
+	})
+}
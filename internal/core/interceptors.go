@@ -0,0 +1,219 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInjectedFault - the error of a call failed on purpose by a FaultInjector
+var ErrInjectedFault = errors.New("injected fault")
+
+// WithLogging - log every call with how long it took, failed calls as warnings and the others at debug level
+func WithLogging(logger *slog.Logger) Middleware {
+	return Intercept(func(ctx context.Context, call Call, next func(ctx context.Context) error) error {
+		start := time.Now()
+		err := next(ctx)
+
+		attrs := []any{"op", call.Op, "duration", time.Since(start)}
+		if call.Target != "" {
+			attrs = append(attrs, "target", call.Target)
+		}
+
+		if err != nil {
+			logger.WarnContext(ctx, "repository call failed", append(attrs, "error", err)...)
+			return err
+		}
+
+		logger.DebugContext(ctx, "repository call", attrs...)
+		return nil
+	})
+}
+
+// ReadOnlyGuard - a switch refusing every change to the repositories it guards with ErrReadOnly while it is on
+type ReadOnlyGuard struct {
+	readOnly atomic.Bool
+}
+
+func (g *ReadOnlyGuard) SetReadOnly(readOnly bool) {
+	g.readOnly.Store(readOnly)
+}
+
+func (g *ReadOnlyGuard) ReadOnly() bool {
+	return g.readOnly.Load()
+}
+
+// WithReadOnly - refuse changes, including locking notes for editing, while guard is read-only
+func WithReadOnly(guard *ReadOnlyGuard) Middleware {
+	return Intercept(func(ctx context.Context, call Call, next func(ctx context.Context) error) error {
+		if call.Write && guard.ReadOnly() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return ErrReadOnly
+		}
+
+		return next(ctx)
+	})
+}
+
+// OperationCount - how often a repository call was made, how often it failed and how long it took altogether
+type OperationCount struct {
+	Calls    int
+	Failures int
+	Duration time.Duration
+}
+
+// OperationCounters - counts of the calls made to the repositories they are attached to, safe for concurrent use
+type OperationCounters struct {
+	mu     sync.Mutex
+	counts map[string]OperationCount
+}
+
+func NewOperationCounters() *OperationCounters {
+	return &OperationCounters{counts: make(map[string]OperationCount)}
+}
+
+// Count - the count of the calls to op, such as SaveNote
+func (c *OperationCounters) Count(op string) OperationCount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.counts[op]
+}
+
+// LogValue - the counts grouped by op, so the counters can be logged as they are
+func (c *OperationCounters) LogValue() slog.Value {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ops := make([]string, 0, len(c.counts))
+	for op := range c.counts {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	attrs := make([]slog.Attr, len(ops))
+	for i, op := range ops {
+		count := c.counts[op]
+		attrs[i] = slog.Group(op, "calls", count.Calls, "failures", count.Failures, "duration", count.Duration)
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+func (c *OperationCounters) record(op string, duration time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := c.counts[op]
+	count.Calls++
+	count.Duration += duration
+	if err != nil {
+		count.Failures++
+	}
+	c.counts[op] = count
+}
+
+// WithCounters - count every call in counters
+func WithCounters(counters *OperationCounters) Middleware {
+	return Intercept(func(ctx context.Context, call Call, next func(ctx context.Context) error) error {
+		start := time.Now()
+		err := next(ctx)
+		counters.record(call.Op, time.Since(start), err)
+
+		return err
+	})
+}
+
+// Fault - what a FaultInjector does to a call
+type Fault struct {
+	// Err - returned instead of making the call, when set
+	Err error
+	// Delay - waited before the call, giving up once the context of the call is done
+	Delay time.Duration
+	// Times - how many calls the fault applies to before it clears, zero for every call
+	Times int
+}
+
+// FaultInjector - faults injected into repository calls, for testing how callers cope with slow or failing storage
+type FaultInjector struct {
+	mu     sync.Mutex
+	faults map[string]Fault
+}
+
+func NewFaultInjector() *FaultInjector {
+	return &FaultInjector{faults: make(map[string]Fault)}
+}
+
+// Inject - apply fault to the calls to op, such as SaveNote, or to every call when op is "*"
+func (f *FaultInjector) Inject(op string, fault Fault) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.faults[op] = fault
+}
+
+// Clear - stop injecting faults
+func (f *FaultInjector) Clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	clear(f.faults)
+}
+
+// take - the fault to apply to a call to op, counting it against the times the fault applies
+func (f *FaultInjector) take(op string) (Fault, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, key := range []string{op, "*"} {
+		fault, ok := f.faults[key]
+		if !ok {
+			continue
+		}
+
+		if fault.Times > 0 {
+			if fault.Times == 1 {
+				delete(f.faults, key)
+			} else {
+				f.faults[key] = Fault{Err: fault.Err, Delay: fault.Delay, Times: fault.Times - 1}
+			}
+		}
+
+		return fault, true
+	}
+
+	return Fault{}, false
+}
+
+// WithFaults - delay or fail the calls faults has faults for
+func WithFaults(faults *FaultInjector) Middleware {
+	return Intercept(func(ctx context.Context, call Call, next func(ctx context.Context) error) error {
+		fault, ok := faults.take(call.Op)
+		if !ok {
+			return next(ctx)
+		}
+
+		if fault.Delay > 0 {
+			timer := time.NewTimer(fault.Delay)
+			defer timer.Stop()
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		if fault.Err != nil {
+			return fault.Err
+		}
+
+		return next(ctx)
+	})
+}
@@ -0,0 +1,234 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+)
+
+// Backlinker - a repository that knows which notes link to a note, and which note a link leads to
+type Backlinker interface {
+	Backlinks(ctx context.Context, note Note) ([]Backlink, error)
+	ResolveLink(ctx context.Context, from Note, link Link) (Note, error)
+}
+
+// Backlink - a note linking to another one, listed without its content, with the links pointing there
+type Backlink struct {
+	Note  Note
+	Links []Link
+}
+
+// LinkGraph - the links between the notes of a vault, resolved against the notes it holds; Build fills it from the
+// note summaries, which carry their links, and Follow keeps it up to date with the changes to the vault
+type LinkGraph struct {
+	mu sync.RWMutex
+	// notes - the summaries of the notes by their relative path
+	notes map[string]Note
+	names noteNames
+	// backlinks - the relative paths of the notes linking to a note, worked out again after every change
+	backlinks map[string][]string
+
+	// touched - the notes changed since Build started, which must not be replaced with what it listed before
+	touched map[string]bool
+	ready   chan struct{}
+	once    sync.Once
+}
+
+func NewLinkGraph() *LinkGraph {
+	return &LinkGraph{
+		notes: make(map[string]Note),
+		names: make(noteNames),
+		ready: make(chan struct{}),
+	}
+}
+
+// WithLinkGraph - a middleware answering Backlinks and ResolveLink from graph
+func WithLinkGraph(graph *LinkGraph) Middleware {
+	return func(repository Repository) Repository {
+		return &linkedRepository{RepositoryWrapper: RepositoryWrapper{Repository: repository}, graph: graph}
+	}
+}
+
+type linkedRepository struct {
+	RepositoryWrapper
+	graph *LinkGraph
+}
+
+func (r *linkedRepository) Backlinks(ctx context.Context, note Note) ([]Backlink, error) {
+	return r.graph.Backlinks(ctx, note)
+}
+
+func (r *linkedRepository) ResolveLink(ctx context.Context, from Note, link Link) (Note, error) {
+	return r.graph.ResolveLink(ctx, from, link)
+}
+
+// Build - add every note of repository to the graph; lookups wait until Build returned, even when it failed
+func (g *LinkGraph) Build(ctx context.Context, repository Repository) error {
+	defer g.once.Do(func() { close(g.ready) })
+
+	g.mu.Lock()
+	g.touched = make(map[string]bool)
+	g.mu.Unlock()
+
+	err := StreamNotes(ctx, repository, func(batch []Note) {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		for _, note := range batch {
+			if !g.touched[note.RelativePath()] {
+				g.add(note)
+			}
+		}
+	})
+
+	g.mu.Lock()
+	g.touched = nil
+	count := len(g.notes)
+	g.mu.Unlock()
+
+	if err != nil {
+		slog.Error("failed to build link graph", "error", err)
+		return err
+	}
+
+	slog.Info("built link graph", "count", count)
+	return nil
+}
+
+// Follow - apply every batch of changes until the channel is closed
+func (g *LinkGraph) Follow(changes <-chan []NoteChange) {
+	for batch := range changes {
+		g.Apply(batch)
+	}
+}
+
+// Apply - update the graph with changes made to the vault
+func (g *LinkGraph) Apply(changes []NoteChange) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, change := range changes {
+		if change.Kind == NoteRenamed {
+			g.touch(change.Previous.RelativePath())
+			g.remove(change.Previous.RelativePath())
+		}
+
+		g.touch(change.Note.RelativePath())
+		g.remove(change.Note.RelativePath())
+		if change.Kind != NoteDeleted {
+			g.add(change.Note)
+		}
+	}
+}
+
+// Resolve - the relative path of the note link in from points to, false when the vault has no such note
+func (g *LinkGraph) Resolve(from Note, link Link) (string, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.names.resolve(from.RelativePath(), link)
+}
+
+// ResolveLink - the summary of the note link in from points to, waiting for Build to finish; ErrNotFound when the
+// vault has no such note
+func (g *LinkGraph) ResolveLink(ctx context.Context, from Note, link Link) (Note, error) {
+	select {
+	case <-g.ready:
+	case <-ctx.Done():
+		return Note{}, ctx.Err()
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if target, ok := g.names.resolve(from.RelativePath(), link); ok {
+		if note, ok := g.notes[target]; ok {
+			return note, nil
+		}
+	}
+
+	return Note{}, fmt.Errorf("%w: %s", ErrNotFound, link.Target)
+}
+
+// Backlinks - the notes linking to note, ordered by path, waiting for Build to finish
+func (g *LinkGraph) Backlinks(ctx context.Context, note Note) ([]Backlink, error) {
+	select {
+	case <-g.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.backlinks == nil {
+		g.link()
+	}
+
+	target := note.RelativePath()
+
+	var backlinks []Backlink
+	for _, source := range g.backlinks[target] {
+		from := g.notes[source]
+
+		backlink := Backlink{Note: from}
+		for _, link := range from.Links() {
+			if resolved, ok := g.names.resolve(source, link); ok && resolved == target {
+				backlink.Links = append(backlink.Links, link)
+			}
+		}
+		backlinks = append(backlinks, backlink)
+	}
+
+	return backlinks, nil
+}
+
+// link - work out the backlinks of every note, leaving out the links of notes to themselves
+func (g *LinkGraph) link() {
+	g.backlinks = make(map[string][]string)
+
+	for source, note := range g.notes {
+		seen := map[string]bool{}
+		for _, link := range note.Links() {
+			target, ok := g.names.resolve(source, link)
+			if !ok || target == source || seen[target] {
+				continue
+			}
+
+			seen[target] = true
+			g.backlinks[target] = append(g.backlinks[target], source)
+		}
+	}
+
+	for _, sources := range g.backlinks {
+		sort.Strings(sources)
+	}
+}
+
+func (g *LinkGraph) touch(relativePath string) {
+	if g.touched != nil {
+		g.touched[relativePath] = true
+	}
+}
+
+func (g *LinkGraph) add(note Note) {
+	relativePath := note.RelativePath()
+	if _, ok := g.notes[relativePath]; !ok {
+		g.names.add(relativePath)
+	}
+
+	g.notes[relativePath] = note.summary()
+	g.backlinks = nil
+}
+
+func (g *LinkGraph) remove(relativePath string) {
+	if _, ok := g.notes[relativePath]; !ok {
+		return
+	}
+
+	delete(g.notes, relativePath)
+	g.names.remove(relativePath)
+	g.backlinks = nil
+}
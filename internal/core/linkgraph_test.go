@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func backlinkPaths(backlinks []Backlink) []string {
+	var paths []string
+	for _, backlink := range backlinks {
+		paths = append(paths, backlink.Note.RelativePath())
+	}
+
+	return paths
+}
+
+func TestLinkGraph(t *testing.T) {
+	ctx := context.Background()
+
+	graph := NewLinkGraph()
+	repository := ChainRepository(NewMemoryRepository(
+		NewNote("target.md", "# Target\nLinks to [[#Itself]]."),
+		NewNote("wiki.md", "See [[Target]] and again [[target|the target]]."),
+		NewNote("notes/markdown.md", "See [the target](../target.md)."),
+		NewNote("unrelated.md", "See [[Missing]]."),
+	), WithLinkGraph(graph))
+
+	if err := graph.Build(ctx, repository); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	target, _ := repository.GetNoteByTitle(ctx, "target")
+
+	t.Run("every note linking to a note is a backlink", func(t *testing.T) {
+		backlinks, err := repository.Backlinks(ctx, target)
+		if err != nil {
+			t.Fatalf("Backlinks failed: %v", err)
+		}
+
+		paths := backlinkPaths(backlinks)
+		if len(paths) != 2 || paths[0] != "notes/markdown.md" || paths[1] != "wiki.md" {
+			t.Fatalf("Expected the markdown and the wiki note, got %v", paths)
+		}
+		if len(backlinks[1].Links) != 2 || backlinks[1].Links[1].Text != "the target" {
+			t.Errorf("Expected both links of the wiki note, got %+v", backlinks[1].Links)
+		}
+		if backlinks[0].Note.Loaded() {
+			t.Error("Expected the backlinks to be listed without their content")
+		}
+	})
+
+	t.Run("changes to the vault update the backlinks", func(t *testing.T) {
+		graph.Apply([]NoteChange{
+			{Kind: NoteCreated, Note: NewNote("new.md", "Also [[Target]].")},
+			{Kind: NoteUpdated, Note: NewNote("wiki.md", "No more links.")},
+			{Kind: NoteRenamed, Note: NewNote("notes/moved.md", "See [the target](../target.md)."), Previous: NewNote("notes/markdown.md", "")},
+		})
+
+		backlinks, _ := graph.Backlinks(ctx, target)
+		paths := backlinkPaths(backlinks)
+		if len(paths) != 2 || paths[0] != "new.md" || paths[1] != "notes/moved.md" {
+			t.Errorf("Expected the new and the moved note, got %v", paths)
+		}
+	})
+
+	t.Run("links resolve once their target is created", func(t *testing.T) {
+		graph.Apply([]NoteChange{{Kind: NoteCreated, Note: NewNote("Missing.md", "")}})
+
+		backlinks, _ := graph.Backlinks(ctx, NewNote("Missing.md", ""))
+		if paths := backlinkPaths(backlinks); len(paths) != 1 || paths[0] != "unrelated.md" {
+			t.Errorf("Expected the note linking to the created one, got %v", paths)
+		}
+	})
+}
+
+func TestLinkGraphWaitsForBuild(t *testing.T) {
+	graph := NewLinkGraph()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := graph.Backlinks(ctx, NewNote("note.md", "")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the lookup to wait for the graph until ctx is done, got %v", err)
+	}
+}
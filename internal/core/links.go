@@ -0,0 +1,251 @@
+package core
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LinkKind - how a link was written
+type LinkKind int
+
+const (
+	// WikiLink - [[Target#Fragment|Text]], where Target is the name or vault path of a note
+	WikiLink LinkKind = iota
+	// MarkdownLink - [Text](target.md#fragment), where the target is relative to the folder of the linking note
+	MarkdownLink
+)
+
+// Link - a link from a note to a note of the vault, or to a part of itself when Target is empty
+type Link struct {
+	Kind LinkKind `json:"kind"`
+	// Target - the note linked to as written, without the fragment
+	Target string `json:"target"`
+	// Fragment - the heading or ^block linked to, without the leading #
+	Fragment string `json:"fragment,omitempty"`
+	// Text - the alias of a wiki link or the text of a markdown link
+	Text string `json:"text,omitempty"`
+	// Embed - the link was written as ![[Target]] to show the note in place
+	Embed bool `json:"embed,omitempty"`
+	// Start, End - the byte offsets of the whole link in the note content
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Label - the text a link shows, its target when it has no text of its own
+func (l Link) Label() string {
+	if l.Text != "" {
+		return l.Text
+	}
+	if l.Target == "" {
+		return l.Fragment
+	}
+
+	return l.Target
+}
+
+var (
+	wikiLinkPattern     = regexp.MustCompile(`(!?)\[\[([^\[\]\n]+)\]\]`)
+	markdownLinkPattern = regexp.MustCompile(`(!?)\[([^\[\]\n]*)\]\((?:<([^<>\n]+)>|([^()<>\s]+))(?:\s+"[^"\n]*")?\)`)
+	urlSchemePattern    = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+)
+
+// extractLinks - the wiki and markdown links to notes in body, which starts offset bytes into the note content,
+// leaving out those in code; markdown links to other files and to URLs are not links to notes
+func extractLinks(body string, offset int) []Link {
+	var links []Link
+
+	inFence := false
+	position := offset
+	for _, line := range strings.SplitAfter(body, "\n") {
+		lineStart := position
+		position += len(line)
+
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		masked := maskInlineCode(line)
+		for _, match := range wikiLinkPattern.FindAllStringSubmatchIndex(masked, -1) {
+			links = append(links, parseWikiLink(line, match, lineStart))
+		}
+		for _, match := range markdownLinkPattern.FindAllStringSubmatchIndex(masked, -1) {
+			if link, ok := parseMarkdownLink(line, match, lineStart); ok {
+				links = append(links, link)
+			}
+		}
+	}
+
+	sort.SliceStable(links, func(i, j int) bool {
+		return links[i].Start < links[j].Start
+	})
+
+	return links
+}
+
+func parseWikiLink(line string, match []int, lineStart int) Link {
+	inner := line[match[4]:match[5]]
+
+	target, text, _ := strings.Cut(inner, "|")
+	target, fragment, _ := strings.Cut(target, "#")
+
+	return Link{
+		Kind:     WikiLink,
+		Target:   strings.TrimSpace(target),
+		Fragment: strings.TrimSpace(fragment),
+		Text:     strings.TrimSpace(text),
+		Embed:    match[3] > match[2],
+		Start:    lineStart + match[0],
+		End:      lineStart + match[1],
+	}
+}
+
+func parseMarkdownLink(line string, match []int, lineStart int) (Link, bool) {
+	// ![alt](picture.png) shows an image rather than linking to a note
+	if match[3] > match[2] {
+		return Link{}, false
+	}
+
+	destination := ""
+	if match[6] >= 0 {
+		destination = line[match[6]:match[7]]
+	} else {
+		destination = line[match[8]:match[9]]
+	}
+
+	if urlSchemePattern.MatchString(destination) || strings.HasPrefix(destination, "//") {
+		return Link{}, false
+	}
+
+	target, fragment, _ := strings.Cut(destination, "#")
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+	if ext := path.Ext(target); ext != "" && ext != ".md" {
+		return Link{}, false
+	}
+
+	return Link{
+		Kind:     MarkdownLink,
+		Target:   target,
+		Fragment: fragment,
+		Text:     line[match[4]:match[5]],
+		Start:    lineStart + match[0],
+		End:      lineStart + match[1],
+	}, true
+}
+
+// maskInlineCode - the line with its inline code spans blanked out, keeping every other byte where it was
+func maskInlineCode(line string) string {
+	masked := []byte(line)
+
+	inCode := false
+	for i, c := range masked {
+		if c == '`' {
+			inCode = !inCode
+			continue
+		}
+		if inCode && c != '\n' {
+			masked[i] = ' '
+		}
+	}
+
+	return string(masked)
+}
+
+// noteNames - the relative paths of the notes of a vault by their lower cased file name without .md, which wiki
+// links resolve against
+type noteNames map[string][]string
+
+func (n noteNames) add(relativePath string) {
+	name := noteName(relativePath)
+	n[name] = append(n[name], relativePath)
+}
+
+func (n noteNames) remove(relativePath string) {
+	name := noteName(relativePath)
+
+	paths := n[name]
+	for i, p := range paths {
+		if p == relativePath {
+			paths = append(paths[:i], paths[i+1:]...)
+			break
+		}
+	}
+
+	if len(paths) == 0 {
+		delete(n, name)
+	} else {
+		n[name] = paths
+	}
+}
+
+// resolve - the relative path of the note link points to from the note at from, false when there is no such note;
+// a wiki link naming a note without its folder prefers the note next to from, then the one closest to the root
+func (n noteNames) resolve(from string, link Link) (string, bool) {
+	if link.Target == "" {
+		return from, true
+	}
+
+	if link.Kind == MarkdownLink {
+		target := markdownTarget(from, link)
+		if path.Ext(target) == "" {
+			target += ".md"
+		}
+
+		for _, candidate := range n[noteName(target)] {
+			if candidate == target {
+				return candidate, true
+			}
+		}
+		return "", false
+	}
+
+	wanted := strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(link.Target, "/"), ".md"))
+
+	var matches []string
+	for _, candidate := range n[noteName(wanted)] {
+		name := strings.ToLower(strings.TrimSuffix(candidate, ".md"))
+		if name == wanted || strings.HasSuffix(name, "/"+wanted) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	folder := path.Dir(from)
+	sort.Slice(matches, func(i, j int) bool {
+		if near := path.Dir(matches[i]) == folder; near != (path.Dir(matches[j]) == folder) {
+			return near
+		}
+		if depth := strings.Count(matches[i], "/"); depth != strings.Count(matches[j], "/") {
+			return depth < strings.Count(matches[j], "/")
+		}
+		return matches[i] < matches[j]
+	})
+
+	return matches[0], true
+}
+
+// markdownTarget - the vault path the markdown link in the note at from points to, relative to the folder of the
+// note, or to the root of the vault when it starts with a slash
+func markdownTarget(from string, link Link) string {
+	if strings.HasPrefix(link.Target, "/") {
+		return path.Clean(strings.TrimLeft(link.Target, "/"))
+	}
+
+	return path.Clean(path.Join(path.Dir(from), link.Target))
+}
+
+// noteName - the lower cased file name of the note at relativePath, without .md
+func noteName(relativePath string) string {
+	return strings.ToLower(strings.TrimSuffix(path.Base(relativePath), ".md"))
+}
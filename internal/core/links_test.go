@@ -0,0 +1,72 @@
+package core
+
+import "testing"
+
+func TestExtractLinks(t *testing.T) {
+	content := "---\ntitle: Links\n---\n" +
+		"See [[Other Note]] and [[projects/Plan#Goals|the goals]], ![[Diagram]] or [[#Summary]].\n" +
+		"Markdown [sibling](sibling.md), [deeper](../archive/Old%20Note.md#top), [no extension](<Other Note>).\n" +
+		"Not notes: [site](https://example.com), [mail](mailto:me@example.com), ![image](picture.png), [pdf](doc.pdf).\n" +
+		"`[[inline code]]` stays code, like\n```\n[[fenced]] and [fenced](fenced.md)\n```\n"
+
+	note := NewNote("notes/links.md", content)
+	links := note.Links()
+
+	expected := []Link{
+		{Kind: WikiLink, Target: "Other Note"},
+		{Kind: WikiLink, Target: "projects/Plan", Fragment: "Goals", Text: "the goals"},
+		{Kind: WikiLink, Target: "Diagram", Embed: true},
+		{Kind: WikiLink, Fragment: "Summary"},
+		{Kind: MarkdownLink, Target: "sibling.md", Text: "sibling"},
+		{Kind: MarkdownLink, Target: "../archive/Old Note.md", Fragment: "top", Text: "deeper"},
+		{Kind: MarkdownLink, Target: "Other Note", Text: "no extension"},
+	}
+
+	if len(links) != len(expected) {
+		t.Fatalf("Expected %d links, got %d: %+v", len(expected), len(links), links)
+	}
+
+	for i, want := range expected {
+		got := links[i]
+		if got.Kind != want.Kind || got.Target != want.Target || got.Fragment != want.Fragment || got.Text != want.Text || got.Embed != want.Embed {
+			t.Errorf("Expected link %d to be %+v, got %+v", i, want, got)
+		}
+	}
+
+	if written := content[links[1].Start:links[1].End]; written != "[[projects/Plan#Goals|the goals]]" {
+		t.Errorf("Expected the offsets to span the link in the content, got %q", written)
+	}
+	if written := content[links[5].Start:links[5].End]; written != "[deeper](../archive/Old%20Note.md#top)" {
+		t.Errorf("Expected the offsets to span the markdown link in the content, got %q", written)
+	}
+}
+
+func TestResolveLinks(t *testing.T) {
+	names := noteNames{}
+	for _, relativePath := range []string{"Other Note.md", "projects/Plan.md", "projects/Other Note.md", "archive/Old Note.md", "a/b/Deep.md", "c/Deep.md"} {
+		names.add(relativePath)
+	}
+
+	for _, test := range []struct {
+		from     string
+		link     Link
+		expected string
+	}{
+		{"index.md", Link{Kind: WikiLink, Target: "other note"}, "Other Note.md"},
+		{"projects/todo.md", Link{Kind: WikiLink, Target: "Other Note"}, "projects/Other Note.md"},
+		{"index.md", Link{Kind: WikiLink, Target: "projects/Other Note"}, "projects/Other Note.md"},
+		{"index.md", Link{Kind: WikiLink, Target: "Plan.md"}, "projects/Plan.md"},
+		{"index.md", Link{Kind: WikiLink, Target: "Deep"}, "c/Deep.md"},
+		{"index.md", Link{Kind: WikiLink, Fragment: "Summary"}, "index.md"},
+		{"projects/todo.md", Link{Kind: MarkdownLink, Target: "../archive/Old Note.md"}, "archive/Old Note.md"},
+		{"projects/todo.md", Link{Kind: MarkdownLink, Target: "Plan"}, "projects/Plan.md"},
+		{"projects/todo.md", Link{Kind: MarkdownLink, Target: "/archive/Old Note.md"}, "archive/Old Note.md"},
+		{"index.md", Link{Kind: WikiLink, Target: "Missing"}, ""},
+		{"index.md", Link{Kind: MarkdownLink, Target: "Plan.md"}, ""},
+	} {
+		got, ok := names.resolve(test.from, test.link)
+		if got != test.expected || ok != (test.expected != "") {
+			t.Errorf("Expected %+v from %s to resolve to %q, got %q", test.link, test.from, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package core
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// loadConcurrency - how many note files are read at the same time while loading a vault
+	loadConcurrency = 8
+	// loadBatchSize - how many loaded notes are handed on together while loading a vault
+	loadBatchSize = 256
+)
+
+// NoteStreamer - a repository handing out its notes in batches while it loads them, so they can be shown early;
+// batches come in no particular order, one at a time, and none comes once StreamNotes returned
+type NoteStreamer interface {
+	StreamNotes(ctx context.Context, batch func([]Note)) error
+}
+
+// StreamNotes - hand the notes of repository to batch as they load, all at once when it cannot stream them
+func StreamNotes(ctx context.Context, repository Repository, batch func([]Note)) error {
+	if streamer, ok := repository.(NoteStreamer); ok {
+		return streamer.StreamNotes(ctx, batch)
+	}
+
+	notes, err := repository.GetAllNotes(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(notes) > 0 {
+		batch(notes)
+	}
+	return nil
+}
+
+// LoadNote - the note with its content, reading it from repository when the note was listed without it
+func LoadNote(ctx context.Context, repository Repository, note Note) (Note, error) {
+	if note.Loaded() {
+		return note, nil
+	}
+
+	return repository.GetNoteByTitle(ctx, strings.TrimSuffix(note.RelativePath(), ".md"))
+}
+
+// StreamNotes - hand the summaries of the notes to batch as they load, stopping once ctx is done even when the
+// filesystem hangs
+func (r *NoteRepository) StreamNotes(ctx context.Context, batch func([]Note)) error {
+	var mu sync.Mutex
+	returned := false
+
+	_, err := awaitContext(ctx, func() (struct{}, error) {
+		return struct{}{}, r.streamNotes(ctx, func(notes []Note) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if !returned {
+				batch(notes)
+			}
+		})
+	})
+
+	mu.Lock()
+	returned = true
+	mu.Unlock()
+
+	return err
+}
+
+// streamNotes - load the summaries of the notes, taking the unchanged ones from the index and reading the others
+// loadConcurrency at a time
+func (r *NoteRepository) streamNotes(ctx context.Context, batch func([]Note)) error {
+	loader := &noteLoader{batch: batch, entries: map[string]indexEntry{}, now: time.Now()}
+	files := make(chan pendingFile)
+
+	var wg sync.WaitGroup
+	for range loadConcurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range files {
+				if ctx.Err() == nil {
+					loader.readFile(r.basePath, file)
+				}
+			}
+		}()
+	}
+
+	err := walkNotes(ctx, r.basePath, func(filePath string, _ fs.DirEntry) error {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			slog.Warn("failed to read file", "file", filePath, "error", err)
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(r.basePath, filePath)
+		if err != nil {
+			return nil
+		}
+		relativePath = filepath.ToSlash(relativePath)
+
+		if note, entry, ok := r.index.lookup(r.basePath, relativePath, info); ok {
+			loader.add(note, relativePath, &entry)
+			return nil
+		}
+
+		select {
+		case files <- pendingFile{path: filePath, relativePath: relativePath, info: info}:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	close(files)
+	wg.Wait()
+
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		slog.Error("failed to read markdown files", "error", err)
+		return err
+	}
+
+	loader.flush()
+	r.index.replace(loader.entries, loader.read > 0)
+
+	slog.Info("loaded notes", "count", loader.count, "read", loader.read)
+	return nil
+}
+
+// pendingFile - a note file that is not indexed or changed since, waiting to be read
+type pendingFile struct {
+	path, relativePath string
+	info               fs.FileInfo
+}
+
+// noteLoader - the notes loaded so far, handed on a batch at a time, and the index entries to keep for them
+type noteLoader struct {
+	mu      sync.Mutex
+	batch   func([]Note)
+	pending []Note
+	entries map[string]indexEntry
+	now     time.Time
+	count   int
+	read    int
+}
+
+func (l *noteLoader) readFile(basePath string, file pendingFile) {
+	content, err := os.ReadFile(file.path)
+	if err != nil {
+		slog.Warn("failed to read file", "file", file.path, "error", err)
+		return
+	}
+
+	note := newVaultNote(basePath, file.path, string(content))
+
+	var entry *indexEntry
+	if indexed, ok := newIndexEntry(note, file.info, l.now); ok {
+		entry = &indexed
+	}
+
+	l.mu.Lock()
+	l.read++
+	l.mu.Unlock()
+
+	l.add(note.summary(), file.relativePath, entry)
+}
+
+// add - collect a loaded note, handing the collected notes on once there are enough of them
+func (l *noteLoader) add(note Note, relativePath string, entry *indexEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.count++
+	if entry != nil {
+		l.entries[relativePath] = *entry
+	}
+
+	l.pending = append(l.pending, note)
+	if len(l.pending) >= loadBatchSize {
+		l.batch(l.pending)
+		l.pending = nil
+	}
+}
+
+func (l *noteLoader) flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.pending) > 0 {
+		l.batch(l.pending)
+		l.pending = nil
+	}
+}
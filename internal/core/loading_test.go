@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNoteRepositoryStreamNotes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("notes are handed on in batches", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		count := loadBatchSize*2 + 10
+		for i := range count {
+			content := fmt.Sprintf("# Note %d", i)
+			if err := os.WriteFile(filepath.Join(tempDir, fmt.Sprintf("note%03d.md", i)), []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write note: %v", err)
+			}
+		}
+
+		repository := NewNoteRepository(tempDir)
+		var batches, streamed int
+		seen := map[string]bool{}
+		err := repository.StreamNotes(ctx, func(notes []Note) {
+			batches++
+			streamed += len(notes)
+			for _, note := range notes {
+				seen[note.RelativePath()] = true
+			}
+		})
+		if err != nil {
+			t.Fatalf("StreamNotes failed: %v", err)
+		}
+
+		if batches != 3 || streamed != count || len(seen) != count {
+			t.Errorf("Expected %d distinct notes in 3 batches, got %d in %d batches", count, len(seen), batches)
+		}
+	})
+
+	t.Run("listed notes leave their content to LoadNote", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		content := "---\ntitle: Shopping\n---\n# List\n#home"
+		if err := os.WriteFile(filepath.Join(tempDir, "list.md"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write note: %v", err)
+		}
+
+		repository := NewNoteRepository(tempDir)
+		notes, err := repository.GetAllNotes(ctx)
+		if err != nil || len(notes) != 1 {
+			t.Fatalf("Expected one note, got %v, %v", notes, err)
+		}
+
+		listed := notes[0]
+		if listed.Loaded() || listed.FileContent() != "" {
+			t.Errorf("Expected the listed note without its content, got %q", listed.FileContent())
+		}
+		if listed.Title() != "Shopping" || listed.Description() != "List" || !listed.HasTag("home") {
+			t.Errorf("Expected the listed note to keep its title, description and tags, got %q %q %v", listed.Title(), listed.Description(), listed.Tags())
+		}
+
+		loaded, err := LoadNote(ctx, &repository, listed)
+		if err != nil || !loaded.Loaded() || loaded.FileContent() != content {
+			t.Fatalf("Expected LoadNote to read the content, got %q, %v", loaded.FileContent(), err)
+		}
+		if loaded.Version() != listed.Version() {
+			t.Errorf("Expected the listed and the loaded note to share their version")
+		}
+	})
+
+	t.Run("no batch comes after a cancelled stream returned", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		for i := range loadBatchSize * 4 {
+			if err := os.WriteFile(filepath.Join(tempDir, fmt.Sprintf("note%04d.md", i)), []byte("# Note"), 0644); err != nil {
+				t.Fatalf("Failed to write note: %v", err)
+			}
+		}
+
+		cancelCtx, cancel := context.WithCancel(ctx)
+		returned := make(chan struct{})
+		late := make(chan struct{}, 1)
+
+		repository := NewNoteRepository(tempDir)
+		err := repository.StreamNotes(cancelCtx, func([]Note) {
+			cancel()
+			select {
+			case <-returned:
+				late <- struct{}{}
+			default:
+			}
+		})
+		close(returned)
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+
+		select {
+		case <-late:
+			t.Error("Expected no batch after StreamNotes returned")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Backend - how the notes of a vault are stored
+type Backend string
+
+const (
+	// BackendFile - one markdown file per note below a directory
+	BackendFile Backend = "file"
+	// BackendSQLite - every note in a single SQLite database file
+	BackendSQLite Backend = "sqlite"
+)
+
+// Location - where and how the notes of a vault are stored
+type Location struct {
+	Backend Backend
+	Path    string
+}
+
+func (l Location) String() string {
+	return string(l.Backend) + "://" + l.Path
+}
+
+// ParseLocation - the location of a vault given as a path, stored with defaultBackend, or as a URL such as
+// sqlite:///home/me/notes.db or file://notes
+func ParseLocation(location string, defaultBackend Backend) (Location, error) {
+	scheme, rest, found := strings.Cut(location, ":")
+
+	// a single letter before the colon is a Windows drive rather than a scheme
+	if !found || len(scheme) == 1 {
+		return Location{Backend: defaultBackend, Path: location}, validateLocation(defaultBackend, location)
+	}
+
+	backend := Backend(strings.ToLower(scheme))
+	path := strings.TrimPrefix(rest, "//")
+
+	return Location{Backend: backend, Path: path}, validateLocation(backend, path)
+}
+
+func validateLocation(backend Backend, path string) error {
+	if backend != BackendFile && backend != BackendSQLite {
+		return fmt.Errorf("unknown notes backend %q, use %s or %s", backend, BackendFile, BackendSQLite)
+	}
+	if path == "" {
+		return fmt.Errorf("missing path for the %s notes backend", backend)
+	}
+
+	return nil
+}
+
+// OpenRepository - the repository stored at location, which may need closing through io.Closer
+func OpenRepository(location Location) (Repository, error) {
+	switch location.Backend {
+	case BackendFile:
+		repository := NewNoteRepository(location.Path)
+		return &repository, nil
+	case BackendSQLite:
+		return NewSQLiteRepository(location.Path)
+	default:
+		return nil, validateLocation(location.Backend, location.Path)
+	}
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		location string
+		expected Location
+		wantErr  bool
+	}{
+		{location: "notes", expected: Location{Backend: BackendFile, Path: "notes"}},
+		{location: "sqlite:///home/me/notes.db", expected: Location{Backend: BackendSQLite, Path: "/home/me/notes.db"}},
+		{location: "sqlite://notes.db", expected: Location{Backend: BackendSQLite, Path: "notes.db"}},
+		{location: "sqlite:notes.db", expected: Location{Backend: BackendSQLite, Path: "notes.db"}},
+		{location: "file:///home/me/notes", expected: Location{Backend: BackendFile, Path: "/home/me/notes"}},
+		{location: `C:\notes`, expected: Location{Backend: BackendFile, Path: `C:\notes`}},
+		{location: "http://example.com", wantErr: true},
+		{location: "sqlite://", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			location, err := ParseLocation(tt.location, BackendFile)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error for %q", tt.location)
+				}
+				return
+			}
+
+			if err != nil || location != tt.expected {
+				t.Errorf("Expected %+v, got %+v, %v", tt.expected, location, err)
+			}
+		})
+	}
+}
+
+func TestCopyNotes(t *testing.T) {
+	t.Run("exports a directory to a database and imports it back", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		files := NewNoteRepository(filepath.Join(tempDir, "notes"))
+		note, _ := files.CreateEmptyNote(ctx, "projects/alpha")
+		_, _ = files.SaveNote(ctx, note.WithContent("alpha content"))
+		_, _ = files.CreateEmptyNote(ctx, "beta")
+
+		database, err := OpenRepository(Location{Backend: BackendSQLite, Path: filepath.Join(tempDir, "notes.db")})
+		if err != nil {
+			t.Fatalf("OpenRepository failed: %v", err)
+		}
+		defer database.(*SQLiteRepository).Close()
+
+		copied, err := CopyNotes(ctx, &files, database)
+		if err != nil || copied != 2 {
+			t.Fatalf("Expected 2 exported notes, got %d, %v", copied, err)
+		}
+
+		exported, _ := database.GetNoteByTitle(ctx, "projects/alpha")
+		if exported.FileContent() != "alpha content" {
+			t.Errorf("Expected the exported content, got %q", exported.FileContent())
+		}
+
+		_, _ = database.SaveNote(ctx, exported.WithContent("changed in the database"))
+
+		copied, err = CopyNotes(ctx, database, &files)
+		if err != nil || copied != 2 {
+			t.Fatalf("Expected 2 imported notes, got %d, %v", copied, err)
+		}
+
+		imported, _ := files.GetNoteByTitle(ctx, "projects/alpha")
+		if imported.FileContent() != "changed in the database" {
+			t.Errorf("Expected existing notes to be replaced, got %q", imported.FileContent())
+		}
+	})
+}
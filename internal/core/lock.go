@@ -0,0 +1,355 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	vaultLockName  = ".elephant.lock"
+	noteLocksDir   = ".locks"
+	lockStaleAfter = 2 * time.Minute
+)
+
+// lockRefreshInterval - how often held locks are refreshed, well within lockStaleAfter
+var lockRefreshInterval = lockStaleAfter / 4
+
+// LockInfo - who holds a lock, stored as the content of the lock file
+type LockInfo struct {
+	PID         int       `json:"pid"`
+	Host        string    `json:"host"`
+	AcquiredAt  time.Time `json:"acquired_at"`
+	RefreshedAt time.Time `json:"refreshed_at"`
+}
+
+// currentLockInfo - a lock owned by this process
+func currentLockInfo(now time.Time) LockInfo {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	return LockInfo{PID: os.Getpid(), Host: host, AcquiredAt: now, RefreshedAt: now}
+}
+
+// ownedBy - whether the lock was taken by the process described by owner
+func (l LockInfo) ownedBy(owner LockInfo) bool {
+	return l.PID == owner.PID && l.Host == owner.Host
+}
+
+// stale - whether the lock can be taken over: it has not been refreshed for longer than lockStaleAfter, or its
+// process is gone when it lives on this host, where a reused pid would otherwise keep a dead lock alive
+func (l LockInfo) stale(now time.Time) bool {
+	if now.Sub(l.RefreshedAt) > lockStaleAfter {
+		return true
+	}
+
+	return l.Host == currentLockInfo(now).Host && l.PID > 0 && !processAlive(l.PID)
+}
+
+// acquireLockFile - create the lock file at path for owner, taking over a stale or unreadable lock
+func acquireLockFile(path string, owner LockInfo) error {
+	content, err := json.Marshal(owner)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		err = writeNewFile(path, content)
+		if !errors.Is(err, ErrAlreadyExists) {
+			return err
+		}
+
+		existing, readErr := readLockFile(path)
+		if readErr == nil && existing.ownedBy(owner) {
+			return writeFileAtomic(path, content, 0644)
+		}
+		if readErr == nil && !existing.stale(time.Now()) {
+			return &LockedError{Path: path, Owner: existing}
+		}
+
+		return takeOverLockFile(path, owner, content)
+	}
+
+	existing, _ := readLockFile(path)
+	return &LockedError{Path: path, Owner: existing}
+}
+
+// takeOverLockFile - replace the stale lock at path with content while holding its takeover guard, so that of the
+// instances finding the lock stale at the same time only one replaces it, and the lock never goes missing meanwhile
+func takeOverLockFile(path string, owner LockInfo, content []byte) error {
+	guard := path + ".takeover"
+
+	err := writeNewFile(guard, nil)
+	if errors.Is(err, ErrAlreadyExists) {
+		// a guard left behind by an instance that died while taking over
+		if info, statErr := os.Stat(guard); statErr == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			slog.Warn("removing stale lock takeover", "file", guard)
+			if err := os.Remove(guard); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			err = writeNewFile(guard, nil)
+		}
+	}
+	if errors.Is(err, ErrAlreadyExists) {
+		existing, _ := readLockFile(path)
+		return &LockedError{Path: path, Owner: existing}
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(guard); err != nil {
+			slog.Warn("failed to remove lock takeover", "file", guard, "error", err)
+		}
+	}()
+
+	existing, readErr := readLockFile(path)
+	if readErr == nil && !existing.ownedBy(owner) && !existing.stale(time.Now()) {
+		return &LockedError{Path: path, Owner: existing}
+	}
+
+	slog.Warn("taking over stale lock", "file", path, "owner", existing, "error", readErr)
+	if err := writeFileAtomic(path, content, 0644); err != nil {
+		return err
+	}
+
+	existing, err = readLockFile(path)
+	if err != nil {
+		return err
+	}
+	if !existing.ownedBy(owner) {
+		return &LockedError{Path: path, Owner: existing}
+	}
+
+	return nil
+}
+
+// refreshLockFile - bump the timestamp of a lock owned by owner, failing when it was taken over
+func refreshLockFile(path string, owner LockInfo, now time.Time) error {
+	existing, err := readLockFile(path)
+	if err != nil {
+		return err
+	}
+	if !existing.ownedBy(owner) {
+		return &LockedError{Path: path, Owner: existing}
+	}
+
+	existing.RefreshedAt = now
+	content, err := json.Marshal(existing)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, content, 0644)
+}
+
+// releaseLockFile - remove the lock file when it is still owned by owner
+func releaseLockFile(path string, owner LockInfo) error {
+	existing, err := readLockFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err == nil && !existing.ownedBy(owner) {
+		return nil
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func readLockFile(path string) (LockInfo, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return LockInfo{}, err
+	}
+
+	var info LockInfo
+	err = json.Unmarshal(content, &info)
+	return info, err
+}
+
+// VaultLock - the single-writer lock of a vault, refreshed in the background until it is released
+type VaultLock struct {
+	path  string
+	owner LockInfo
+	done  chan struct{}
+	once  sync.Once
+}
+
+// AcquireVaultLock - take the lock of the vault at basePath, returning a *LockedError when another live
+// instance holds it
+func AcquireVaultLock(basePath string) (*VaultLock, error) {
+	err := os.MkdirAll(basePath, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &VaultLock{
+		path:  filepath.Join(basePath, vaultLockName),
+		owner: currentLockInfo(time.Now()),
+		done:  make(chan struct{}),
+	}
+
+	err = acquireLockFile(lock.path, lock.owner)
+	if err != nil {
+		slog.Warn("failed to lock vault", "path", basePath, "error", err)
+		return nil, err
+	}
+
+	go refreshPeriodically(lock.done, func(now time.Time) {
+		if err := refreshLockFile(lock.path, lock.owner, now); err != nil {
+			slog.Error("failed to refresh vault lock", "file", lock.path, "error", err)
+		}
+	})
+
+	return lock, nil
+}
+
+// Release - stop refreshing the lock and remove it
+func (l *VaultLock) Release() error {
+	var err error
+	l.once.Do(func() {
+		close(l.done)
+		err = releaseLockFile(l.path, l.owner)
+	})
+
+	return err
+}
+
+// NoteLocks - advisory per-note locks shared between instances, so that a note being edited in one
+// instance cannot be changed from another
+type NoteLocks struct {
+	basePath string
+	owner    LockInfo
+	done     chan struct{}
+	once     sync.Once
+
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+func NewNoteLocks(basePath string) *NoteLocks {
+	locks := &NoteLocks{
+		basePath: basePath,
+		owner:    currentLockInfo(time.Now()),
+		done:     make(chan struct{}),
+		held:     map[string]bool{},
+	}
+
+	go refreshPeriodically(locks.done, locks.refresh)
+
+	return locks
+}
+
+// LockNote - take the lock of note, returning a *LockedError when another instance holds it
+func (l *NoteLocks) LockNote(note Note) error {
+	path := l.lockPath(note)
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	owner := l.owner
+	owner.AcquiredAt = time.Now()
+	owner.RefreshedAt = owner.AcquiredAt
+
+	err = acquireLockFile(path, owner)
+	if err != nil {
+		slog.Warn("failed to lock note", "file", note.FilePath(), "error", err)
+		return err
+	}
+
+	l.mu.Lock()
+	l.held[path] = true
+	l.mu.Unlock()
+
+	return nil
+}
+
+// UnlockNote - release the lock of note if this instance holds it
+func (l *NoteLocks) UnlockNote(note Note) error {
+	path := l.lockPath(note)
+
+	l.mu.Lock()
+	delete(l.held, path)
+	l.mu.Unlock()
+
+	return releaseLockFile(path, l.owner)
+}
+
+// CheckNote - make sure no other instance holds the lock of note
+func (l *NoteLocks) CheckNote(note Note) error {
+	path := l.lockPath(note)
+
+	existing, err := readLockFile(path)
+	if err != nil || existing.ownedBy(l.owner) || existing.stale(time.Now()) {
+		return nil
+	}
+
+	return &LockedError{Path: note.FilePath(), Owner: existing}
+}
+
+// Close - stop refreshing and release every held lock
+func (l *NoteLocks) Close() error {
+	l.once.Do(func() {
+		close(l.done)
+	})
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs []error
+	for path := range l.held {
+		errs = append(errs, releaseLockFile(path, l.owner))
+		delete(l.held, path)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (l *NoteLocks) refresh(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for path := range l.held {
+		if err := refreshLockFile(path, l.owner, now); err != nil {
+			slog.Error("failed to refresh note lock", "file", path, "error", err)
+		}
+	}
+}
+
+// lockPath - the lock file of note, named after its path relative to the vault
+func (l *NoteLocks) lockPath(note Note) string {
+	relativePath, err := filepath.Rel(l.basePath, note.FilePath())
+	if err != nil {
+		relativePath = note.FilePath()
+	}
+
+	return filepath.Join(l.basePath, noteLocksDir, HashContent(filepath.ToSlash(relativePath))+".lock")
+}
+
+func refreshPeriodically(done <-chan struct{}, refresh func(now time.Time)) {
+	ticker := time.NewTicker(lockRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			refresh(now)
+		}
+	}
+}
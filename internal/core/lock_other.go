@@ -0,0 +1,16 @@
+//go:build !unix
+
+package core
+
+import "os"
+
+// processAlive - whether a process with the given pid is running on this host
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	_ = process.Release()
+	return true
+}
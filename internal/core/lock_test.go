@@ -0,0 +1,236 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestVaultLock(t *testing.T) {
+	t.Run("a second lock on the same vault reports the owner", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		writeLockFile(t, filepath.Join(tempDir, vaultLockName), LockInfo{PID: os.Getppid(), Host: currentLockInfo(time.Now()).Host, RefreshedAt: time.Now()})
+
+		_, err := AcquireVaultLock(tempDir)
+		var locked *LockedError
+		if !errors.As(err, &locked) || !errors.Is(err, ErrLocked) {
+			t.Fatalf("Expected a LockedError, got %v", err)
+		}
+		if locked.Owner.PID != os.Getppid() {
+			t.Errorf("Expected the owner pid %d, got %d", os.Getppid(), locked.Owner.PID)
+		}
+	})
+
+	t.Run("releasing removes the lock file", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		lock, err := AcquireVaultLock(tempDir)
+		if err != nil {
+			t.Fatalf("AcquireVaultLock failed: %v", err)
+		}
+
+		info, err := readLockFile(filepath.Join(tempDir, vaultLockName))
+		if err != nil || info.PID != os.Getpid() {
+			t.Errorf("Expected the lock file to hold this process, got %+v, %v", info, err)
+		}
+
+		if err := lock.Release(); err != nil {
+			t.Fatalf("Release failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(tempDir, vaultLockName)); !os.IsNotExist(err) {
+			t.Error("Expected the lock file to be removed")
+		}
+	})
+
+	t.Run("stale locks are taken over", func(t *testing.T) {
+		tests := map[string]LockInfo{
+			"dead process on this host":      {PID: deadPID(t), Host: currentLockInfo(time.Now()).Host, RefreshedAt: time.Now()},
+			"not refreshed on another host":  {PID: 1, Host: "elsewhere", RefreshedAt: time.Now().Add(-2 * lockStaleAfter)},
+			"unreadable content of the lock": {},
+		}
+
+		for name, owner := range tests {
+			t.Run(name, func(t *testing.T) {
+				tempDir := createTempDir(t)
+				defer removeTempDir(t, tempDir)
+
+				lockPath := filepath.Join(tempDir, vaultLockName)
+				if owner == (LockInfo{}) {
+					if err := os.WriteFile(lockPath, []byte("garbage"), 0644); err != nil {
+						t.Fatalf("Failed to write lock: %v", err)
+					}
+				} else {
+					writeLockFile(t, lockPath, owner)
+				}
+
+				lock, err := AcquireVaultLock(tempDir)
+				if err != nil {
+					t.Fatalf("Expected the stale lock to be taken over, got %v", err)
+				}
+				defer lock.Release()
+			})
+		}
+	})
+
+	t.Run("a lock on this host not refreshed for long is stale even when its pid is in use", func(t *testing.T) {
+		reused := LockInfo{PID: os.Getppid(), Host: currentLockInfo(time.Now()).Host, RefreshedAt: time.Now().Add(-2 * lockStaleAfter)}
+		if !reused.stale(time.Now()) {
+			t.Error("Expected the lock to be stale")
+		}
+	})
+
+	t.Run("only one of the instances finding a stale lock takes it over", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		lockPath := filepath.Join(tempDir, vaultLockName)
+		writeLockFile(t, lockPath, LockInfo{PID: 1, Host: "elsewhere", RefreshedAt: time.Now().Add(-2 * lockStaleAfter)})
+
+		results := make(chan error)
+		for i := range 8 {
+			go func() {
+				results <- acquireLockFile(lockPath, LockInfo{PID: 1000 + i, Host: "racer", RefreshedAt: time.Now()})
+			}()
+		}
+
+		acquired := 0
+		for range 8 {
+			err := <-results
+			switch {
+			case err == nil:
+				acquired++
+			case !errors.Is(err, ErrLocked):
+				t.Errorf("Expected ErrLocked for the others, got %v", err)
+			}
+		}
+
+		if acquired != 1 {
+			t.Errorf("Expected exactly one instance to take the lock over, got %d", acquired)
+		}
+		if _, err := os.Stat(lockPath + ".takeover"); !os.IsNotExist(err) {
+			t.Error("Expected the takeover guard to be removed")
+		}
+	})
+
+	t.Run("a takeover guard left behind is cleared", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		lockPath := filepath.Join(tempDir, vaultLockName)
+		writeLockFile(t, lockPath, LockInfo{PID: 1, Host: "elsewhere", RefreshedAt: time.Now().Add(-2 * lockStaleAfter)})
+		if err := os.WriteFile(lockPath+".takeover", nil, 0644); err != nil {
+			t.Fatalf("Failed to write guard: %v", err)
+		}
+		old := time.Now().Add(-2 * lockStaleAfter)
+		if err := os.Chtimes(lockPath+".takeover", old, old); err != nil {
+			t.Fatalf("Failed to age guard: %v", err)
+		}
+
+		lock, err := AcquireVaultLock(tempDir)
+		if err != nil {
+			t.Fatalf("Expected the stale lock to be taken over, got %v", err)
+		}
+		defer lock.Release()
+	})
+
+	t.Run("a live lock on another host is respected", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		writeLockFile(t, filepath.Join(tempDir, vaultLockName), LockInfo{PID: 1, Host: "elsewhere", RefreshedAt: time.Now()})
+
+		if _, err := AcquireVaultLock(tempDir); !errors.Is(err, ErrLocked) {
+			t.Errorf("Expected ErrLocked, got %v", err)
+		}
+	})
+}
+
+func TestLockingRepository(t *testing.T) {
+	t.Run("a note locked by another instance cannot be changed", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		noteRepository := NewNoteRepository(tempDir)
+		locked, _ := noteRepository.CreateEmptyNote(ctx, "locked")
+		free, _ := noteRepository.CreateEmptyNote(ctx, "free")
+
+		locks := NewNoteLocks(tempDir)
+		defer locks.Close()
+		repository := NewLockingRepository(&noteRepository, locks)
+
+		if err := os.MkdirAll(filepath.Join(tempDir, noteLocksDir), 0755); err != nil {
+			t.Fatalf("Failed to create locks folder: %v", err)
+		}
+		writeLockFile(t, locks.lockPath(locked), LockInfo{PID: 1, Host: "elsewhere", RefreshedAt: time.Now()})
+
+		if _, err := repository.SaveNote(ctx, locked.WithContent("changed")); !errors.Is(err, ErrLocked) {
+			t.Errorf("Expected SaveNote of the locked note to fail with ErrLocked, got %v", err)
+		}
+		if err := repository.LockNote(ctx, locked); !errors.Is(err, ErrLocked) {
+			t.Errorf("Expected LockNote of the locked note to fail with ErrLocked, got %v", err)
+		}
+		if _, err := repository.SaveNote(ctx, free.WithContent("changed")); err != nil {
+			t.Errorf("Expected SaveNote of another note to succeed, got %v", err)
+		}
+	})
+
+	t.Run("notes locked by this instance stay writable and are released", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		noteRepository := NewNoteRepository(tempDir)
+		note, _ := noteRepository.CreateEmptyNote(ctx, "note")
+
+		locks := NewNoteLocks(tempDir)
+		repository := NewLockingRepository(&noteRepository, locks)
+
+		if err := repository.LockNote(ctx, note); err != nil {
+			t.Fatalf("LockNote failed: %v", err)
+		}
+		if _, err := repository.SaveNote(ctx, note.WithContent("changed")); err != nil {
+			t.Errorf("Expected SaveNote of an own locked note to succeed, got %v", err)
+		}
+
+		if err := locks.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		if _, err := os.Stat(locks.lockPath(note)); !os.IsNotExist(err) {
+			t.Error("Expected closing to release the note lock")
+		}
+	})
+}
+
+func writeLockFile(t *testing.T, path string, info LockInfo) {
+	t.Helper()
+
+	content, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("Failed to encode lock: %v", err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("Failed to write lock: %v", err)
+	}
+}
+
+// deadPID - a pid no process on this host is running under
+func deadPID(t *testing.T) int {
+	t.Helper()
+
+	for pid := 999999; pid > 900000; pid-- {
+		if !processAlive(pid) {
+			return pid
+		}
+	}
+
+	t.Skip("no free pid found")
+	return 0
+}
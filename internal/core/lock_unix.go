@@ -0,0 +1,14 @@
+//go:build unix
+
+package core
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive - whether a process with the given pid is running on this host
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
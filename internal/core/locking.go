@@ -0,0 +1,86 @@
+package core
+
+import "context"
+
+// NoteLocker - a repository whose notes can be locked while they are edited
+type NoteLocker interface {
+	LockNote(ctx context.Context, note Note) error
+	UnlockNote(ctx context.Context, note Note) error
+}
+
+// LockingRepository - a repository shared with other instances, refusing changes to notes they have locked
+type LockingRepository struct {
+	RepositoryWrapper
+	locks *NoteLocks
+}
+
+func NewLockingRepository(repository Repository, locks *NoteLocks) *LockingRepository {
+	return &LockingRepository{RepositoryWrapper: RepositoryWrapper{Repository: repository}, locks: locks}
+}
+
+// WithNoteLocks - lock notes in locks while they are edited and refuse changes to the notes other instances locked
+func WithNoteLocks(locks *NoteLocks) Middleware {
+	return func(repository Repository) Repository {
+		return NewLockingRepository(repository, locks)
+	}
+}
+
+func (r *LockingRepository) LockNote(ctx context.Context, note Note) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.locks.LockNote(note)
+}
+
+func (r *LockingRepository) UnlockNote(ctx context.Context, note Note) error {
+	return r.locks.UnlockNote(note)
+}
+
+func (r *LockingRepository) SaveNote(ctx context.Context, note Note) (Note, error) {
+	if err := r.checkNoteWritable(ctx, note); err != nil {
+		return Note{}, err
+	}
+
+	return r.Repository.SaveNote(ctx, note)
+}
+
+func (r *LockingRepository) DeleteNote(ctx context.Context, note Note) error {
+	if err := r.checkNoteWritable(ctx, note); err != nil {
+		return err
+	}
+
+	return r.Repository.DeleteNote(ctx, note)
+}
+
+func (r *LockingRepository) RenameNote(ctx context.Context, note Note, newName string) (Note, error) {
+	if err := r.checkNoteWritable(ctx, note); err != nil {
+		return Note{}, err
+	}
+
+	return r.Repository.RenameNote(ctx, note, newName)
+}
+
+func (r *LockingRepository) MoveNote(ctx context.Context, note Note, folder string) (Note, error) {
+	if err := r.checkNoteWritable(ctx, note); err != nil {
+		return Note{}, err
+	}
+
+	return r.Repository.MoveNote(ctx, note, folder)
+}
+
+func (r *LockingRepository) RestoreRevision(ctx context.Context, note Note, hash string) (Note, error) {
+	if err := r.checkNoteWritable(ctx, note); err != nil {
+		return Note{}, err
+	}
+
+	return r.RepositoryWrapper.RestoreRevision(ctx, note, hash)
+}
+
+func (r *LockingRepository) checkNoteWritable(ctx context.Context, note Note) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.locks.CheckNote(note)
+}
@@ -0,0 +1,232 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+)
+
+// MemoryRepository - a vault kept in memory, for tests and scratch vaults, where the file path of a note is its path
+// relative to the vault; it behaves like the on-disk repositories, except that deleted notes are gone for good
+type MemoryRepository struct {
+	mu    sync.Mutex
+	notes map[string]Note
+	err   error
+}
+
+// NewMemoryRepository - an in-memory vault holding notes, keyed by their file paths
+func NewMemoryRepository(notes ...Note) *MemoryRepository {
+	r := &MemoryRepository{notes: make(map[string]Note, len(notes))}
+	for _, note := range notes {
+		r.notes[note.FilePath()] = NewNote(note.FilePath(), note.FileContent()).stored()
+	}
+
+	return r
+}
+
+// FailWith - make every following call fail with err, or behave normally again when err is nil
+func (r *MemoryRepository) FailWith(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.err = err
+}
+
+// begin - lock the repository for a call, returning the error the call must fail with instead
+func (r *MemoryRepository) begin(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if r.err != nil {
+		r.mu.Unlock()
+		return r.err
+	}
+
+	return nil
+}
+
+func (r *MemoryRepository) GetAllNotes(ctx context.Context) ([]Note, error) {
+	if err := r.begin(ctx); err != nil {
+		return nil, err
+	}
+	defer r.mu.Unlock()
+
+	return r.filterNotes(func(Note) bool { return true }), nil
+}
+
+func (r *MemoryRepository) GetNoteByTitle(ctx context.Context, title string) (Note, error) {
+	if err := r.begin(ctx); err != nil {
+		return Note{}, err
+	}
+	defer r.mu.Unlock()
+
+	notePath := title + ".md"
+	if err := ValidateName(notePath); err != nil {
+		slog.Error("failed to read note by title", "title", title, "error", err)
+		return Note{}, err
+	}
+
+	return r.getNote(notePath)
+}
+
+func (r *MemoryRepository) GetNotesByTag(ctx context.Context, tag string) ([]Note, error) {
+	if err := r.begin(ctx); err != nil {
+		return nil, err
+	}
+	defer r.mu.Unlock()
+
+	return r.filterNotes(func(note Note) bool { return note.HasTag(tag) }), nil
+}
+
+// SaveNote - replace the note content and return the stored note; a *ConflictError is returned when the note
+// changed since it was loaded
+func (r *MemoryRepository) SaveNote(ctx context.Context, note Note) (Note, error) {
+	if err := r.begin(ctx); err != nil {
+		return Note{}, err
+	}
+	defer r.mu.Unlock()
+
+	if err := ValidateName(note.FilePath()); err != nil {
+		slog.Error("failed to save note", "file", note.FilePath(), "error", err)
+		return Note{}, err
+	}
+
+	stored, ok := r.notes[note.FilePath()]
+	if !ok && note.Version() != "" {
+		return Note{}, fmt.Errorf("%w: %s", ErrNotFound, note.FilePath())
+	}
+	if ok && note.Version() != "" && stored.Version() != note.Version() {
+		return Note{}, &ConflictError{Mine: note, Theirs: stored}
+	}
+
+	saved := NewNote(note.FilePath(), note.FileContent()).stored()
+	r.notes[saved.FilePath()] = saved
+
+	return saved, nil
+}
+
+// CreateEmptyNote - create a note named filename, which may contain a folder path such as projects/alpha/kickoff
+func (r *MemoryRepository) CreateEmptyNote(ctx context.Context, filename string) (Note, error) {
+	if err := r.begin(ctx); err != nil {
+		return Note{}, err
+	}
+	defer r.mu.Unlock()
+
+	if path.Ext(filename) != ".md" {
+		filename = filename + ".md"
+	}
+
+	if err := ValidateName(filename); err != nil {
+		slog.Error("failed to create empty note", "filename", filename, "error", err)
+		return Note{}, err
+	}
+
+	if _, ok := r.notes[filename]; ok {
+		return Note{}, fmt.Errorf("%w: %s", ErrAlreadyExists, filename)
+	}
+
+	note := NewNote(filename, "# "+path.Base(filename)).stored()
+	r.notes[filename] = note
+
+	return note, nil
+}
+
+func (r *MemoryRepository) DeleteNote(ctx context.Context, note Note) error {
+	if err := r.begin(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if _, err := r.getNote(note.FilePath()); err != nil {
+		return err
+	}
+
+	delete(r.notes, note.FilePath())
+	return nil
+}
+
+// RenameNote - give the note a new file name, keeping it in the same folder
+func (r *MemoryRepository) RenameNote(ctx context.Context, note Note, newName string) (Note, error) {
+	if err := r.begin(ctx); err != nil {
+		return Note{}, err
+	}
+	defer r.mu.Unlock()
+
+	if strings.Contains(newName, "/") {
+		return Note{}, fmt.Errorf("%w: %q must not contain a folder, move the note instead", ErrInvalidName, newName)
+	}
+
+	if path.Ext(newName) != ".md" {
+		newName = newName + ".md"
+	}
+
+	if err := ValidateName(newName); err != nil {
+		return Note{}, err
+	}
+
+	return r.relocateNote(note, path.Join(path.Dir(note.FilePath()), newName))
+}
+
+// MoveNote - move the note into folder, a path relative to the vault root where empty means the root itself
+func (r *MemoryRepository) MoveNote(ctx context.Context, note Note, folder string) (Note, error) {
+	if err := r.begin(ctx); err != nil {
+		return Note{}, err
+	}
+	defer r.mu.Unlock()
+
+	folder = strings.Trim(folder, "/")
+	if folder != "" {
+		if err := ValidateName(folder); err != nil {
+			return Note{}, err
+		}
+	}
+
+	return r.relocateNote(note, path.Join(folder, path.Base(note.FilePath())))
+}
+
+func (r *MemoryRepository) relocateNote(note Note, newPath string) (Note, error) {
+	stored, err := r.getNote(note.FilePath())
+	if err != nil {
+		return Note{}, err
+	}
+
+	if newPath == note.FilePath() {
+		return stored, nil
+	}
+
+	if _, ok := r.notes[newPath]; ok {
+		return Note{}, fmt.Errorf("%w: %s", ErrAlreadyExists, newPath)
+	}
+
+	relocated := NewNote(newPath, stored.FileContent()).stored()
+	delete(r.notes, stored.FilePath())
+	r.notes[newPath] = relocated
+
+	return relocated, nil
+}
+
+func (r *MemoryRepository) getNote(notePath string) (Note, error) {
+	note, ok := r.notes[notePath]
+	if !ok {
+		return Note{}, fmt.Errorf("%w: %s", ErrNotFound, notePath)
+	}
+
+	return note, nil
+}
+
+func (r *MemoryRepository) filterNotes(keep func(Note) bool) []Note {
+	var notes []Note
+	for _, note := range r.notes {
+		if keep(note) {
+			notes = append(notes, note)
+		}
+	}
+
+	SortNotes(notes)
+	return notes
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	t.Run("starts with the given notes", func(t *testing.T) {
+		repo := NewMemoryRepository(NewNote("projects/alpha.md", "alpha"), NewNote("beta.md", "beta"))
+
+		note, err := repo.GetNoteByTitle(context.Background(), "projects/alpha")
+		if err != nil || note.FileContent() != "alpha" || note.Version() != HashContent("alpha") {
+			t.Errorf("Expected the stored alpha note, got %q at %q, %v", note.FileContent(), note.Version(), err)
+		}
+	})
+
+	t.Run("fails every call while FailWith is set", func(t *testing.T) {
+		repo := NewMemoryRepository(NewNote("note.md", "content"))
+		diskFull := errors.New("disk full")
+
+		repo.FailWith(diskFull)
+		if _, err := repo.GetAllNotes(context.Background()); !errors.Is(err, diskFull) {
+			t.Errorf("Expected the injected error, got %v", err)
+		}
+		if _, err := repo.CreateEmptyNote(context.Background(), "new"); !errors.Is(err, diskFull) {
+			t.Errorf("Expected the injected error, got %v", err)
+		}
+
+		repo.FailWith(nil)
+		notes, err := repo.GetAllNotes(context.Background())
+		if err != nil || len(notes) != 1 {
+			t.Errorf("Expected the vault to be untouched once the error is cleared, got %d notes, %v", len(notes), err)
+		}
+	})
+}
@@ -0,0 +1,324 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Middleware - wraps a repository in another one adding behaviour around its calls, such as caching or logging
+type Middleware func(Repository) Repository
+
+// ChainRepository - wrap repository in middlewares, the first of which ends up outermost and sees every call first;
+// the chain passes calls to the trash, history, note locks, note streaming, search and link graph of the repository
+// through as well
+func ChainRepository(repository Repository, middlewares ...Middleware) RepositoryWrapper {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		repository = middlewares[i](repository)
+	}
+
+	return RepositoryWrapper{Repository: repository}
+}
+
+// RepositoryWrapper - the base of a middleware, passing every call through to the wrapped repository, including the
+// calls to its trash, history, note locks, note streaming, search and link graph; embed it and override the calls to
+// intercept
+type RepositoryWrapper struct {
+	Repository
+}
+
+func (w RepositoryWrapper) StreamNotes(ctx context.Context, batch func([]Note)) error {
+	return StreamNotes(ctx, w.Repository, batch)
+}
+
+func (w RepositoryWrapper) SearchNotes(ctx context.Context, query string) ([]Note, error) {
+	searcher, ok := w.Repository.(Searcher)
+	if !ok {
+		return nil, fmt.Errorf("repository %T cannot search", w.Repository)
+	}
+
+	return searcher.SearchNotes(ctx, query)
+}
+
+func (w RepositoryWrapper) Backlinks(ctx context.Context, note Note) ([]Backlink, error) {
+	backlinker, ok := w.Repository.(Backlinker)
+	if !ok {
+		return nil, fmt.Errorf("repository %T has no link graph", w.Repository)
+	}
+
+	return backlinker.Backlinks(ctx, note)
+}
+
+func (w RepositoryWrapper) ResolveLink(ctx context.Context, from Note, link Link) (Note, error) {
+	backlinker, ok := w.Repository.(Backlinker)
+	if !ok {
+		return Note{}, fmt.Errorf("repository %T has no link graph", w.Repository)
+	}
+
+	return backlinker.ResolveLink(ctx, from, link)
+}
+
+func (w RepositoryWrapper) GetTrashedNotes(ctx context.Context) ([]TrashedNote, error) {
+	trash, err := w.trash()
+	if err != nil {
+		return nil, err
+	}
+
+	return trash.GetTrashedNotes(ctx)
+}
+
+func (w RepositoryWrapper) RestoreNote(ctx context.Context, id string) (Note, error) {
+	trash, err := w.trash()
+	if err != nil {
+		return Note{}, err
+	}
+
+	return trash.RestoreNote(ctx, id)
+}
+
+func (w RepositoryWrapper) PurgeNote(ctx context.Context, id string) error {
+	trash, err := w.trash()
+	if err != nil {
+		return err
+	}
+
+	return trash.PurgeNote(ctx, id)
+}
+
+func (w RepositoryWrapper) EmptyTrash(ctx context.Context, olderThan time.Duration) (int, error) {
+	trash, err := w.trash()
+	if err != nil {
+		return 0, err
+	}
+
+	return trash.EmptyTrash(ctx, olderThan)
+}
+
+func (w RepositoryWrapper) GetRevisions(ctx context.Context, note Note) ([]Revision, error) {
+	history, err := w.history()
+	if err != nil {
+		return nil, err
+	}
+
+	return history.GetRevisions(ctx, note)
+}
+
+func (w RepositoryWrapper) RestoreRevision(ctx context.Context, note Note, hash string) (Note, error) {
+	history, err := w.history()
+	if err != nil {
+		return Note{}, err
+	}
+
+	return history.RestoreRevision(ctx, note, hash)
+}
+
+// LockNote - lock the note in the wrapped repository, where a repository without note locks has nothing to lock
+func (w RepositoryWrapper) LockNote(ctx context.Context, note Note) error {
+	if locker, ok := w.Repository.(NoteLocker); ok {
+		return locker.LockNote(ctx, note)
+	}
+
+	return ctx.Err()
+}
+
+func (w RepositoryWrapper) UnlockNote(ctx context.Context, note Note) error {
+	if locker, ok := w.Repository.(NoteLocker); ok {
+		return locker.UnlockNote(ctx, note)
+	}
+
+	return nil
+}
+
+func (w RepositoryWrapper) trash() (Trash, error) {
+	trash, ok := w.Repository.(Trash)
+	if !ok {
+		return nil, fmt.Errorf("repository %T has no trash", w.Repository)
+	}
+
+	return trash, nil
+}
+
+func (w RepositoryWrapper) history() (History, error) {
+	history, ok := w.Repository.(History)
+	if !ok {
+		return nil, fmt.Errorf("repository %T has no history", w.Repository)
+	}
+
+	return history, nil
+}
+
+// Call - a repository call seen by an Interceptor
+type Call struct {
+	// Op - the name of the method called, such as SaveNote
+	Op string
+	// Target - the note path, title or trash id the call is about, empty for calls about the whole vault
+	Target string
+	// Write - whether the call changes the vault
+	Write bool
+}
+
+// Interceptor - runs around every call to a repository, making the call itself through next
+type Interceptor func(ctx context.Context, call Call, next func(ctx context.Context) error) error
+
+// Intercept - a middleware running interceptor around every call, including those to the trash, history and note locks
+func Intercept(interceptor Interceptor) Middleware {
+	return func(repository Repository) Repository {
+		return &interceptedRepository{RepositoryWrapper: RepositoryWrapper{Repository: repository}, intercept: interceptor}
+	}
+}
+
+type interceptedRepository struct {
+	RepositoryWrapper
+	intercept Interceptor
+}
+
+func (r *interceptedRepository) GetAllNotes(ctx context.Context) (notes []Note, err error) {
+	err = r.intercept(ctx, Call{Op: "GetAllNotes"}, func(ctx context.Context) (err error) {
+		notes, err = r.Repository.GetAllNotes(ctx)
+		return err
+	})
+	return notes, err
+}
+
+func (r *interceptedRepository) StreamNotes(ctx context.Context, batch func([]Note)) error {
+	return r.intercept(ctx, Call{Op: "StreamNotes"}, func(ctx context.Context) error {
+		return r.RepositoryWrapper.StreamNotes(ctx, batch)
+	})
+}
+
+func (r *interceptedRepository) SearchNotes(ctx context.Context, query string) (notes []Note, err error) {
+	err = r.intercept(ctx, Call{Op: "SearchNotes"}, func(ctx context.Context) (err error) {
+		notes, err = r.RepositoryWrapper.SearchNotes(ctx, query)
+		return err
+	})
+	return notes, err
+}
+
+func (r *interceptedRepository) Backlinks(ctx context.Context, note Note) (backlinks []Backlink, err error) {
+	err = r.intercept(ctx, Call{Op: "Backlinks", Target: note.RelativePath()}, func(ctx context.Context) (err error) {
+		backlinks, err = r.RepositoryWrapper.Backlinks(ctx, note)
+		return err
+	})
+	return backlinks, err
+}
+
+func (r *interceptedRepository) ResolveLink(ctx context.Context, from Note, link Link) (note Note, err error) {
+	err = r.intercept(ctx, Call{Op: "ResolveLink", Target: from.RelativePath()}, func(ctx context.Context) (err error) {
+		note, err = r.RepositoryWrapper.ResolveLink(ctx, from, link)
+		return err
+	})
+	return note, err
+}
+
+func (r *interceptedRepository) GetNoteByTitle(ctx context.Context, title string) (note Note, err error) {
+	err = r.intercept(ctx, Call{Op: "GetNoteByTitle", Target: title}, func(ctx context.Context) (err error) {
+		note, err = r.Repository.GetNoteByTitle(ctx, title)
+		return err
+	})
+	return note, err
+}
+
+func (r *interceptedRepository) GetNotesByTag(ctx context.Context, tag string) (notes []Note, err error) {
+	err = r.intercept(ctx, Call{Op: "GetNotesByTag", Target: tag}, func(ctx context.Context) (err error) {
+		notes, err = r.Repository.GetNotesByTag(ctx, tag)
+		return err
+	})
+	return notes, err
+}
+
+func (r *interceptedRepository) SaveNote(ctx context.Context, note Note) (saved Note, err error) {
+	err = r.intercept(ctx, Call{Op: "SaveNote", Target: note.RelativePath(), Write: true}, func(ctx context.Context) (err error) {
+		saved, err = r.Repository.SaveNote(ctx, note)
+		return err
+	})
+	return saved, err
+}
+
+func (r *interceptedRepository) CreateEmptyNote(ctx context.Context, filename string) (note Note, err error) {
+	err = r.intercept(ctx, Call{Op: "CreateEmptyNote", Target: filename, Write: true}, func(ctx context.Context) (err error) {
+		note, err = r.Repository.CreateEmptyNote(ctx, filename)
+		return err
+	})
+	return note, err
+}
+
+func (r *interceptedRepository) DeleteNote(ctx context.Context, note Note) error {
+	return r.intercept(ctx, Call{Op: "DeleteNote", Target: note.RelativePath(), Write: true}, func(ctx context.Context) error {
+		return r.Repository.DeleteNote(ctx, note)
+	})
+}
+
+func (r *interceptedRepository) RenameNote(ctx context.Context, note Note, newName string) (renamed Note, err error) {
+	err = r.intercept(ctx, Call{Op: "RenameNote", Target: note.RelativePath(), Write: true}, func(ctx context.Context) (err error) {
+		renamed, err = r.Repository.RenameNote(ctx, note, newName)
+		return err
+	})
+	return renamed, err
+}
+
+func (r *interceptedRepository) MoveNote(ctx context.Context, note Note, folder string) (moved Note, err error) {
+	err = r.intercept(ctx, Call{Op: "MoveNote", Target: note.RelativePath(), Write: true}, func(ctx context.Context) (err error) {
+		moved, err = r.Repository.MoveNote(ctx, note, folder)
+		return err
+	})
+	return moved, err
+}
+
+func (r *interceptedRepository) GetTrashedNotes(ctx context.Context) (trashed []TrashedNote, err error) {
+	err = r.intercept(ctx, Call{Op: "GetTrashedNotes"}, func(ctx context.Context) (err error) {
+		trashed, err = r.RepositoryWrapper.GetTrashedNotes(ctx)
+		return err
+	})
+	return trashed, err
+}
+
+func (r *interceptedRepository) RestoreNote(ctx context.Context, id string) (restored Note, err error) {
+	err = r.intercept(ctx, Call{Op: "RestoreNote", Target: id, Write: true}, func(ctx context.Context) (err error) {
+		restored, err = r.RepositoryWrapper.RestoreNote(ctx, id)
+		return err
+	})
+	return restored, err
+}
+
+func (r *interceptedRepository) PurgeNote(ctx context.Context, id string) error {
+	return r.intercept(ctx, Call{Op: "PurgeNote", Target: id, Write: true}, func(ctx context.Context) error {
+		return r.RepositoryWrapper.PurgeNote(ctx, id)
+	})
+}
+
+func (r *interceptedRepository) EmptyTrash(ctx context.Context, olderThan time.Duration) (purged int, err error) {
+	err = r.intercept(ctx, Call{Op: "EmptyTrash", Write: true}, func(ctx context.Context) (err error) {
+		purged, err = r.RepositoryWrapper.EmptyTrash(ctx, olderThan)
+		return err
+	})
+	return purged, err
+}
+
+func (r *interceptedRepository) GetRevisions(ctx context.Context, note Note) (revisions []Revision, err error) {
+	err = r.intercept(ctx, Call{Op: "GetRevisions", Target: note.RelativePath()}, func(ctx context.Context) (err error) {
+		revisions, err = r.RepositoryWrapper.GetRevisions(ctx, note)
+		return err
+	})
+	return revisions, err
+}
+
+func (r *interceptedRepository) RestoreRevision(ctx context.Context, note Note, hash string) (restored Note, err error) {
+	err = r.intercept(ctx, Call{Op: "RestoreRevision", Target: note.RelativePath(), Write: true}, func(ctx context.Context) (err error) {
+		restored, err = r.RepositoryWrapper.RestoreRevision(ctx, note, hash)
+		return err
+	})
+	return restored, err
+}
+
+func (r *interceptedRepository) LockNote(ctx context.Context, note Note) error {
+	return r.intercept(ctx, Call{Op: "LockNote", Target: note.RelativePath(), Write: true}, func(ctx context.Context) error {
+		return r.RepositoryWrapper.LockNote(ctx, note)
+	})
+}
+
+// UnlockNote - release the note lock, which is not a write so that a vault turned read-only can still let go of it
+func (r *interceptedRepository) UnlockNote(ctx context.Context, note Note) error {
+	return r.intercept(ctx, Call{Op: "UnlockNote", Target: note.RelativePath()}, func(ctx context.Context) error {
+		return r.RepositoryWrapper.UnlockNote(ctx, note)
+	})
+}
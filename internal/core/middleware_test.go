@@ -0,0 +1,284 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChainRepository(t *testing.T) {
+	t.Run("the first middleware sees every call first", func(t *testing.T) {
+		var order []string
+		record := func(name string) Middleware {
+			return Intercept(func(ctx context.Context, call Call, next func(ctx context.Context) error) error {
+				order = append(order, name)
+				return next(ctx)
+			})
+		}
+
+		repository := ChainRepository(NewMemoryRepository(), record("outer"), record("inner"))
+		if _, err := repository.GetAllNotes(context.Background()); err != nil {
+			t.Fatalf("GetAllNotes failed: %v", err)
+		}
+
+		if strings.Join(order, ",") != "outer,inner" {
+			t.Errorf("Expected outer before inner, got %v", order)
+		}
+	})
+
+	t.Run("the trash and history of the repository are passed through", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		noteRepository := NewNoteRepository(tempDir)
+		repository := ChainRepository(&noteRepository, WithLogging(slog.Default()), WithCache(NewNoteCache()))
+
+		note, _ := repository.CreateEmptyNote(ctx, "note")
+		if _, err := repository.SaveNote(ctx, note.WithContent("changed")); err != nil {
+			t.Fatalf("SaveNote failed: %v", err)
+		}
+
+		revisions, err := repository.GetRevisions(ctx, note)
+		if err != nil || len(revisions) != 2 {
+			t.Errorf("Expected 2 revisions through the chain, got %d, %v", len(revisions), err)
+		}
+
+		if err := repository.DeleteNote(ctx, note); err != nil {
+			t.Fatalf("DeleteNote failed: %v", err)
+		}
+		trashed, err := repository.GetTrashedNotes(ctx)
+		if err != nil || len(trashed) != 1 {
+			t.Errorf("Expected the deleted note in the trash, got %d, %v", len(trashed), err)
+		}
+	})
+
+	t.Run("a repository without trash reports it", func(t *testing.T) {
+		repository := ChainRepository(NewMemoryRepository())
+
+		if _, err := repository.GetTrashedNotes(context.Background()); err == nil || !strings.Contains(err.Error(), "has no trash") {
+			t.Errorf("Expected a missing trash to be reported, got %v", err)
+		}
+		if err := repository.LockNote(context.Background(), NewNote("note.md", "")); err != nil {
+			t.Errorf("Expected a repository without note locks to have nothing to lock, got %v", err)
+		}
+	})
+}
+
+func TestWithReadOnly(t *testing.T) {
+	t.Run("read-only refuses every change", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+
+		noteRepository := NewNoteRepository(tempDir)
+		note, err := noteRepository.CreateEmptyNote(ctx, "note")
+		if err != nil {
+			t.Fatalf("CreateEmptyNote failed: %v", err)
+		}
+
+		locks := NewNoteLocks(tempDir)
+		defer locks.Close()
+		guard := &ReadOnlyGuard{}
+		guard.SetReadOnly(true)
+		repository := ChainRepository(&noteRepository, WithReadOnly(guard), WithNoteLocks(locks))
+
+		if _, err := repository.SaveNote(ctx, note.WithContent("changed")); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Expected SaveNote to fail with ErrReadOnly, got %v", err)
+		}
+		if _, err := repository.CreateEmptyNote(ctx, "other"); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Expected CreateEmptyNote to fail with ErrReadOnly, got %v", err)
+		}
+		if err := repository.DeleteNote(ctx, note); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Expected DeleteNote to fail with ErrReadOnly, got %v", err)
+		}
+		if _, err := repository.EmptyTrash(ctx, 0); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Expected EmptyTrash to fail with ErrReadOnly, got %v", err)
+		}
+		if err := repository.LockNote(ctx, note); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Expected LockNote to fail with ErrReadOnly, got %v", err)
+		}
+		if err := repository.UnlockNote(ctx, note); err != nil {
+			t.Errorf("Expected UnlockNote to keep working, got %v", err)
+		}
+
+		notes, err := repository.GetAllNotes(ctx)
+		if err != nil || len(notes) != 1 {
+			t.Errorf("Expected reads to keep working, got %d notes, %v", len(notes), err)
+		}
+
+		guard.SetReadOnly(false)
+		if _, err := repository.SaveNote(ctx, note.WithContent("changed")); err != nil {
+			t.Errorf("Expected SaveNote to work once writable again, got %v", err)
+		}
+	})
+}
+
+func TestWithCounters(t *testing.T) {
+	t.Run("counts calls and failures by op", func(t *testing.T) {
+		ctx := context.Background()
+		counters := NewOperationCounters()
+		repository := ChainRepository(NewMemoryRepository(), WithCounters(counters))
+
+		_, _ = repository.GetAllNotes(ctx)
+		_, _ = repository.GetAllNotes(ctx)
+		_, _ = repository.GetNoteByTitle(ctx, "missing")
+
+		if count := counters.Count("GetAllNotes"); count.Calls != 2 || count.Failures != 0 {
+			t.Errorf("Expected 2 successful GetAllNotes calls, got %+v", count)
+		}
+		if count := counters.Count("GetNoteByTitle"); count.Calls != 1 || count.Failures != 1 {
+			t.Errorf("Expected 1 failed GetNoteByTitle call, got %+v", count)
+		}
+
+		var logged bytes.Buffer
+		slog.New(slog.NewTextHandler(&logged, nil)).Info("repository calls", "counts", counters)
+		if !strings.Contains(logged.String(), "counts.GetAllNotes.calls=2") {
+			t.Errorf("Expected the counters to be logged by op, got %q", logged.String())
+		}
+	})
+}
+
+func TestWithFaults(t *testing.T) {
+	t.Run("fails the given number of calls", func(t *testing.T) {
+		ctx := context.Background()
+		faults := NewFaultInjector()
+		repository := ChainRepository(NewMemoryRepository(), WithFaults(faults))
+
+		faults.Inject("CreateEmptyNote", Fault{Err: ErrInjectedFault, Times: 1})
+
+		if _, err := repository.CreateEmptyNote(ctx, "note"); !errors.Is(err, ErrInjectedFault) {
+			t.Errorf("Expected the injected fault, got %v", err)
+		}
+		if _, err := repository.CreateEmptyNote(ctx, "note"); err != nil {
+			t.Errorf("Expected the fault to clear after one call, got %v", err)
+		}
+	})
+
+	t.Run("delays give up with the context", func(t *testing.T) {
+		faults := NewFaultInjector()
+		repository := ChainRepository(NewMemoryRepository(), WithFaults(faults))
+		faults.Inject("*", Fault{Delay: time.Minute})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if _, err := repository.GetAllNotes(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the delayed call to time out, got %v", err)
+		}
+
+		faults.Clear()
+		if _, err := repository.GetAllNotes(context.Background()); err != nil {
+			t.Errorf("Expected calls to work once cleared, got %v", err)
+		}
+	})
+}
+
+func TestWithLogging(t *testing.T) {
+	t.Run("logs failed calls with their target", func(t *testing.T) {
+		var logged bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&logged, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		repository := ChainRepository(NewMemoryRepository(), WithLogging(logger))
+
+		_, _ = repository.GetAllNotes(context.Background())
+		_, _ = repository.GetNoteByTitle(context.Background(), "missing")
+
+		lines := strings.Split(strings.TrimSpace(logged.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("Expected one line per call, got %q", logged.String())
+		}
+		if !strings.Contains(lines[0], "level=DEBUG") || !strings.Contains(lines[0], "op=GetAllNotes") || !strings.Contains(lines[0], "duration=") {
+			t.Errorf("Expected a debug line with the op and duration, got %q", lines[0])
+		}
+		if !strings.Contains(lines[1], "level=WARN") || !strings.Contains(lines[1], "target=missing") || !strings.Contains(lines[1], "error=") {
+			t.Errorf("Expected a warning with the target and error, got %q", lines[1])
+		}
+	})
+}
+
+func TestWithCache(t *testing.T) {
+	newCachedRepository := func() (Repository, *MemoryRepository, *OperationCounters, *NoteCache) {
+		storage := NewMemoryRepository(NewNote("alpha.md", "#work"), NewNote("beta.md", "beta"))
+		counters := NewOperationCounters()
+		cache := NewNoteCache()
+
+		return ChainRepository(storage, WithCache(cache), WithCounters(counters)), storage, counters, cache
+	}
+
+	t.Run("serves repeated reads from the cache", func(t *testing.T) {
+		ctx := context.Background()
+		repository, _, counters, _ := newCachedRepository()
+
+		for range 3 {
+			if notes, err := repository.GetAllNotes(ctx); err != nil || len(notes) != 2 {
+				t.Fatalf("Expected 2 notes, got %d, %v", len(notes), err)
+			}
+		}
+		if note, err := repository.GetNoteByTitle(ctx, "alpha"); err != nil || note.FileContent() != "#work" {
+			t.Errorf("Expected alpha from the cache, got %q, %v", note.FileContent(), err)
+		}
+		if tagged, err := repository.GetNotesByTag(ctx, "work"); err != nil || len(tagged) != 1 {
+			t.Errorf("Expected 1 tagged note from the cache, got %d, %v", len(tagged), err)
+		}
+
+		if calls := counters.Count("GetAllNotes").Calls; calls != 1 {
+			t.Errorf("Expected the notes to be loaded once, got %d loads", calls)
+		}
+		if calls := counters.Count("GetNoteByTitle").Calls + counters.Count("GetNotesByTag").Calls; calls != 0 {
+			t.Errorf("Expected no reads to reach the repository, got %d", calls)
+		}
+	})
+
+	t.Run("keeps up with changes made through it", func(t *testing.T) {
+		ctx := context.Background()
+		repository, _, _, _ := newCachedRepository()
+		notes, _ := repository.GetAllNotes(ctx)
+
+		saved, _ := repository.SaveNote(ctx, notes[0].WithContent("saved"))
+		renamed, _ := repository.RenameNote(ctx, saved, "gamma")
+		_ = repository.DeleteNote(ctx, notes[1])
+		_, _ = repository.CreateEmptyNote(ctx, "delta")
+
+		notes, _ = repository.GetAllNotes(ctx)
+		if len(notes) != 2 || notes[0].Title() != "delta" || notes[1].Title() != "gamma" || notes[1].FileContent() != "saved" {
+			t.Errorf("Expected delta and the renamed gamma, got %v", notes)
+		}
+		if _, err := repository.GetNoteByTitle(ctx, "alpha"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected the old name to be gone, got %v", err)
+		}
+		if note, _ := repository.GetNoteByTitle(ctx, "gamma"); note.Version() != renamed.Version() {
+			t.Error("Expected the renamed note to be cached")
+		}
+	})
+
+	t.Run("changes made elsewhere show up once invalidated", func(t *testing.T) {
+		ctx := context.Background()
+		repository, storage, _, cache := newCachedRepository()
+		notes, _ := repository.GetAllNotes(ctx)
+
+		_, _ = storage.SaveNote(ctx, notes[0].WithContent("changed elsewhere"))
+
+		_, err := repository.SaveNote(ctx, notes[0].WithContent("mine"))
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) {
+			t.Fatalf("Expected a conflict, got %v", err)
+		}
+		if note, _ := repository.GetNoteByTitle(ctx, "alpha"); note.FileContent() != "changed elsewhere" {
+			t.Errorf("Expected the conflict to refresh the cached note, got %q", note.FileContent())
+		}
+
+		_, _ = storage.CreateEmptyNote(ctx, "outside")
+		if notes, _ := repository.GetAllNotes(ctx); len(notes) != 2 {
+			t.Errorf("Expected the cached listing before invalidating, got %d notes", len(notes))
+		}
+
+		cache.Invalidate()
+		if notes, _ := repository.GetAllNotes(ctx); len(notes) != 3 {
+			t.Errorf("Expected the new note after invalidating, got %d notes", len(notes))
+		}
+	})
+}
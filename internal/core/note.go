@@ -1,6 +1,9 @@
 package core
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -8,17 +11,89 @@ import (
 type Note struct {
 	title, description    string
 	filePath, fileContent string
+	relativePath          string
+	frontMatter           string
+	metadata              Metadata
+	tags                  []string
+	links                 []Link
+	tasks                 []Task
+	baseContent, version  string
+	// lazy - the note was listed without its content, which LoadNote reads
+	lazy bool
 }
 
 func NewNote(filePath, fileContent string) Note {
+	relativePath := filePath
+	if filepath.IsAbs(filePath) {
+		relativePath = filepath.Base(filePath)
+	}
+
+	frontMatter, metadata, body := extractFrontMatter(filePath, fileContent)
+
+	title := extractTitle(filePath)
+	if value, ok := metadata.String("title"); ok && value != "" {
+		title = value
+	}
+
+	description := extractDescription(body)
+	if value, ok := metadata.String("description"); ok {
+		description = value
+	}
+
+	return Note{
+		title:        title,
+		description:  description,
+		filePath:     filePath,
+		fileContent:  fileContent,
+		relativePath: filepath.ToSlash(relativePath),
+		frontMatter:  frontMatter,
+		metadata:     metadata,
+		tags:         extractTags(metadata, body),
+		links:        extractLinks(body, len(fileContent)-len(body)),
+		tasks:        extractTasks(body, strings.Count(frontMatter, "\n")+1),
+	}
+}
+
+// newVaultNote - a note as it is stored in the vault at basePath, remembering the stored content as its base
+func newVaultNote(basePath, filePath, fileContent string) Note {
+	note := NewNote(filePath, fileContent)
+
+	if relativePath, err := filepath.Rel(basePath, filePath); err == nil {
+		note.relativePath = filepath.ToSlash(relativePath)
+	}
+
+	return note.stored()
+}
+
+// stored - the note as it was just read from or written to storage, remembering its content as the base
+func (n Note) stored() Note {
+	n.baseContent = n.fileContent
+	n.version = HashContent(n.fileContent)
+
+	return n
+}
+
+// summary - the note without its content, as it is listed until it is opened
+func (n Note) summary() Note {
 	return Note{
-		title:       extractTitle(filePath),
-		description: extractDescription(fileContent),
-		filePath:    filePath,
-		fileContent: fileContent,
+		title:        n.title,
+		description:  n.description,
+		filePath:     n.filePath,
+		relativePath: n.relativePath,
+		tags:         n.tags,
+		links:        n.links,
+		tasks:        n.tasks,
+		version:      n.version,
+		lazy:         true,
 	}
 }
 
+// HashContent - the hex encoded SHA-256 of a note content
+func HashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
 func (n Note) Title() string {
 	return n.title
 }
@@ -35,12 +110,118 @@ func (n Note) FileContent() string {
 	return n.fileContent
 }
 
+// Loaded - whether the note holds its content, which the notes listed by a repository may leave out
+func (n Note) Loaded() bool {
+	return !n.lazy
+}
+
+// Version - the hash of the content stored when the note was loaded or saved, empty for notes never stored
+func (n Note) Version() string {
+	return n.version
+}
+
+// BaseContent - the content stored when the note was loaded or saved
+func (n Note) BaseContent() string {
+	return n.baseContent
+}
+
+// WithContent - a copy of the note with new content, still based on the version it was loaded at
+func (n Note) WithContent(content string) Note {
+	updated := NewNote(n.filePath, content)
+	updated.relativePath = n.relativePath
+	updated.baseContent = n.baseContent
+	updated.version = n.version
+
+	return updated
+}
+
+// Rebase - a copy of the note based on the stored version of onto, so saving it overwrites the changes in onto
+func (n Note) Rebase(onto Note) Note {
+	rebased := n
+	rebased.baseContent = onto.baseContent
+	rebased.version = onto.version
+
+	return rebased
+}
+
+// FrontMatter - the raw front matter block of the note, delimiters included, empty when there is none
+func (n Note) FrontMatter() string {
+	return n.frontMatter
+}
+
+// Metadata - the values parsed from the front matter of the note
+func (n Note) Metadata() Metadata {
+	return n.metadata
+}
+
+// Body - the content of the note without its front matter
+func (n Note) Body() string {
+	return strings.TrimPrefix(n.fileContent, n.frontMatter)
+}
+
+// Tags - the lower cased front matter tags and inline #tags of the note, without the leading #
+func (n Note) Tags() []string {
+	return n.tags
+}
+
+// HasTag - whether the note is tagged with tag, ignoring case and a leading #
+func (n Note) HasTag(tag string) bool {
+	tag = normalizeTag(tag)
+	for _, t := range n.tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Links - the links to notes written in the note, in the order they appear
+func (n Note) Links() []Link {
+	return n.links
+}
+
+// Tasks - the tasks of the note, in the order they appear, each holding the note
+func (n Note) Tasks() []Task {
+	tasks := make([]Task, len(n.tasks))
+	for i, task := range n.tasks {
+		task.Note = n
+		tasks[i] = task
+	}
+
+	return tasks
+}
+
+// RelativePath - the slash separated path of the note relative to the vault root
+func (n Note) RelativePath() string {
+	return n.relativePath
+}
+
+// Folder - the slash separated folder of the note relative to the vault root, empty for the root
+func (n Note) Folder() string {
+	folder := path.Dir(n.relativePath)
+	if folder == "." || folder == "/" {
+		return ""
+	}
+
+	return folder
+}
+
 func (n Note) FilterValue() string {
-	if n.description == "" {
-		return n.title
-	} else {
-		return n.title + " " + n.description
+	value := n.title
+	if folder := n.Folder(); folder != "" {
+		value = folder + "/" + n.title
+	}
+
+	if n.description != "" {
+		value += " " + n.description
 	}
+
+	for _, tag := range n.tags {
+		value += " #" + tag
+	}
+
+	return value
 }
 
 func extractTitle(path string) string {
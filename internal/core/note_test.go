@@ -0,0 +1,120 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewNote(t *testing.T) {
+	t.Run("YAML front matter overrides title and description", func(t *testing.T) {
+		content := "---\ntitle: Kickoff Meeting\ndescription: Alpha project kickoff\ntags: [meeting, alpha]\n---\n# Heading\nBody"
+		note := NewNote("kickoff.md", content)
+
+		if note.Title() != "Kickoff Meeting" {
+			t.Errorf("Expected title 'Kickoff Meeting', got '%s'", note.Title())
+		}
+
+		if note.Description() != "Alpha project kickoff" {
+			t.Errorf("Expected description 'Alpha project kickoff', got '%s'", note.Description())
+		}
+
+		if note.Body() != "# Heading\nBody" {
+			t.Errorf("Expected body without front matter, got '%s'", note.Body())
+		}
+
+		if note.FrontMatter()+note.Body() != content {
+			t.Error("Expected front matter and body to round-trip to the file content")
+		}
+
+		if tags := note.Metadata().Strings("tags"); !reflect.DeepEqual(tags, []string{"meeting", "alpha"}) {
+			t.Errorf("Expected tags [meeting alpha], got %v", tags)
+		}
+	})
+
+	t.Run("TOML front matter is parsed", func(t *testing.T) {
+		content := "+++\ntitle = \"Recipe\"\nservings = 4\n+++\n# Soup\n"
+		note := NewNote("soup.md", content)
+
+		if note.Title() != "Recipe" {
+			t.Errorf("Expected title 'Recipe', got '%s'", note.Title())
+		}
+
+		if note.Description() != "Soup" {
+			t.Errorf("Expected description 'Soup', got '%s'", note.Description())
+		}
+
+		if servings, _ := note.Metadata().String("servings"); servings != "4" {
+			t.Errorf("Expected servings '4', got '%s'", servings)
+		}
+	})
+
+	t.Run("nested properties are not scalars", func(t *testing.T) {
+		note := NewNote("nested.md", "---\ntitle:\n  text: Nested\n---\n# Heading")
+
+		if title, ok := note.Metadata().String("title"); ok {
+			t.Errorf("Expected no scalar for a nested property, got '%s'", title)
+		}
+		if note.Title() != "nested" {
+			t.Errorf("Expected title 'nested', got '%s'", note.Title())
+		}
+	})
+
+	t.Run("malformed front matter is left in the body", func(t *testing.T) {
+		content := "---\ntitle: [unclosed\n---\n# Heading"
+		note := NewNote("broken.md", content)
+
+		if note.Title() != "broken" {
+			t.Errorf("Expected title 'broken', got '%s'", note.Title())
+		}
+
+		if note.Body() != content {
+			t.Errorf("Expected body to be the whole content, got '%s'", note.Body())
+		}
+
+		if note.Metadata() != nil {
+			t.Error("Expected no metadata for malformed front matter")
+		}
+	})
+
+	t.Run("unterminated front matter is not parsed", func(t *testing.T) {
+		content := "---\njust a horizontal rule"
+		note := NewNote("rule.md", content)
+
+		if note.FrontMatter() != "" {
+			t.Errorf("Expected no front matter, got '%s'", note.FrontMatter())
+		}
+	})
+}
+
+func TestNoteTags(t *testing.T) {
+	t.Run("front matter and inline tags are merged", func(t *testing.T) {
+		content := "---\ntags: [Meeting, '#alpha']\n---\n# Heading\nNotes for #meeting and #follow-up, issue #42.\n" +
+			"```\n#not-a-tag\n```\nSee `#code` and http://example.com/#anchor\n"
+		note := NewNote("standup.md", content)
+
+		expected := []string{"meeting", "alpha", "follow-up"}
+		if !reflect.DeepEqual(note.Tags(), expected) {
+			t.Errorf("Expected tags %v, got %v", expected, note.Tags())
+		}
+
+		if !note.HasTag("#Alpha") {
+			t.Error("Expected HasTag to ignore case and a leading #")
+		}
+
+		if note.HasTag("not-a-tag") {
+			t.Error("Expected tags in code blocks to be ignored")
+		}
+	})
+
+	t.Run("CollectTags counts notes per tag", func(t *testing.T) {
+		notes := []Note{
+			NewNote("a.md", "#meeting #alpha"),
+			NewNote("b.md", "#meeting"),
+		}
+
+		expected := []Tag{{Name: "alpha", Count: 1}, {Name: "meeting", Count: 2}}
+		if tags := CollectTags(notes); !reflect.DeepEqual(tags, expected) {
+			t.Errorf("Expected tags %v, got %v", expected, tags)
+		}
+	})
+}
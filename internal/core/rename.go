@@ -0,0 +1,293 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LinkEdit - a line of a note rewritten to follow a renamed or moved note
+type LinkEdit struct {
+	// Path - the relative path of the note holding the line
+	Path string
+	// Line - the line number, counted from 1
+	Line          int
+	Before, After string
+}
+
+// RenamePlan - a note about to be renamed or moved together with the notes linking to it, rewritten to link to its
+// new path; see PlanRename, PlanMove and ApplyRename
+type RenamePlan struct {
+	Note Note
+	// Target - the relative path of the note once renamed or moved
+	Target string
+	// Edits - the lines changed, in the note itself first and then in the linking notes in the order of their paths
+	Edits []LinkEdit
+
+	name, folder string
+	move         bool
+	rewrites     []Note
+}
+
+// Notes - the number of notes whose links are rewritten
+func (p RenamePlan) Notes() int {
+	return len(p.rewrites)
+}
+
+// PlanRename - the rename of note to newName, which follows the rules of RenameNote, rewriting the links to it;
+// a repository without a link graph knows of no links to rewrite
+func PlanRename(ctx context.Context, repository Repository, note Note, newName string) (RenamePlan, error) {
+	if strings.Contains(newName, "/") {
+		return RenamePlan{}, fmt.Errorf("%w: %q must not contain a folder, move the note instead", ErrInvalidName, newName)
+	}
+	if path.Ext(newName) != ".md" {
+		newName += ".md"
+	}
+	if err := ValidateName(newName); err != nil {
+		return RenamePlan{}, err
+	}
+
+	plan := RenamePlan{Target: path.Join(note.Folder(), newName), name: newName}
+	return plan, plan.rewriteLinks(ctx, repository, note)
+}
+
+// PlanMove - the move of note into folder, which follows the rules of MoveNote, rewriting the links to it and the
+// relative links it holds
+func PlanMove(ctx context.Context, repository Repository, note Note, folder string) (RenamePlan, error) {
+	folder = strings.Trim(folder, "/")
+	if folder != "" {
+		if err := ValidateName(folder); err != nil {
+			return RenamePlan{}, err
+		}
+	}
+
+	plan := RenamePlan{Target: path.Join(folder, path.Base(note.RelativePath())), folder: folder, move: true}
+	return plan, plan.rewriteLinks(ctx, repository, note)
+}
+
+// rewriteLinks - load note and the notes linking to it, rewriting their links to the target of the plan
+func (p *RenamePlan) rewriteLinks(ctx context.Context, repository Repository, note Note) error {
+	loaded, err := LoadNote(ctx, repository, note)
+	if err != nil {
+		slog.Error("failed to plan rename", "file", note.FilePath(), "error", err)
+		return err
+	}
+	p.Note = loaded
+
+	if loaded.RelativePath() == p.Target {
+		return nil
+	}
+
+	var backlinks []Backlink
+	if backlinker, ok := repository.(Backlinker); ok {
+		backlinks, err = backlinker.Backlinks(ctx, loaded)
+		if err != nil {
+			slog.Error("failed to plan rename", "file", note.FilePath(), "error", err)
+			return err
+		}
+	}
+
+	// the note itself goes first, since its rewritten content is saved before it is renamed
+	p.rewrite(loaded, func(link Link, written string) (string, bool) {
+		if link.Kind == MarkdownLink && link.Target != "" {
+			target := markdownTarget(loaded.RelativePath(), link)
+			if target == loaded.RelativePath() || target+".md" == loaded.RelativePath() {
+				target = p.Target
+			}
+			// a link from the vault root points to the same note wherever the note is moved
+			rooted := strings.HasPrefix(link.Target, "/")
+			return markdownDestination(link, written, path.Dir(p.Target), target), p.move && !rooted || target == p.Target
+		}
+		if link.Kind == WikiLink && link.Target != "" && linksToItself(loaded, link) {
+			return wikiLink(link, p.Target), true
+		}
+		return "", false
+	})
+
+	for _, backlink := range backlinks {
+		source, err := LoadNote(ctx, repository, backlink.Note)
+		if err != nil {
+			slog.Error("failed to plan rename", "file", backlink.Note.FilePath(), "error", err)
+			return err
+		}
+
+		// the links are looked up again in the loaded content, which may have changed since the graph saw it
+		linked := map[Link]bool{}
+		for _, link := range backlink.Links {
+			linked[Link{Kind: link.Kind, Target: link.Target}] = true
+		}
+
+		p.rewrite(source, func(link Link, written string) (string, bool) {
+			if !linked[Link{Kind: link.Kind, Target: link.Target}] {
+				return "", false
+			}
+			if link.Kind == MarkdownLink {
+				return markdownDestination(link, written, source.Folder(), p.Target), true
+			}
+			return wikiLink(link, p.Target), true
+		})
+	}
+
+	return nil
+}
+
+// rewrite - replace the links of note that replacement has new text for, recording the changed lines
+func (p *RenamePlan) rewrite(note Note, replacement func(link Link, written string) (string, bool)) {
+	content := note.FileContent()
+	links := note.Links()
+
+	rewritten := content
+	for i := len(links) - 1; i >= 0; i-- {
+		link := links[i]
+		if text, ok := replacement(link, content[link.Start:link.End]); ok {
+			rewritten = rewritten[:link.Start] + text + rewritten[link.End:]
+		}
+	}
+
+	if rewritten == content {
+		return
+	}
+
+	before := strings.Split(content, "\n")
+	after := strings.Split(rewritten, "\n")
+	for i := range before {
+		if i < len(after) && before[i] != after[i] {
+			p.Edits = append(p.Edits, LinkEdit{Path: note.RelativePath(), Line: i + 1, Before: before[i], After: after[i]})
+		}
+	}
+
+	p.rewrites = append(p.rewrites, note.WithContent(rewritten))
+}
+
+// linksToItself - whether the wiki link in note names the note itself
+func linksToItself(note Note, link Link) bool {
+	wanted := strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(link.Target, "/"), ".md"))
+	name := strings.ToLower(strings.TrimSuffix(note.RelativePath(), ".md"))
+
+	return name == wanted || strings.HasSuffix(name, "/"+wanted)
+}
+
+// wikiLink - the wiki link written again for target, naming it the way it was named before, by its vault path
+// when it had one and by its file name otherwise
+func wikiLink(link Link, target string) string {
+	name := strings.TrimSuffix(target, ".md")
+	if !strings.Contains(strings.TrimPrefix(link.Target, "/"), "/") {
+		name = path.Base(name)
+	}
+	if strings.HasSuffix(link.Target, ".md") {
+		name += ".md"
+	}
+
+	text := "[[" + name
+	if link.Fragment != "" {
+		text += "#" + link.Fragment
+	}
+	if link.Text != "" {
+		text += "|" + link.Text
+	}
+	text += "]]"
+
+	if link.Embed {
+		text = "!" + text
+	}
+
+	return text
+}
+
+// markdownDestination - the markdown link, as written, pointed at target from a note in folder, keeping its text,
+// fragment and title, and keeping it written from the vault root when it was
+func markdownDestination(link Link, written, folder, target string) string {
+	open := strings.Index(written, "](") + 2
+	end := open
+	angled := strings.HasPrefix(written[open:], "<")
+	if angled {
+		end += strings.IndexByte(written[open:], '>') + 1
+	} else {
+		end += strings.IndexFunc(written[open:], func(r rune) bool { return r == ')' || r == ' ' || r == '\t' })
+	}
+
+	relative := "/" + target
+	if !strings.HasPrefix(link.Target, "/") {
+		fromFolder, err := filepath.Rel(filepath.FromSlash(path.Clean("/"+folder)), filepath.FromSlash(relative))
+		if err != nil {
+			fromFolder = target
+		}
+		relative = filepath.ToSlash(fromFolder)
+	}
+	if path.Ext(link.Target) == "" {
+		relative = strings.TrimSuffix(relative, ".md")
+	}
+
+	if angled {
+		relative = "<" + relative
+	} else {
+		segments := strings.Split(relative, "/")
+		for i, segment := range segments {
+			segments[i] = url.PathEscape(segment)
+		}
+		relative = strings.Join(segments, "/")
+	}
+	if link.Fragment != "" {
+		relative += "#" + link.Fragment
+	}
+	if angled {
+		relative += ">"
+	}
+
+	return written[:open] + relative + written[end:]
+}
+
+// ApplyRename - save the rewritten links and rename or move the note, saving the linking notes back as they were
+// when a step fails, so either every change is made or none is
+func ApplyRename(ctx context.Context, repository Repository, plan RenamePlan) (Note, error) {
+	note := plan.Note
+
+	var saved, originals []Note
+	for _, rewrite := range plan.rewrites {
+		stored, err := repository.SaveNote(ctx, rewrite)
+		if err != nil {
+			return Note{}, rollbackRename(ctx, repository, saved, originals, err)
+		}
+		saved = append(saved, stored)
+		originals = append(originals, rewrite)
+
+		if stored.RelativePath() == note.RelativePath() {
+			note = stored
+		}
+	}
+
+	var renamed Note
+	var err error
+	if plan.move {
+		renamed, err = repository.MoveNote(ctx, note, plan.folder)
+	} else {
+		renamed, err = repository.RenameNote(ctx, note, plan.name)
+	}
+	if err != nil {
+		return Note{}, rollbackRename(ctx, repository, saved, originals, err)
+	}
+
+	return renamed, nil
+}
+
+// rollbackRename - save the notes whose links were rewritten back to the content of their originals, even when ctx
+// is done
+func rollbackRename(ctx context.Context, repository Repository, saved, originals []Note, err error) error {
+	ctx = context.WithoutCancel(ctx)
+
+	errs := []error{err}
+	for i := len(saved) - 1; i >= 0; i-- {
+		note := saved[i]
+		if _, restoreErr := repository.SaveNote(ctx, note.WithContent(originals[i].BaseContent())); restoreErr != nil {
+			slog.Error("failed to roll back link rewrite", "file", note.FilePath(), "error", restoreErr)
+			errs = append(errs, fmt.Errorf("rolling back %s: %w", note.RelativePath(), restoreErr))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func renameRepository(t *testing.T, faults *FaultInjector, notes ...Note) Repository {
+	t.Helper()
+
+	graph := NewLinkGraph()
+	repository := ChainRepository(NewMemoryRepository(notes...), WithFaults(faults), WithLinkGraph(graph))
+	if err := graph.Build(context.Background(), repository); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	return repository
+}
+
+func noteContent(t *testing.T, repository Repository, relativePath string) string {
+	t.Helper()
+
+	notes, _ := repository.GetAllNotes(context.Background())
+	for _, note := range notes {
+		if note.RelativePath() == relativePath {
+			loaded, err := LoadNote(context.Background(), repository, note)
+			if err != nil {
+				t.Fatalf("LoadNote failed: %v", err)
+			}
+			return loaded.FileContent()
+		}
+	}
+
+	t.Fatalf("Expected a note at %s", relativePath)
+	return ""
+}
+
+func TestRenameRewritesLinks(t *testing.T) {
+	ctx := context.Background()
+	repository := renameRepository(t, NewFaultInjector(),
+		NewNote("projects/Plan.md", "# Plan\nSee [[#Goals]] and [[Plan#Risks]]."),
+		NewNote("index.md", "Read [[Plan]], ![[projects/Plan#Goals|goals]]\nand [the plan](projects/Plan.md#top \"Plan\")."),
+		NewNote("projects/todo.md", "Next [plan](<Plan>) and [[Other]]."),
+	)
+
+	plan, _ := repository.GetNoteByTitle(ctx, "projects/Plan")
+
+	renamePlan, err := PlanRename(ctx, repository, plan, "Road Map")
+	if err != nil {
+		t.Fatalf("PlanRename failed: %v", err)
+	}
+
+	if renamePlan.Target != "projects/Road Map.md" || renamePlan.Notes() != 3 {
+		t.Fatalf("Expected the three notes rewritten for projects/Road Map.md, got %d for %s", renamePlan.Notes(), renamePlan.Target)
+	}
+
+	expected := []LinkEdit{
+		{Path: "projects/Plan.md", Line: 2, Before: "See [[#Goals]] and [[Plan#Risks]].", After: "See [[#Goals]] and [[Road Map#Risks]]."},
+		{Path: "index.md", Line: 1, Before: "Read [[Plan]], ![[projects/Plan#Goals|goals]]", After: "Read [[Road Map]], ![[projects/Road Map#Goals|goals]]"},
+		{Path: "index.md", Line: 2, Before: "and [the plan](projects/Plan.md#top \"Plan\").", After: "and [the plan](projects/Road%20Map.md#top \"Plan\")."},
+		{Path: "projects/todo.md", Line: 1, Before: "Next [plan](<Plan>) and [[Other]].", After: "Next [plan](<Road Map>) and [[Other]]."},
+	}
+	if len(renamePlan.Edits) != len(expected) {
+		t.Fatalf("Expected %d edits, got %+v", len(expected), renamePlan.Edits)
+	}
+	for i, edit := range renamePlan.Edits {
+		if edit != expected[i] {
+			t.Errorf("Expected edit %d to be %+v, got %+v", i, expected[i], edit)
+		}
+	}
+
+	renamed, err := ApplyRename(ctx, repository, renamePlan)
+	if err != nil {
+		t.Fatalf("ApplyRename failed: %v", err)
+	}
+
+	if renamed.RelativePath() != "projects/Road Map.md" {
+		t.Errorf("Expected the note to be renamed, got %s", renamed.RelativePath())
+	}
+	if content := noteContent(t, repository, "projects/Road Map.md"); content != "# Plan\nSee [[#Goals]] and [[Road Map#Risks]]." {
+		t.Errorf("Expected the renamed note to keep its rewritten links, got %q", content)
+	}
+	if content := noteContent(t, repository, "projects/todo.md"); content != "Next [plan](<Road Map>) and [[Other]]." {
+		t.Errorf("Expected the linking note to be rewritten, got %q", content)
+	}
+}
+
+func TestMoveRewritesRelativeLinks(t *testing.T) {
+	ctx := context.Background()
+	repository := renameRepository(t, NewFaultInjector(),
+		NewNote("Plan.md", "See [todo](todo.md) and [[todo]]."),
+		NewNote("todo.md", "Back to [plan](Plan.md)."),
+	)
+
+	plan, _ := repository.GetNoteByTitle(ctx, "Plan")
+
+	movePlan, err := PlanMove(ctx, repository, plan, "archive/2024")
+	if err != nil {
+		t.Fatalf("PlanMove failed: %v", err)
+	}
+
+	if _, err := ApplyRename(ctx, repository, movePlan); err != nil {
+		t.Fatalf("ApplyRename failed: %v", err)
+	}
+
+	if content := noteContent(t, repository, "archive/2024/Plan.md"); content != "See [todo](../../todo.md) and [[todo]]." {
+		t.Errorf("Expected the relative link of the moved note to follow it, got %q", content)
+	}
+	if content := noteContent(t, repository, "todo.md"); content != "Back to [plan](archive/2024/Plan.md)." {
+		t.Errorf("Expected the link to the moved note to be rewritten, got %q", content)
+	}
+}
+
+func TestMoveKeepsVaultRootLinks(t *testing.T) {
+	ctx := context.Background()
+	repository := renameRepository(t, NewFaultInjector(),
+		NewNote("projects/Plan.md", "See [todo](/todo.md)."),
+		NewNote("todo.md", "Back to [plan](/projects/Plan.md)."),
+		NewNote("projects/other.md", "Also [plan](/projects/Plan#Goals)."),
+	)
+
+	plan, _ := repository.GetNoteByTitle(ctx, "projects/Plan")
+
+	movePlan, err := PlanMove(ctx, repository, plan, "archive")
+	if err != nil {
+		t.Fatalf("PlanMove failed: %v", err)
+	}
+	if movePlan.Notes() != 2 {
+		t.Fatalf("Expected only the two linking notes rewritten, got %+v", movePlan.Edits)
+	}
+
+	if _, err := ApplyRename(ctx, repository, movePlan); err != nil {
+		t.Fatalf("ApplyRename failed: %v", err)
+	}
+
+	if content := noteContent(t, repository, "archive/Plan.md"); content != "See [todo](/todo.md)." {
+		t.Errorf("Expected the link from the vault root to be kept, got %q", content)
+	}
+	if content := noteContent(t, repository, "todo.md"); content != "Back to [plan](/archive/Plan.md)." {
+		t.Errorf("Expected the link to the moved note to stay written from the vault root, got %q", content)
+	}
+	if content := noteContent(t, repository, "projects/other.md"); content != "Also [plan](/archive/Plan#Goals)." {
+		t.Errorf("Expected the link from the folder of the note to be rewritten, got %q", content)
+	}
+}
+
+func TestRenameRollsBack(t *testing.T) {
+	ctx := context.Background()
+	faults := NewFaultInjector()
+	repository := renameRepository(t, faults,
+		NewNote("Plan.md", "# Plan"),
+		NewNote("a.md", "See [[Plan]]."),
+		NewNote("b.md", "Also [[Plan]]."),
+	)
+
+	plan, _ := repository.GetNoteByTitle(ctx, "Plan")
+
+	renamePlan, err := PlanRename(ctx, repository, plan, "Road Map")
+	if err != nil {
+		t.Fatalf("PlanRename failed: %v", err)
+	}
+
+	failure := errors.New("disk full")
+	faults.Inject("RenameNote", Fault{Err: failure, Times: 1})
+
+	if _, err := ApplyRename(ctx, repository, renamePlan); !errors.Is(err, failure) {
+		t.Fatalf("Expected the rename to fail, got %v", err)
+	}
+
+	for path, content := range map[string]string{"Plan.md": "# Plan", "a.md": "See [[Plan]].", "b.md": "Also [[Plan]]."} {
+		if got := noteContent(t, repository, path); got != content {
+			t.Errorf("Expected %s to be rolled back to %q, got %q", path, content, got)
+		}
+	}
+}
+
+func TestRenameWithoutLinks(t *testing.T) {
+	ctx := context.Background()
+	repository := NewMemoryRepository(NewNote("Plan.md", "# Plan"))
+
+	plan, _ := repository.GetNoteByTitle(ctx, "Plan")
+
+	renamePlan, err := PlanRename(ctx, repository, plan, "Road Map.md")
+	if err != nil {
+		t.Fatalf("PlanRename failed: %v", err)
+	}
+	if len(renamePlan.Edits) != 0 {
+		t.Errorf("Expected a repository without a link graph to plan no edits, got %+v", renamePlan.Edits)
+	}
+
+	if _, err := PlanRename(ctx, repository, plan, "projects/Plan"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("Expected a name with a folder to be invalid, got %v", err)
+	}
+}
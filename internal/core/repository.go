@@ -1,87 +1,426 @@
 package core
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
+// Repository - the notes of a vault, where every call gives up with the context error once ctx is done; a write
+// given up on may still complete in the background
 type Repository interface {
-	GetAllNotes() ([]Note, error)
-	GetNoteByTitle(title string) (Note, error)
-	SaveNote(note Note) error
-	CreateEmptyNote(filename string) (Note, error)
+	GetAllNotes(ctx context.Context) ([]Note, error)
+	GetNoteByTitle(ctx context.Context, title string) (Note, error)
+	GetNotesByTag(ctx context.Context, tag string) ([]Note, error)
+	SaveNote(ctx context.Context, note Note) (Note, error)
+	CreateEmptyNote(ctx context.Context, filename string) (Note, error)
+	DeleteNote(ctx context.Context, note Note) error
+	RenameNote(ctx context.Context, note Note, newName string) (Note, error)
+	MoveNote(ctx context.Context, note Note, folder string) (Note, error)
 }
 
 type NoteRepository struct {
-	basePath string
+	basePath  string
+	retention HistoryRetention
+	index     *noteIndex
+	// historyMu - guards the history store, so that saves of different notes do not drop each other's revisions
+	// or collect an object a revision is being recorded for; lockHistory extends it to other instances
+	historyMu *sync.Mutex
+	// historyDisabled - no revisions are recorded, as another history keeps them
+	historyDisabled bool
 }
 
 func NewNoteRepository(basePath string) NoteRepository {
-	return NoteRepository{basePath: basePath}
+	return NoteRepository{
+		basePath:  basePath,
+		retention: DefaultHistoryRetention,
+		index:     newNoteIndex(filepath.Join(basePath, vaultIndexName)),
+		historyMu: &sync.Mutex{},
+	}
+}
+
+// GetAllNotes - the summaries of the notes, whose content LoadNote reads when it is needed
+func (r *NoteRepository) GetAllNotes(ctx context.Context) ([]Note, error) {
+	return awaitContext(ctx, func() ([]Note, error) {
+		var notes []Note
+		if err := r.streamNotes(ctx, func(batch []Note) { notes = append(notes, batch...) }); err != nil {
+			return nil, err
+		}
+
+		SortNotes(notes)
+		return notes, nil
+	})
 }
 
-func (r *NoteRepository) GetAllNotes() ([]Note, error) {
-	pattern := filepath.Join(r.basePath, "*.md")
+func (r *NoteRepository) GetNoteByTitle(ctx context.Context, title string) (Note, error) {
+	return awaitContext(ctx, func() (Note, error) {
+		return r.getNoteByTitle(title)
+	})
+}
 
-	files, err := filepath.Glob(pattern)
+func (r *NoteRepository) getNoteByTitle(title string) (Note, error) {
+	filePath, err := r.notePath(title + ".md")
+	if err != nil {
+		slog.Error("failed to read note by title", "title", title, "error", err)
+		return Note{}, err
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		err = notFoundError(err, filePath)
+		slog.Error("failed to read note by title", "title", title, "file", filePath, "error", err)
+		return Note{}, err
+	}
+
+	return newVaultNote(r.basePath, filePath, string(content)), nil
+}
+
+func (r *NoteRepository) GetNotesByTag(ctx context.Context, tag string) ([]Note, error) {
+	notes, err := r.GetAllNotes(ctx)
 	if err != nil {
-		slog.Error("failed to read markdown files", "error", err)
 		return nil, err
 	}
 
-	var notes []Note
-	for _, filePath := range files {
-		content, err := os.ReadFile(filePath)
-		if err != nil {
-			slog.Warn("failed to read file", "file", filePath, "error", err)
-			continue
+	var tagged []Note
+	for _, note := range notes {
+		if note.HasTag(tag) {
+			tagged = append(tagged, note)
 		}
-
-		fileContent := string(content)
-		notes = append(notes, NewNote(filePath, fileContent))
 	}
 
-	slog.Info("loaded notes", "count", len(notes))
-	return notes, nil
+	return tagged, nil
+}
+
+// SaveNote - atomically replace the note content, keeping its mode and owner and writing through symlinks,
+// record both the replaced and the new content in the note history, and return the stored note;
+// a *ConflictError is returned when the file changed since the note was loaded
+func (r *NoteRepository) SaveNote(ctx context.Context, note Note) (Note, error) {
+	return awaitContext(ctx, func() (Note, error) {
+		return r.saveNote(note)
+	})
 }
 
-func (r *NoteRepository) GetNoteByTitle(title string) (Note, error) {
-	fileName := title + ".md"
-	filePath := filepath.Join(r.basePath, fileName)
+func (r *NoteRepository) saveNote(note Note) (Note, error) {
 
-	content, err := os.ReadFile(filePath)
+	err := r.checkInVault(note.FilePath())
 	if err != nil {
-		slog.Error("failed to read note by title", "title", title, "file", filePath, "error", err)
+		slog.Error("failed to save note", "file", note.FilePath(), "error", err)
 		return Note{}, err
 	}
 
-	return NewNote(filePath, string(content)), nil
-}
+	err = r.checkNotChanged(note)
+	if err != nil {
+		slog.Error("failed to save note", "file", note.FilePath(), "error", err)
+		return Note{}, err
+	}
+
+	if err := r.snapshotNote(note.FilePath()); err != nil {
+		slog.Warn("failed to record note history", "file", note.FilePath(), "error", err)
+	}
 
-func (r *NoteRepository) SaveNote(note Note) error {
-	err := os.WriteFile(note.FilePath(), []byte(note.FileContent()), 0644)
+	err = writeFileAtomic(note.FilePath(), []byte(note.FileContent()), 0644)
 	if err != nil {
 		slog.Error("failed to save note", "file", note.FilePath(), "error", err)
-		return err
+		return Note{}, err
+	}
+
+	if err := r.recordRevision(note.FilePath(), note.FileContent(), time.Now()); err != nil {
+		slog.Warn("failed to record note history", "file", note.FilePath(), "error", err)
+	}
+
+	return newVaultNote(r.basePath, note.FilePath(), note.FileContent()), nil
+}
+
+// checkNotChanged - make sure the stored content still matches the version the note was loaded at, and that it was
+// not deleted since
+func (r *NoteRepository) checkNotChanged(note Note) error {
+	if note.Version() == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(note.FilePath())
+	if err != nil {
+		return notFoundError(err, note.FilePath())
+	}
+
+	if HashContent(string(content)) != note.Version() {
+		return &ConflictError{Mine: note, Theirs: newVaultNote(r.basePath, note.FilePath(), string(content))}
 	}
 
 	return nil
 }
 
-func (r *NoteRepository) CreateEmptyNote(filename string) (Note, error) {
+// CreateEmptyNote - create a note named filename, which may contain a folder path such as projects/alpha/kickoff
+func (r *NoteRepository) CreateEmptyNote(ctx context.Context, filename string) (Note, error) {
+	return awaitContext(ctx, func() (Note, error) {
+		return r.createEmptyNote(filename)
+	})
+}
+
+func (r *NoteRepository) createEmptyNote(filename string) (Note, error) {
+
 	if filepath.Ext(filename) != ".md" {
 		filename = filename + ".md"
 	}
 
-	filePath := filepath.Join(r.basePath, filename)
-	content := "# " + filename
+	filePath, err := r.notePath(filename)
+	if err != nil {
+		slog.Error("failed to create empty note", "filename", filename, "error", err)
+		return Note{}, err
+	}
 
-	err := os.WriteFile(filePath, []byte(content), 0644)
+	content := "# " + filepath.Base(filePath)
+
+	err = os.MkdirAll(filepath.Dir(filePath), 0755)
+	if err != nil {
+		slog.Error("failed to create note folder", "file", filePath, "error", err)
+		return Note{}, err
+	}
+
+	err = writeNewFile(filePath, []byte(content))
 	if err != nil {
 		slog.Error("failed to create empty note", "file", filePath, "error", err)
 		return Note{}, err
 	}
 
-	return NewNote(filePath, content), nil
+	return newVaultNote(r.basePath, filePath, content), nil
+}
+
+// DeleteNote - move the note into the vault trash, from where it can be restored
+func (r *NoteRepository) DeleteNote(ctx context.Context, note Note) error {
+	_, err := awaitContext(ctx, func() (struct{}, error) {
+		return struct{}{}, r.deleteNote(note)
+	})
+	return err
+}
+
+func (r *NoteRepository) deleteNote(note Note) error {
+
+	err := r.checkNoteExists(note)
+	if err != nil {
+		slog.Error("failed to delete note", "file", note.FilePath(), "error", err)
+		return err
+	}
+
+	err = r.moveToTrash(note, time.Now())
+	if err != nil {
+		slog.Error("failed to delete note", "file", note.FilePath(), "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// RenameNote - give the note a new file name, keeping it in the same folder
+func (r *NoteRepository) RenameNote(ctx context.Context, note Note, newName string) (Note, error) {
+	return awaitContext(ctx, func() (Note, error) {
+		return r.renameNote(note, newName)
+	})
+}
+
+func (r *NoteRepository) renameNote(note Note, newName string) (Note, error) {
+
+	if strings.Contains(newName, "/") {
+		return Note{}, fmt.Errorf("%w: %q must not contain a folder, move the note instead", ErrInvalidName, newName)
+	}
+
+	if filepath.Ext(newName) != ".md" {
+		newName = newName + ".md"
+	}
+
+	if err := ValidateName(newName); err != nil {
+		slog.Error("failed to rename note", "file", note.FilePath(), "error", err)
+		return Note{}, err
+	}
+
+	newPath := filepath.Join(filepath.Dir(note.FilePath()), newName)
+	return r.relocateNote(note, newPath)
+}
+
+// MoveNote - move the note into folder, a path relative to the vault root where empty means the root itself
+func (r *NoteRepository) MoveNote(ctx context.Context, note Note, folder string) (Note, error) {
+	return awaitContext(ctx, func() (Note, error) {
+		return r.moveNote(note, folder)
+	})
+}
+
+func (r *NoteRepository) moveNote(note Note, folder string) (Note, error) {
+
+	folder = strings.Trim(folder, "/")
+	if folder != "" {
+		if err := ValidateName(folder); err != nil {
+			slog.Error("failed to move note", "file", note.FilePath(), "error", err)
+			return Note{}, err
+		}
+	}
+
+	newPath := filepath.Join(r.basePath, filepath.FromSlash(folder), filepath.Base(note.FilePath()))
+	return r.relocateNote(note, newPath)
+}
+
+func (r *NoteRepository) relocateNote(note Note, newPath string) (Note, error) {
+	err := r.checkNoteExists(note)
+	if err != nil {
+		slog.Error("failed to relocate note", "file", note.FilePath(), "error", err)
+		return Note{}, err
+	}
+
+	if newPath == note.FilePath() {
+		return note, nil
+	}
+
+	err = r.checkInVault(newPath)
+	if err != nil {
+		slog.Error("failed to relocate note", "file", note.FilePath(), "target", newPath, "error", err)
+		return Note{}, err
+	}
+
+	if _, err := os.Lstat(newPath); err == nil {
+		err = fmt.Errorf("%w: %s", ErrAlreadyExists, newPath)
+		slog.Error("failed to relocate note", "file", note.FilePath(), "target", newPath, "error", err)
+		return Note{}, err
+	}
+
+	err = os.MkdirAll(filepath.Dir(newPath), 0755)
+	if err != nil {
+		slog.Error("failed to create note folder", "file", newPath, "error", err)
+		return Note{}, err
+	}
+
+	err = os.Rename(note.FilePath(), newPath)
+	if err != nil {
+		slog.Error("failed to relocate note", "file", note.FilePath(), "target", newPath, "error", err)
+		return Note{}, err
+	}
+
+	if err := r.moveHistory(note.FilePath(), newPath); err != nil {
+		slog.Warn("failed to move note history", "file", note.FilePath(), "target", newPath, "error", err)
+	}
+
+	content := note.FileContent()
+	if !note.Loaded() {
+		data, err := os.ReadFile(newPath)
+		if err != nil {
+			slog.Error("failed to read relocated note", "file", newPath, "error", err)
+			return Note{}, err
+		}
+		content = string(data)
+	}
+
+	return newVaultNote(r.basePath, newPath, content), nil
+}
+
+// notePath - the path of the note named name, validating the name and keeping the path inside the vault
+func (r *NoteRepository) notePath(name string) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+
+	filePath := filepath.Join(r.basePath, filepath.FromSlash(name))
+	if err := r.checkInVault(filePath); err != nil {
+		return "", err
+	}
+
+	return filePath, nil
+}
+
+// checkInVault - make sure filePath points below the vault root
+func (r *NoteRepository) checkInVault(filePath string) error {
+	relativePath, err := filepath.Rel(r.basePath, filePath)
+	if err != nil || relativePath == "." || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%w: %s", ErrOutsideVault, filePath)
+	}
+
+	return nil
+}
+
+func (r *NoteRepository) checkNoteExists(note Note) error {
+	if err := r.checkInVault(note.FilePath()); err != nil {
+		return err
+	}
+
+	if _, err := os.Lstat(note.FilePath()); err != nil {
+		return notFoundError(err, note.FilePath())
+	}
+
+	return nil
+}
+
+// writeNewFile - write a file that must not exist yet, reporting ErrAlreadyExists otherwise
+func writeNewFile(filePath string, content []byte) error {
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%w: %s", ErrAlreadyExists, filePath)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// notFoundError - translate a missing file into ErrNotFound, leaving other errors untouched
+func notFoundError(err error, filePath string) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, filePath)
+	}
+
+	return err
+}
+
+// walkNotes - call fn for every markdown note below basePath, skipping hidden files and folders
+func walkNotes(ctx context.Context, basePath string, fn func(filePath string, entry fs.DirEntry) error) error {
+	return filepath.WalkDir(basePath, func(filePath string, entry fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if err != nil {
+			slog.Warn("failed to walk path", "path", filePath, "error", err)
+			if entry != nil && entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() {
+			if filePath != basePath && isHidden(entry.Name()) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if isHidden(entry.Name()) || filepath.Ext(entry.Name()) != ".md" {
+			return nil
+		}
+
+		return fn(filePath, entry)
+	})
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// SortNotes - order notes by folder and then by title so notes in the same folder are grouped together
+func SortNotes(notes []Note) {
+	sort.SliceStable(notes, func(i, j int) bool {
+		if notes[i].Folder() != notes[j].Folder() {
+			return notes[i].Folder() < notes[j].Folder()
+		}
+		return notes[i].Title() < notes[j].Title()
+	})
 }
@@ -1,6 +1,8 @@
 package core
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -10,6 +12,7 @@ func TestNoteRepository(t *testing.T) {
 	t.Run("GetAllNotes", func(t *testing.T) {
 		tmpDir := createTempDir(t)
 		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
 
 		err := os.WriteFile(filepath.Join(tmpDir, "note1.md"), []byte("# First Note\nThis is the content"), 0644)
 		if err != nil {
@@ -22,7 +25,7 @@ func TestNoteRepository(t *testing.T) {
 		}
 
 		service := NewNoteRepository(tmpDir)
-		notes, err := service.GetAllNotes()
+		notes, err := service.GetAllNotes(ctx)
 		if err != nil {
 			t.Fatalf("GetAllNotes failed: %v", err)
 		}
@@ -48,9 +51,90 @@ func TestNoteRepository(t *testing.T) {
 		}
 	})
 
+	t.Run("GetAllNotes in subfolders", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		err := os.MkdirAll(filepath.Join(tmpDir, "projects", "alpha"), 0755)
+		if err != nil {
+			t.Fatalf("Failed to create test folder: %v", err)
+		}
+
+		err = os.MkdirAll(filepath.Join(tmpDir, ".hidden"), 0755)
+		if err != nil {
+			t.Fatalf("Failed to create test folder: %v", err)
+		}
+
+		files := map[string]string{
+			"root.md":                   "# Root",
+			"projects/alpha/kickoff.md": "# Kickoff",
+			".hidden/secret.md":         "# Secret",
+			"projects/readme.txt":       "not a note",
+		}
+		for name, content := range files {
+			err := os.WriteFile(filepath.Join(tmpDir, filepath.FromSlash(name)), []byte(content), 0644)
+			if err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+		}
+
+		service := NewNoteRepository(tmpDir)
+		notes, err := service.GetAllNotes(ctx)
+		if err != nil {
+			t.Fatalf("GetAllNotes failed: %v", err)
+		}
+
+		if len(notes) != 2 {
+			t.Fatalf("Expected 2 notes, got %d", len(notes))
+		}
+
+		if notes[0].RelativePath() != "root.md" || notes[0].Folder() != "" {
+			t.Errorf("Expected root note first, got '%s'", notes[0].RelativePath())
+		}
+
+		if notes[1].RelativePath() != "projects/alpha/kickoff.md" {
+			t.Errorf("Expected relative path 'projects/alpha/kickoff.md', got '%s'", notes[1].RelativePath())
+		}
+
+		if notes[1].Folder() != "projects/alpha" {
+			t.Errorf("Expected folder 'projects/alpha', got '%s'", notes[1].Folder())
+		}
+
+		if notes[1].Title() != "kickoff" {
+			t.Errorf("Expected title 'kickoff', got '%s'", notes[1].Title())
+		}
+	})
+
+	t.Run("GetAllNotes stops when the context is cancelled", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := os.WriteFile(filepath.Join(tmpDir, "note1.md"), []byte("# First Note"), 0644)
+		if err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		service := NewNoteRepository(tmpDir)
+		if _, err := service.GetAllNotes(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+
+		if _, err := service.CreateEmptyNote(ctx, "note2"); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(tmpDir, "note2.md")); !os.IsNotExist(err) {
+			t.Error("Expected no note to be created with a cancelled context")
+		}
+	})
+
 	t.Run("GetNoteByTitle", func(t *testing.T) {
 		tmpDir := createTempDir(t)
 		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
 
 		expectedTitle := "test_note"
 		expectedContent := "# Test Note Title\nThis is the test note content"
@@ -62,7 +146,7 @@ func TestNoteRepository(t *testing.T) {
 		}
 
 		service := NewNoteRepository(tmpDir)
-		note, err := service.GetNoteByTitle(expectedTitle)
+		note, err := service.GetNoteByTitle(ctx, expectedTitle)
 		if err != nil {
 			t.Fatalf("GetNoteByTitle failed: %v", err)
 		}
@@ -84,16 +168,43 @@ func TestNoteRepository(t *testing.T) {
 		}
 	})
 
+	t.Run("GetNotesByTag", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		err := os.WriteFile(filepath.Join(tmpDir, "standup.md"), []byte("# Standup\n#meeting notes"), 0644)
+		if err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		err = os.WriteFile(filepath.Join(tmpDir, "groceries.md"), []byte("---\ntags: [shopping]\n---\n# Groceries"), 0644)
+		if err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		service := NewNoteRepository(tmpDir)
+		notes, err := service.GetNotesByTag(ctx, "#meeting")
+		if err != nil {
+			t.Fatalf("GetNotesByTag failed: %v", err)
+		}
+
+		if len(notes) != 1 || notes[0].Title() != "standup" {
+			t.Errorf("Expected only the standup note, got %v", notes)
+		}
+	})
+
 	t.Run("SaveNote", func(t *testing.T) {
 		tmpDir := createTempDir(t)
 		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
 
 		filePath := filepath.Join(tmpDir, "saved_note.md")
 		content := "# Saved Note\nThis is saved content"
 		note := NewNote(filePath, content)
 
 		service := NewNoteRepository(tmpDir)
-		err := service.SaveNote(note)
+		_, err := service.SaveNote(ctx, note)
 		if err != nil {
 			t.Fatalf("SaveNote failed: %v", err)
 		}
@@ -108,13 +219,89 @@ func TestNoteRepository(t *testing.T) {
 		}
 	})
 
+	t.Run("SaveNote keeps front matter byte-for-byte", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		filePath := filepath.Join(tmpDir, "front_matter.md")
+		content := "---\r\ntitle:   Spaced   Out\r\ntags: [a,b]  # comment\r\n---\r\n# Body\r\n"
+		note := NewNote(filePath, content)
+
+		service := NewNoteRepository(tmpDir)
+		_, err := service.SaveNote(ctx, note)
+		if err != nil {
+			t.Fatalf("SaveNote failed: %v", err)
+		}
+
+		loaded, err := service.GetNoteByTitle(ctx, "front_matter")
+		if err != nil {
+			t.Fatalf("GetNoteByTitle failed: %v", err)
+		}
+
+		if loaded.FileContent() != content {
+			t.Errorf("Expected saved content '%q', got '%q'", content, loaded.FileContent())
+		}
+
+		if loaded.FrontMatter() == "" {
+			t.Error("Expected front matter to be parsed after reloading")
+		}
+	})
+
+	t.Run("SaveNote detects changes made on disk since loading", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		service := NewNoteRepository(tmpDir)
+		note, err := service.CreateEmptyNote(ctx, "shared")
+		if err != nil {
+			t.Fatalf("CreateEmptyNote failed: %v", err)
+		}
+
+		err = os.WriteFile(note.FilePath(), []byte("# Changed elsewhere"), 0644)
+		if err != nil {
+			t.Fatalf("Failed to change test file: %v", err)
+		}
+
+		_, err = service.SaveNote(ctx, note.WithContent("# Mine"))
+
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) || !errors.Is(err, ErrConflict) {
+			t.Fatalf("Expected a ConflictError, got %v", err)
+		}
+
+		if conflict.Theirs.FileContent() != "# Changed elsewhere" {
+			t.Errorf("Expected theirs to be the content on disk, got '%s'", conflict.Theirs.FileContent())
+		}
+
+		content, _ := os.ReadFile(note.FilePath())
+		if string(content) != "# Changed elsewhere" {
+			t.Error("Expected the changes on disk to be kept")
+		}
+
+		saved, err := service.SaveNote(ctx, conflict.Mine.Rebase(conflict.Theirs))
+		if err != nil {
+			t.Fatalf("SaveNote after rebasing failed: %v", err)
+		}
+
+		if saved.Version() != HashContent("# Mine") {
+			t.Error("Expected the saved note to be based on the saved content")
+		}
+
+		if _, err := service.SaveNote(ctx, saved.WithContent("# Mine again")); err != nil {
+			t.Errorf("Expected saving the saved note again to succeed, got %v", err)
+		}
+	})
+
 	t.Run("CreateEmptyNote", func(t *testing.T) {
 		tmpDir := createTempDir(t)
 		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
 
 		filename := "new_note"
 		service := NewNoteRepository(tmpDir)
-		note, err := service.CreateEmptyNote(filename)
+		note, err := service.CreateEmptyNote(ctx, filename)
 		if err != nil {
 			t.Fatalf("CreateEmptyNote failed: %v", err)
 		}
@@ -146,13 +333,43 @@ func TestNoteRepository(t *testing.T) {
 		}
 	})
 
+	t.Run("CreateEmptyNote in folder", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		service := NewNoteRepository(tmpDir)
+		note, err := service.CreateEmptyNote(ctx, "projects/alpha/kickoff")
+		if err != nil {
+			t.Fatalf("CreateEmptyNote in folder failed: %v", err)
+		}
+
+		expectedPath := filepath.Join(tmpDir, "projects", "alpha", "kickoff.md")
+		if note.FilePath() != expectedPath {
+			t.Errorf("Expected file path '%s', got '%s'", expectedPath, note.FilePath())
+		}
+
+		if note.RelativePath() != "projects/alpha/kickoff.md" {
+			t.Errorf("Expected relative path 'projects/alpha/kickoff.md', got '%s'", note.RelativePath())
+		}
+
+		if note.FileContent() != "# kickoff.md" {
+			t.Errorf("Expected content '# kickoff.md', got '%s'", note.FileContent())
+		}
+
+		if _, err := os.Stat(expectedPath); os.IsNotExist(err) {
+			t.Error("Expected file to be created, but it doesn't exist")
+		}
+	})
+
 	t.Run("CreateEmptyNote with extension", func(t *testing.T) {
 		tmpDir := createTempDir(t)
 		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
 
 		filename := "note_with_ext.md"
 		service := NewNoteRepository(tmpDir)
-		note, err := service.CreateEmptyNote(filename)
+		note, err := service.CreateEmptyNote(ctx, filename)
 		if err != nil {
 			t.Fatalf("CreateEmptyNote with extension failed: %v", err)
 		}
@@ -170,6 +387,181 @@ func TestNoteRepository(t *testing.T) {
 			t.Errorf("Expected content '# note_with_ext.md', got '%s'", note.FileContent())
 		}
 	})
+
+	t.Run("DeleteNote", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		service := NewNoteRepository(tmpDir)
+		note, err := service.CreateEmptyNote(ctx, "doomed")
+		if err != nil {
+			t.Fatalf("CreateEmptyNote failed: %v", err)
+		}
+
+		err = service.DeleteNote(ctx, note)
+		if err != nil {
+			t.Fatalf("DeleteNote failed: %v", err)
+		}
+
+		if _, err := os.Stat(note.FilePath()); !os.IsNotExist(err) {
+			t.Error("Expected file to be deleted, but it still exists")
+		}
+	})
+
+	t.Run("RenameNote", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		service := NewNoteRepository(tmpDir)
+		note, err := service.CreateEmptyNote(ctx, "projects/draft")
+		if err != nil {
+			t.Fatalf("CreateEmptyNote failed: %v", err)
+		}
+
+		renamed, err := service.RenameNote(ctx, note, "final")
+		if err != nil {
+			t.Fatalf("RenameNote failed: %v", err)
+		}
+
+		if renamed.RelativePath() != "projects/final.md" {
+			t.Errorf("Expected relative path 'projects/final.md', got '%s'", renamed.RelativePath())
+		}
+
+		if renamed.FileContent() != note.FileContent() {
+			t.Error("Expected content to be kept after renaming")
+		}
+
+		if _, err := os.Stat(note.FilePath()); !os.IsNotExist(err) {
+			t.Error("Expected old file to be gone after renaming")
+		}
+	})
+
+	t.Run("RenameNote does not overwrite an existing note", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		service := NewNoteRepository(tmpDir)
+		note, err := service.CreateEmptyNote(ctx, "first")
+		if err != nil {
+			t.Fatalf("CreateEmptyNote failed: %v", err)
+		}
+
+		if _, err := service.CreateEmptyNote(ctx, "second"); err != nil {
+			t.Fatalf("CreateEmptyNote failed: %v", err)
+		}
+
+		if _, err := service.RenameNote(ctx, note, "second"); err == nil {
+			t.Error("Expected RenameNote to fail when the target exists")
+		}
+
+		content, err := os.ReadFile(filepath.Join(tmpDir, "second.md"))
+		if err != nil || string(content) != "# second.md" {
+			t.Error("Expected existing note to be left untouched")
+		}
+	})
+
+	t.Run("MoveNote", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		service := NewNoteRepository(tmpDir)
+		note, err := service.CreateEmptyNote(ctx, "kickoff")
+		if err != nil {
+			t.Fatalf("CreateEmptyNote failed: %v", err)
+		}
+
+		moved, err := service.MoveNote(ctx, note, "projects/alpha")
+		if err != nil {
+			t.Fatalf("MoveNote failed: %v", err)
+		}
+
+		if moved.RelativePath() != "projects/alpha/kickoff.md" {
+			t.Errorf("Expected relative path 'projects/alpha/kickoff.md', got '%s'", moved.RelativePath())
+		}
+
+		if _, err := os.Stat(moved.FilePath()); err != nil {
+			t.Errorf("Expected moved file to exist: %v", err)
+		}
+	})
+
+	t.Run("CreateEmptyNote does not overwrite an existing note", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		err := os.WriteFile(filepath.Join(tmpDir, "existing.md"), []byte("precious"), 0644)
+		if err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		service := NewNoteRepository(tmpDir)
+		if _, err := service.CreateEmptyNote(ctx, "existing"); !errors.Is(err, ErrAlreadyExists) {
+			t.Errorf("Expected ErrAlreadyExists, got %v", err)
+		}
+
+		content, _ := os.ReadFile(filepath.Join(tmpDir, "existing.md"))
+		if string(content) != "precious" {
+			t.Error("Expected existing note to be left untouched")
+		}
+	})
+
+	t.Run("GetNoteByTitle rejects path traversal", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		service := NewNoteRepository(tmpDir)
+		if _, err := service.GetNoteByTitle(ctx, "../../etc/passwd"); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("Expected ErrInvalidName, got %v", err)
+		}
+
+		if _, err := service.GetNoteByTitle(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("SaveNote rejects notes outside the vault", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		service := NewNoteRepository(filepath.Join(tmpDir, "vault"))
+		note := NewNote(filepath.Join(tmpDir, "outside.md"), "# Outside")
+
+		if _, err := service.SaveNote(ctx, note); !errors.Is(err, ErrOutsideVault) {
+			t.Errorf("Expected ErrOutsideVault, got %v", err)
+		}
+
+		if _, err := os.Stat(note.FilePath()); !os.IsNotExist(err) {
+			t.Error("Expected no file to be written outside the vault")
+		}
+	})
+
+	t.Run("RenameNote and MoveNote validate the target", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		service := NewNoteRepository(tmpDir)
+		note, _ := service.CreateEmptyNote(ctx, "note")
+
+		if _, err := service.RenameNote(ctx, note, "../escaped"); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("Expected ErrInvalidName for rename, got %v", err)
+		}
+
+		if _, err := service.MoveNote(ctx, note, "../outside"); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("Expected ErrInvalidName for move, got %v", err)
+		}
+
+		missing := NewNote(filepath.Join(tmpDir, "missing.md"), "")
+		if err := service.DeleteNote(ctx, missing); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for delete, got %v", err)
+		}
+	})
 }
 
 func createTempDir(t *testing.T) string {
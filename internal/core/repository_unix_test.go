@@ -0,0 +1,55 @@
+//go:build unix
+
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestNoteRepositoryStalledWrites(t *testing.T) {
+	t.Run("a save stuck on the filesystem gives up with the context", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		// reading a fifo blocks until something writes to it, as a hanging network filesystem would
+		notePath := filepath.Join(tempDir, "stalled.md")
+		if err := syscall.Mkfifo(notePath, 0644); err != nil {
+			t.Skipf("cannot create a fifo: %v", err)
+		}
+
+		repo := NewNoteRepository(tempDir)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		started := time.Now()
+		if _, err := repo.SaveNote(ctx, NewNote(notePath, "content")); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the stalled save to time out, got %v", err)
+		}
+		if elapsed := time.Since(started); elapsed > time.Second {
+			t.Errorf("Expected the save to give up with the context, took %v", elapsed)
+		}
+
+		// let the abandoned save finish before the vault is removed
+		writer, err := os.OpenFile(notePath, os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatalf("Failed to open fifo: %v", err)
+		}
+		writer.Close()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			revisions, _ := repo.GetRevisions(context.Background(), NewNote(notePath, ""))
+			if len(revisions) > 0 && revisions[0].Content == "content" {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Error("Expected the abandoned save to complete once the filesystem responds")
+	})
+}
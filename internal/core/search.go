@@ -0,0 +1,464 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidQuery - the search query cannot be parsed or would match every note
+var ErrInvalidQuery = errors.New("invalid search query")
+
+// Searcher - a repository that can search the content of its notes, best matches first, for queries as read by
+// ParseQuery
+type Searcher interface {
+	SearchNotes(ctx context.Context, query string) ([]Note, error)
+}
+
+// queryOp - what a node of a parsed query matches
+type queryOp int
+
+const (
+	// matchWords - the words in a row, the last one as a prefix when the node is a prefix
+	matchWords queryOp = iota
+	// matchAll - every child, where negated children must not match
+	matchAll
+	// matchAny - at least one child
+	matchAny
+	// matchNot - the child must not match, only ever found in matchAll
+	matchNot
+)
+
+type queryNode struct {
+	op       queryOp
+	words    []string
+	prefix   bool
+	children []*queryNode
+}
+
+// Query - a parsed search query; words separated by spaces must all match, AND is optional, OR matches either
+// side, NOT or a leading - excludes notes, "quoted words" match as a phrase, a trailing * matches a prefix and
+// parentheses group
+type Query struct {
+	root *queryNode
+}
+
+// Match - the byte offsets of a part of a text matched by a query
+type Match struct {
+	Start, End int
+}
+
+// ParseQuery - the query written as text, empty when text holds no word to look for
+func ParseQuery(text string) (Query, error) {
+	parser := &queryParser{tokens: lexQuery(text)}
+
+	root, err := parser.parseAny()
+	if err != nil {
+		return Query{}, err
+	}
+	if parser.pos < len(parser.tokens) {
+		return Query{}, fmt.Errorf("%w: unexpected %q", ErrInvalidQuery, parser.tokens[parser.pos].text)
+	}
+
+	if root != nil {
+		if root.op == matchNot {
+			return Query{}, fmt.Errorf("%w: a query cannot only exclude notes", ErrInvalidQuery)
+		}
+		if err := validateQuery(root); err != nil {
+			return Query{}, err
+		}
+	}
+
+	return Query{root: root}, nil
+}
+
+// Empty - whether the query looks for nothing, matching no note
+func (q Query) Empty() bool {
+	return q.root == nil
+}
+
+// Matches - the parts of content matching the words the query looks for, in order and without overlaps
+func (q Query) Matches(content string) []Match {
+	if q.root == nil {
+		return nil
+	}
+
+	tokens := tokenize(content)
+	terms := make([]string, len(tokens))
+	for i, token := range tokens {
+		terms[i] = token.term
+	}
+
+	var matches []Match
+	for _, node := range q.root.wordNodes() {
+		for start := range tokens {
+			if node.matchesAt(terms, start) {
+				matches = append(matches, Match{Start: tokens[start].start, End: tokens[start+len(node.words)-1].end})
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+
+	var merged []Match
+	for _, match := range matches {
+		if last := len(merged) - 1; last >= 0 && match.Start <= merged[last].End {
+			merged[last].End = max(merged[last].End, match.End)
+			continue
+		}
+		merged = append(merged, match)
+	}
+
+	return merged
+}
+
+// fts5 - the query as an SQLite FTS5 expression
+func (q Query) fts5() string {
+	if q.root == nil {
+		return ""
+	}
+
+	return q.root.fts5()
+}
+
+func (n *queryNode) fts5() string {
+	switch n.op {
+	case matchWords:
+		expression := `"` + strings.Join(n.words, " ") + `"`
+		if n.prefix {
+			expression += "*"
+		}
+		return expression
+	case matchAny:
+		var parts []string
+		for _, child := range n.children {
+			parts = append(parts, child.fts5())
+		}
+		return "(" + strings.Join(parts, " OR ") + ")"
+	default:
+		var parts, negated []string
+		for _, child := range n.children {
+			if child.op == matchNot {
+				negated = append(negated, child.children[0].fts5())
+			} else {
+				parts = append(parts, child.fts5())
+			}
+		}
+
+		expression := "(" + strings.Join(parts, " AND ") + ")"
+		for _, part := range negated {
+			expression = "(" + expression + " NOT " + part + ")"
+		}
+		return expression
+	}
+}
+
+// wordNodes - the nodes looking for words, leaving out those of negated parts
+func (n *queryNode) wordNodes() []*queryNode {
+	switch n.op {
+	case matchWords:
+		return []*queryNode{n}
+	case matchNot:
+		return nil
+	}
+
+	var nodes []*queryNode
+	for _, child := range n.children {
+		nodes = append(nodes, child.wordNodes()...)
+	}
+
+	return nodes
+}
+
+// matchesAt - whether the words of the node start at terms[start]
+func (n *queryNode) matchesAt(terms []string, start int) bool {
+	if start+len(n.words) > len(terms) {
+		return false
+	}
+
+	for i := range n.words {
+		if !n.matchesWord(i, terms[start+i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesWord - whether term matches the i-th word of the node
+func (n *queryNode) matchesWord(i int, term string) bool {
+	if n.prefix && i == len(n.words)-1 {
+		return strings.HasPrefix(term, n.words[i])
+	}
+
+	return term == n.words[i]
+}
+
+// positive - whether the node only matches notes containing some word, rather than every note lacking some
+func (n *queryNode) positive() bool {
+	switch n.op {
+	case matchNot:
+		return false
+	case matchAll:
+		for _, child := range n.children {
+			if child.positive() {
+				return true
+			}
+		}
+		return false
+	case matchAny:
+		for _, child := range n.children {
+			if !child.positive() {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// validateQuery - reject queries with parts that would match every note lacking some words
+func validateQuery(node *queryNode) error {
+	if node.op == matchNot {
+		if node.children[0].op == matchNot {
+			return fmt.Errorf("%w: NOT cannot be negated again", ErrInvalidQuery)
+		}
+		return validateQuery(node.children[0])
+	}
+
+	if !node.positive() {
+		return fmt.Errorf("%w: every part needs a word to look for besides the excluded ones", ErrInvalidQuery)
+	}
+
+	for _, child := range node.children {
+		if err := validateQuery(child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type queryTokenKind int
+
+const (
+	wordToken queryTokenKind = iota
+	phraseToken
+	openToken
+	closeToken
+	andToken
+	orToken
+	notToken
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+}
+
+// lexQuery - split a query into its words, phrases, operators and parentheses; an unterminated phrase runs to the
+// end of the query
+func lexQuery(text string) []queryToken {
+	var tokens []queryToken
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: openToken, text: "("})
+			i += size
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: closeToken, text: ")"})
+			i += size
+		case r == '"':
+			end := strings.IndexByte(text[i+1:], '"')
+			if end < 0 {
+				tokens = append(tokens, queryToken{kind: phraseToken, text: text[i+1:]})
+				i = len(text)
+				break
+			}
+			tokens = append(tokens, queryToken{kind: phraseToken, text: text[i+1 : i+1+end]})
+			i += end + 2
+		case r == '-' && i+size < len(text) && !unicode.IsSpace(rune(text[i+size])):
+			tokens = append(tokens, queryToken{kind: notToken, text: "-"})
+			i += size
+		default:
+			end := strings.IndexFunc(text[i:], func(r rune) bool {
+				return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+			})
+			if end < 0 {
+				end = len(text) - i
+			}
+
+			word := text[i : i+end]
+			i += end
+
+			switch word {
+			case "AND":
+				tokens = append(tokens, queryToken{kind: andToken, text: word})
+			case "OR":
+				tokens = append(tokens, queryToken{kind: orToken, text: word})
+			case "NOT":
+				tokens = append(tokens, queryToken{kind: notToken, text: word})
+			default:
+				tokens = append(tokens, queryToken{kind: wordToken, text: word})
+			}
+		}
+	}
+
+	return tokens
+}
+
+// queryParser - reads any := all { OR all }, all := unary { [AND] unary }, unary := NOT unary | ( any ) | word |
+// phrase, where parts holding no word are left out
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return queryToken{}, false
+	}
+
+	return p.tokens[p.pos], true
+}
+
+func (p *queryParser) parseAny() (*queryNode, error) {
+	var children []*queryNode
+
+	for {
+		child, err := p.parseAll()
+		if err != nil {
+			return nil, err
+		}
+		if child != nil {
+			children = append(children, child)
+		}
+
+		if token, ok := p.peek(); !ok || token.kind != orToken {
+			break
+		}
+		p.pos++
+	}
+
+	return combine(matchAny, children), nil
+}
+
+func (p *queryParser) parseAll() (*queryNode, error) {
+	var children []*queryNode
+
+	for {
+		token, ok := p.peek()
+		if !ok || token.kind == closeToken || token.kind == orToken {
+			break
+		}
+		if token.kind == andToken {
+			p.pos++
+			continue
+		}
+
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if child != nil {
+			children = append(children, child)
+		}
+	}
+
+	return combine(matchAll, children), nil
+}
+
+func (p *queryParser) parseUnary() (*queryNode, error) {
+	token, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("%w: the query ends after an operator", ErrInvalidQuery)
+	}
+	p.pos++
+
+	switch token.kind {
+	case notToken:
+		child, err := p.parseUnary()
+		if err != nil || child == nil {
+			return nil, err
+		}
+		return &queryNode{op: matchNot, children: []*queryNode{child}}, nil
+	case openToken:
+		node, err := p.parseAny()
+		if err != nil {
+			return nil, err
+		}
+		// a missing closing parenthesis at the end is forgiven, the query is often still being typed
+		if closing, ok := p.peek(); ok && closing.kind == closeToken {
+			p.pos++
+		}
+		return node, nil
+	case closeToken, orToken, andToken:
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidQuery, token.text)
+	}
+
+	var words []string
+	for _, t := range tokenize(token.text) {
+		words = append(words, t.term)
+	}
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	prefix := token.kind == wordToken && strings.HasSuffix(token.text, "*")
+	return &queryNode{op: matchWords, words: words, prefix: prefix}, nil
+}
+
+// combine - the children joined by op, a single child standing for itself and no children for nothing
+func combine(op queryOp, children []*queryNode) *queryNode {
+	switch len(children) {
+	case 0:
+		return nil
+	case 1:
+		return children[0]
+	}
+
+	return &queryNode{op: op, children: children}
+}
+
+// searchToken - a lower cased word of a text and its byte offsets
+type searchToken struct {
+	term       string
+	start, end int
+}
+
+// tokenize - the words of text, made of letters and digits
+func tokenize(text string) []searchToken {
+	var tokens []searchToken
+
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+
+		if start >= 0 {
+			tokens = append(tokens, searchToken{term: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, searchToken{term: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+
+	return tokens
+}
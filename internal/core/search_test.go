@@ -0,0 +1,70 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	t.Run("queries are translated for SQLite", func(t *testing.T) {
+		for query, expected := range map[string]string{
+			"elephant":                      `"elephant"`,
+			"Elephant memory":               `("elephant" AND "memory")`,
+			"ele*":                          `"ele"*`,
+			`"large grey" elephants`:        `("large grey" AND "elephants")`,
+			"cats OR dogs":                  `("cats" OR "dogs")`,
+			"pets AND (cats OR dogs) -fish": `(("pets" AND ("cats" OR "dogs")) NOT "fish")`,
+			"to-do":                         `"to do"`,
+			"notes NOT drafts":              `(("notes") NOT "drafts")`,
+			"(unclosed group":               `("unclosed" AND "group")`,
+		} {
+			parsed, err := ParseQuery(query)
+			if err != nil {
+				t.Errorf("ParseQuery(%q) failed: %v", query, err)
+				continue
+			}
+			if got := parsed.fts5(); got != expected {
+				t.Errorf("ParseQuery(%q) = %s, expected %s", query, got, expected)
+			}
+		}
+	})
+
+	t.Run("queries without words are empty", func(t *testing.T) {
+		for _, query := range []string{"", "   ", `""`, "* ()"} {
+			parsed, err := ParseQuery(query)
+			if err != nil || !parsed.Empty() {
+				t.Errorf("Expected %q to be empty, got %v", query, err)
+			}
+		}
+	})
+
+	t.Run("queries matching every note lacking some words are rejected", func(t *testing.T) {
+		for _, query := range []string{"-drafts", "NOT drafts", "cats OR -dogs", "cats NOT NOT dogs", "cats )", "cats NOT"} {
+			if _, err := ParseQuery(query); !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("Expected ErrInvalidQuery for %q, got %v", query, err)
+			}
+		}
+	})
+}
+
+func TestQueryMatches(t *testing.T) {
+	content := "Large grey Elephants remember.\nA large elephant never forgets, unlike mice."
+
+	parsed, _ := ParseQuery(`"large grey" elephant* -mice OR forget`)
+	matches := parsed.Matches(content)
+
+	var found []string
+	for _, match := range matches {
+		found = append(found, content[match.Start:match.End])
+	}
+
+	expected := []string{"Large grey", "Elephants", "elephant"}
+	if len(found) != len(expected) {
+		t.Fatalf("Expected matches %v, got %v", expected, found)
+	}
+	for i := range expected {
+		if found[i] != expected[i] {
+			t.Errorf("Expected match %d to be %q, got %q", i, expected[i], found[i])
+		}
+	}
+}
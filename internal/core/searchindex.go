@@ -0,0 +1,347 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// bm25K1 - how quickly more occurrences of a word stop raising the rank of a note
+	bm25K1 = 1.2
+	// bm25B - how much a long note is ranked down for holding more words
+	bm25B = 0.75
+)
+
+// SearchIndex - an inverted index of the words in the titles and contents of the notes of a vault, ranking the
+// notes matching a query with BM25; Build fills it and Follow keeps it up to date with the changes to the vault
+type SearchIndex struct {
+	mu sync.RWMutex
+	// docs - the indexed notes by their relative path
+	docs map[string]*searchDoc
+	// postings - the relative paths of the notes holding a word
+	postings map[string]map[string]struct{}
+	// length - the number of words in every indexed note together
+	length int
+
+	// touched - the notes changed until Build is done, which must not be replaced with what it read before; kept
+	// from the start, as changes may be followed before Build runs
+	touched map[string]bool
+	ready   chan struct{}
+	once    sync.Once
+}
+
+// searchDoc - an indexed note, listed without its content, with its words in order and how often each occurs
+type searchDoc struct {
+	note  Note
+	terms []string
+	freq  map[string]int
+}
+
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{
+		docs:     make(map[string]*searchDoc),
+		postings: make(map[string]map[string]struct{}),
+		touched:  make(map[string]bool),
+		ready:    make(chan struct{}),
+	}
+}
+
+// WithSearchIndex - a middleware answering SearchNotes from index, for repositories that cannot search their notes
+func WithSearchIndex(index *SearchIndex) Middleware {
+	return func(repository Repository) Repository {
+		return &indexedRepository{RepositoryWrapper: RepositoryWrapper{Repository: repository}, index: index}
+	}
+}
+
+type indexedRepository struct {
+	RepositoryWrapper
+	index *SearchIndex
+}
+
+func (r *indexedRepository) SearchNotes(ctx context.Context, query string) ([]Note, error) {
+	return r.index.Search(ctx, query)
+}
+
+// Build - index every note of repository, reading their content loadConcurrency at a time; searches wait until
+// Build returned, even when it failed, leaving the notes it could not read out of the index
+func (i *SearchIndex) Build(ctx context.Context, repository Repository) error {
+	defer i.once.Do(func() { close(i.ready) })
+
+	defer func() {
+		i.mu.Lock()
+		i.touched = nil
+		i.mu.Unlock()
+	}()
+
+	notes := make(chan Note)
+	var wg sync.WaitGroup
+	for range loadConcurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for note := range notes {
+				loaded, err := LoadNote(ctx, repository, note)
+				if err != nil {
+					slog.Warn("failed to index note", "file", note.RelativePath(), "error", err)
+					continue
+				}
+
+				i.mu.Lock()
+				if !i.touched[loaded.RelativePath()] {
+					i.add(loaded)
+				}
+				i.mu.Unlock()
+			}
+		}()
+	}
+
+	err := StreamNotes(ctx, repository, func(batch []Note) {
+		for _, note := range batch {
+			select {
+			case notes <- note:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	close(notes)
+	wg.Wait()
+
+	if err != nil {
+		slog.Error("failed to index notes", "error", err)
+		return err
+	}
+
+	i.mu.RLock()
+	slog.Info("indexed notes", "count", len(i.docs), "words", len(i.postings))
+	i.mu.RUnlock()
+
+	return nil
+}
+
+// Follow - apply every batch of changes until the channel is closed
+func (i *SearchIndex) Follow(changes <-chan []NoteChange) {
+	for batch := range changes {
+		i.Apply(batch)
+	}
+}
+
+// Apply - update the index with changes made to the vault
+func (i *SearchIndex) Apply(changes []NoteChange) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, change := range changes {
+		if change.Kind == NoteRenamed {
+			i.touch(change.Previous.RelativePath())
+			i.remove(change.Previous.RelativePath())
+		}
+
+		i.touch(change.Note.RelativePath())
+		i.remove(change.Note.RelativePath())
+		if change.Kind != NoteDeleted {
+			i.add(change.Note)
+		}
+	}
+}
+
+// Search - the notes matching query, as read by ParseQuery, best ranked first, waiting for Build to finish
+func (i *SearchIndex) Search(ctx context.Context, query string) ([]Note, error) {
+	parsed, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Empty() {
+		return nil, nil
+	}
+
+	select {
+	case <-i.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	scores := i.score(parsed.root)
+
+	paths := make([]string, 0, len(scores))
+	for path := range scores {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(a, b int) bool {
+		if scores[paths[a]] != scores[paths[b]] {
+			return scores[paths[a]] > scores[paths[b]]
+		}
+		return paths[a] < paths[b]
+	})
+
+	notes := make([]Note, 0, len(paths))
+	for _, path := range paths {
+		notes = append(notes, i.docs[path].note)
+	}
+
+	return notes, nil
+}
+
+func (i *SearchIndex) touch(relativePath string) {
+	if i.touched != nil {
+		i.touched[relativePath] = true
+	}
+}
+
+// add - index the title and content of note, replacing what was indexed for it before
+func (i *SearchIndex) add(note Note) {
+	i.remove(note.RelativePath())
+
+	doc := &searchDoc{note: note.summary(), freq: make(map[string]int)}
+	for _, token := range append(tokenize(note.Title()), tokenize(note.FileContent())...) {
+		doc.terms = append(doc.terms, token.term)
+		doc.freq[token.term]++
+	}
+
+	relativePath := note.RelativePath()
+	i.docs[relativePath] = doc
+	i.length += len(doc.terms)
+
+	for term := range doc.freq {
+		if i.postings[term] == nil {
+			i.postings[term] = make(map[string]struct{})
+		}
+		i.postings[term][relativePath] = struct{}{}
+	}
+}
+
+func (i *SearchIndex) remove(relativePath string) {
+	doc, ok := i.docs[relativePath]
+	if !ok {
+		return
+	}
+
+	delete(i.docs, relativePath)
+	i.length -= len(doc.terms)
+
+	for term := range doc.freq {
+		delete(i.postings[term], relativePath)
+		if len(i.postings[term]) == 0 {
+			delete(i.postings, term)
+		}
+	}
+}
+
+// score - the BM25 score of every note matching node by its relative path
+func (i *SearchIndex) score(node *queryNode) map[string]float64 {
+	switch node.op {
+	case matchWords:
+		return i.scoreWords(node)
+	case matchAny:
+		scores := make(map[string]float64)
+		for _, child := range node.children {
+			for path, score := range i.score(child) {
+				scores[path] += score
+			}
+		}
+		return scores
+	}
+
+	var scores map[string]float64
+	for _, child := range node.children {
+		if child.op == matchNot {
+			continue
+		}
+
+		childScores := i.score(child)
+		if scores == nil {
+			scores = childScores
+			continue
+		}
+
+		for path, score := range scores {
+			if childScore, ok := childScores[path]; ok {
+				scores[path] = score + childScore
+			} else {
+				delete(scores, path)
+			}
+		}
+	}
+
+	for _, child := range node.children {
+		if child.op == matchNot {
+			for path := range i.score(child.children[0]) {
+				delete(scores, path)
+			}
+		}
+	}
+
+	return scores
+}
+
+// scoreWords - the BM25 score of every note holding the words of node in a row, counting each run as an occurrence
+func (i *SearchIndex) scoreWords(node *queryNode) map[string]float64 {
+	first := i.expand(node, 0)
+
+	candidates := make(map[string]struct{})
+	for _, term := range first {
+		for path := range i.postings[term] {
+			candidates[path] = struct{}{}
+		}
+	}
+
+	occurrences := make(map[string]int)
+	for path := range candidates {
+		doc := i.docs[path]
+
+		count := 0
+		if len(node.words) == 1 {
+			for _, term := range first {
+				count += doc.freq[term]
+			}
+		} else {
+			for start := range doc.terms {
+				if node.matchesAt(doc.terms, start) {
+					count++
+				}
+			}
+		}
+
+		if count > 0 {
+			occurrences[path] = count
+		}
+	}
+
+	total := float64(len(i.docs))
+	found := float64(len(occurrences))
+	idf := math.Log(1 + (total-found+0.5)/(found+0.5))
+	averageLength := float64(i.length) / math.Max(total, 1)
+
+	scores := make(map[string]float64, len(occurrences))
+	for path, count := range occurrences {
+		tf := float64(count)
+		length := float64(len(i.docs[path].terms))
+		scores[path] = idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*length/math.Max(averageLength, 1)))
+	}
+
+	return scores
+}
+
+// expand - the indexed words matching the n-th word of node, all those it is a prefix of for a prefix
+func (i *SearchIndex) expand(node *queryNode, n int) []string {
+	if !node.prefix || n != len(node.words)-1 {
+		return []string{node.words[n]}
+	}
+
+	var terms []string
+	for term := range i.postings {
+		if strings.HasPrefix(term, node.words[n]) {
+			terms = append(terms, term)
+		}
+	}
+
+	return terms
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func noteTitles(notes []Note) map[string]bool {
+	titles := map[string]bool{}
+	for _, note := range notes {
+		titles[note.Title()] = true
+	}
+
+	return titles
+}
+
+func newBuiltSearchIndex(t *testing.T, repository Repository) *SearchIndex {
+	t.Helper()
+
+	index := NewSearchIndex()
+	if err := index.Build(context.Background(), repository); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	return index
+}
+
+func TestSearchIndex(t *testing.T) {
+	ctx := context.Background()
+	repository := NewMemoryRepository(
+		NewNote("elephants.md", "Elephants remember everything. An elephant is large, elephants are grey."),
+		NewNote("mice.md", "Mice are small, unlike an elephant, and remember little."),
+		NewNote("cats.md", "Cats ignore everything, even large grey mice."),
+		NewNote("zoo/keepers.md", "Keepers feed the elephants at noon."),
+	)
+	index := newBuiltSearchIndex(t, repository)
+
+	t.Run("notes are ranked by relevance", func(t *testing.T) {
+		found, err := index.Search(ctx, "elephant*")
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(found) != 3 || found[0].Title() != "elephants" {
+			t.Errorf("Expected elephants to rank above the notes mentioning them once, got %v", found)
+		}
+	})
+
+	for query, expected := range map[string][]string{
+		"elephant*":                 {"elephants", "mice", "keepers"},
+		`"large grey"`:              {"cats"},
+		`"grey large"`:              nil,
+		"remember everything":       {"elephants"},
+		"remember AND everything":   {"elephants"},
+		"ignore OR little":          {"cats", "mice"},
+		"remember -little":          {"elephants"},
+		"large NOT (grey OR small)": nil,
+		"zoo":                       nil,
+		"keepers":                   {"keepers"},
+	} {
+		t.Run("query "+query, func(t *testing.T) {
+			found, err := index.Search(ctx, query)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+
+			titles := noteTitles(found)
+			if len(found) != len(expected) {
+				t.Fatalf("Expected %v, got %v", expected, titles)
+			}
+			for _, title := range expected {
+				if !titles[title] {
+					t.Errorf("Expected %s among %v", title, titles)
+				}
+			}
+		})
+	}
+
+	t.Run("found notes are listed without their content", func(t *testing.T) {
+		found, _ := index.Search(ctx, "keepers")
+		if len(found) != 1 || found[0].Loaded() || found[0].RelativePath() != "zoo/keepers.md" {
+			t.Fatalf("Expected the keepers note without its content, got %v", found)
+		}
+
+		loaded, err := LoadNote(ctx, repository, found[0])
+		if err != nil || loaded.FileContent() != "Keepers feed the elephants at noon." {
+			t.Errorf("Expected LoadNote to read the found note, got %q, %v", loaded.FileContent(), err)
+		}
+	})
+
+	t.Run("invalid queries are rejected", func(t *testing.T) {
+		if _, err := index.Search(ctx, "-elephant"); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("Expected ErrInvalidQuery, got %v", err)
+		}
+	})
+}
+
+func TestSearchIndexApply(t *testing.T) {
+	ctx := context.Background()
+	index := newBuiltSearchIndex(t, NewMemoryRepository(NewNote("first.md", "The old words")))
+
+	second := NewNote("second.md", "Brand new words")
+	renamed := NewNote("renamed.md", "The old words, renamed")
+	index.Apply([]NoteChange{
+		{Kind: NoteCreated, Note: second},
+		{Kind: NoteRenamed, Note: renamed, Previous: NewNote("first.md", "")},
+	})
+
+	if found, _ := index.Search(ctx, "words"); len(found) != 2 || !noteTitles(found)["renamed"] || !noteTitles(found)["second"] {
+		t.Errorf("Expected the created and the renamed note, got %v", found)
+	}
+
+	index.Apply([]NoteChange{
+		{Kind: NoteUpdated, Note: second.WithContent("Rewritten entirely")},
+		{Kind: NoteDeleted, Note: NewNote("renamed.md", "")},
+	})
+
+	if found, _ := index.Search(ctx, "words"); len(found) != 0 {
+		t.Errorf("Expected no notes left with the old words, got %v", found)
+	}
+	if found, _ := index.Search(ctx, "rewritten"); len(found) != 1 {
+		t.Errorf("Expected the updated note, got %v", found)
+	}
+}
+
+func TestSearchIndexWaitsForBuild(t *testing.T) {
+	index := NewSearchIndex()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := index.Search(ctx, "anything"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the search to wait for the index until ctx is done, got %v", err)
+	}
+
+	repository := ChainRepository(NewMemoryRepository(NewNote("note.md", "anything")), WithSearchIndex(index))
+	go func() { _ = index.Build(context.Background(), repository) }()
+
+	found, err := repository.SearchNotes(context.Background(), "anything")
+	if err != nil || len(found) != 1 {
+		t.Errorf("Expected the note once the index is built, got %v, %v", found, err)
+	}
+}
+
+func TestSearchIndexApplyBeforeBuild(t *testing.T) {
+	ctx := context.Background()
+	index := NewSearchIndex()
+
+	// a change followed before Build runs is kept over what Build reads, and counted once
+	index.Apply([]NoteChange{{Kind: NoteUpdated, Note: NewNote("note.md", "Newer words")}})
+	if err := index.Build(ctx, NewMemoryRepository(NewNote("note.md", "Older words"))); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if found, _ := index.Search(ctx, "older"); len(found) != 0 {
+		t.Errorf("Expected the content read by Build to be ignored, got %v", found)
+	}
+	if found, _ := index.Search(ctx, "newer"); len(found) != 1 {
+		t.Errorf("Expected the applied change, got %v", found)
+	}
+	if expected := len(index.docs["note.md"].terms); index.length != expected {
+		t.Errorf("Expected the note to be counted once, %d words, got %d", expected, index.length)
+	}
+}
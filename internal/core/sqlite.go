@@ -0,0 +1,495 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchemaVersion - bumped whenever sqliteSchema changes, stored as the user_version of the database
+const sqliteSchemaVersion = 1
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS notes (
+	path        TEXT PRIMARY KEY,
+	content     TEXT NOT NULL,
+	title       TEXT NOT NULL,
+	description TEXT NOT NULL,
+	metadata    TEXT NOT NULL,
+	updated_at  INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS note_tags (
+	path TEXT NOT NULL REFERENCES notes(path) ON DELETE CASCADE ON UPDATE CASCADE,
+	tag  TEXT NOT NULL,
+	PRIMARY KEY (path, tag)
+);
+CREATE INDEX IF NOT EXISTS note_tags_tag ON note_tags(tag);
+CREATE VIRTUAL TABLE IF NOT EXISTS notes_fts USING fts5(path UNINDEXED, title, content);
+CREATE TABLE IF NOT EXISTS trash (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	original_path TEXT NOT NULL,
+	content       TEXT NOT NULL,
+	deleted_at    INTEGER NOT NULL
+);
+`
+
+// SQLiteRepository - the notes of a vault stored in a single SQLite database file, where the file path of a
+// note is its path relative to the vault
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRepository - open the database at dbPath, creating it when it does not exist yet
+func NewSQLiteRepository(dbPath string) (*SQLiteRepository, error) {
+	if dir := filepath.Dir(dbPath); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	dsn := "file:" + (&url.URL{Path: dbPath}).EscapedPath() +
+		"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		slog.Error("failed to open database", "file", dbPath, "error", err)
+		return nil, err
+	}
+
+	r := &SQLiteRepository{db: db}
+	if err := r.migrate(context.Background()); err != nil {
+		slog.Error("failed to prepare database", "file", dbPath, "error", err)
+		_ = db.Close()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *SQLiteRepository) migrate(ctx context.Context) error {
+	var version int
+	if err := r.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > sqliteSchemaVersion {
+		return fmt.Errorf("database schema version %d is newer than the supported version %d", version, sqliteSchemaVersion)
+	}
+
+	if _, err := r.db.ExecContext(ctx, sqliteSchema); err != nil {
+		return err
+	}
+
+	_, err := r.db.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", sqliteSchemaVersion))
+	return err
+}
+
+func (r *SQLiteRepository) Close() error {
+	return r.db.Close()
+}
+
+func (r *SQLiteRepository) GetAllNotes(ctx context.Context) ([]Note, error) {
+	notes, err := r.queryNotes(ctx, "SELECT path, content FROM notes")
+	if err != nil {
+		slog.Error("failed to read notes", "error", err)
+		return nil, err
+	}
+
+	SortNotes(notes)
+
+	slog.Info("loaded notes", "count", len(notes))
+	return notes, nil
+}
+
+func (r *SQLiteRepository) GetNoteByTitle(ctx context.Context, title string) (Note, error) {
+	notePath := title + ".md"
+	if err := ValidateName(notePath); err != nil {
+		slog.Error("failed to read note by title", "title", title, "error", err)
+		return Note{}, err
+	}
+
+	note, err := r.getNote(ctx, r.db, notePath)
+	if err != nil {
+		slog.Error("failed to read note by title", "title", title, "error", err)
+		return Note{}, err
+	}
+
+	return note, nil
+}
+
+func (r *SQLiteRepository) GetNotesByTag(ctx context.Context, tag string) ([]Note, error) {
+	notes, err := r.queryNotes(ctx,
+		"SELECT notes.path, notes.content FROM notes JOIN note_tags ON note_tags.path = notes.path WHERE note_tags.tag = ?",
+		normalizeTag(tag))
+	if err != nil {
+		slog.Error("failed to read notes by tag", "tag", tag, "error", err)
+		return nil, err
+	}
+
+	SortNotes(notes)
+	return notes, nil
+}
+
+// SearchNotes - the notes matching query, as read by ParseQuery, ranked by relevance
+func (r *SQLiteRepository) SearchNotes(ctx context.Context, query string) ([]Note, error) {
+	parsed, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Empty() {
+		return nil, nil
+	}
+
+	notes, err := r.queryNotes(ctx,
+		"SELECT notes.path, notes.content FROM notes_fts JOIN notes ON notes.path = notes_fts.path "+
+			"WHERE notes_fts MATCH ? ORDER BY bm25(notes_fts)",
+		parsed.fts5())
+	if err != nil {
+		slog.Error("failed to search notes", "query", query, "error", err)
+		return nil, err
+	}
+
+	return notes, nil
+}
+
+// SaveNote - store the note content, returning a *ConflictError when it changed since the note was loaded and
+// ErrNotFound when it was deleted since
+func (r *SQLiteRepository) SaveNote(ctx context.Context, note Note) (Note, error) {
+	if err := ValidateName(note.RelativePath()); err != nil {
+		slog.Error("failed to save note", "file", note.FilePath(), "error", err)
+		return Note{}, err
+	}
+
+	var saved Note
+	err := r.inTransaction(ctx, func(tx *sql.Tx) error {
+		stored, err := r.getNote(ctx, tx, note.FilePath())
+		if err != nil && (!errors.Is(err, ErrNotFound) || note.Version() != "") {
+			return err
+		}
+		if err == nil && note.Version() != "" && stored.Version() != note.Version() {
+			return &ConflictError{Mine: note, Theirs: stored}
+		}
+
+		saved = NewNote(note.FilePath(), note.FileContent()).stored()
+		return r.putNote(ctx, tx, saved)
+	})
+	if err != nil {
+		slog.Error("failed to save note", "file", note.FilePath(), "error", err)
+		return Note{}, err
+	}
+
+	return saved, nil
+}
+
+// CreateEmptyNote - create a note named filename, which may contain a folder path such as projects/alpha/kickoff
+func (r *SQLiteRepository) CreateEmptyNote(ctx context.Context, filename string) (Note, error) {
+	if path.Ext(filename) != ".md" {
+		filename = filename + ".md"
+	}
+
+	if err := ValidateName(filename); err != nil {
+		slog.Error("failed to create empty note", "filename", filename, "error", err)
+		return Note{}, err
+	}
+
+	note := NewNote(filename, "# "+path.Base(filename)).stored()
+	err := r.inTransaction(ctx, func(tx *sql.Tx) error {
+		if _, err := r.getNote(ctx, tx, filename); err == nil {
+			return fmt.Errorf("%w: %s", ErrAlreadyExists, filename)
+		}
+
+		return r.putNote(ctx, tx, note)
+	})
+	if err != nil {
+		slog.Error("failed to create empty note", "filename", filename, "error", err)
+		return Note{}, err
+	}
+
+	return note, nil
+}
+
+// DeleteNote - move the note into the trash of the database, from where it can be restored
+func (r *SQLiteRepository) DeleteNote(ctx context.Context, note Note) error {
+	err := r.inTransaction(ctx, func(tx *sql.Tx) error {
+		stored, err := r.getNote(ctx, tx, note.FilePath())
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO trash (original_path, content, deleted_at) VALUES (?, ?, ?)",
+			stored.FilePath(), stored.FileContent(), time.Now().UnixNano())
+		if err != nil {
+			return err
+		}
+
+		return r.removeNote(ctx, tx, stored.FilePath())
+	})
+	if err != nil {
+		slog.Error("failed to delete note", "file", note.FilePath(), "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// RenameNote - give the note a new file name, keeping it in the same folder
+func (r *SQLiteRepository) RenameNote(ctx context.Context, note Note, newName string) (Note, error) {
+	if strings.Contains(newName, "/") {
+		return Note{}, fmt.Errorf("%w: %q must not contain a folder, move the note instead", ErrInvalidName, newName)
+	}
+
+	if path.Ext(newName) != ".md" {
+		newName = newName + ".md"
+	}
+
+	if err := ValidateName(newName); err != nil {
+		slog.Error("failed to rename note", "file", note.FilePath(), "error", err)
+		return Note{}, err
+	}
+
+	newPath := newName
+	if folder := path.Dir(note.FilePath()); folder != "." {
+		newPath = folder + "/" + newName
+	}
+
+	return r.relocateNote(ctx, note, newPath)
+}
+
+// MoveNote - move the note into folder, a path relative to the vault root where empty means the root itself
+func (r *SQLiteRepository) MoveNote(ctx context.Context, note Note, folder string) (Note, error) {
+	folder = strings.Trim(folder, "/")
+	if folder != "" {
+		if err := ValidateName(folder); err != nil {
+			slog.Error("failed to move note", "file", note.FilePath(), "error", err)
+			return Note{}, err
+		}
+	}
+
+	newPath := path.Join(folder, path.Base(note.FilePath()))
+	return r.relocateNote(ctx, note, newPath)
+}
+
+func (r *SQLiteRepository) relocateNote(ctx context.Context, note Note, newPath string) (Note, error) {
+	var relocated Note
+	err := r.inTransaction(ctx, func(tx *sql.Tx) error {
+		stored, err := r.getNote(ctx, tx, note.FilePath())
+		if err != nil {
+			return err
+		}
+
+		relocated = stored
+		if newPath == note.FilePath() {
+			return nil
+		}
+
+		if _, err := r.getNote(ctx, tx, newPath); err == nil {
+			return fmt.Errorf("%w: %s", ErrAlreadyExists, newPath)
+		}
+
+		relocated = NewNote(newPath, stored.FileContent()).stored()
+		if err := r.removeNote(ctx, tx, stored.FilePath()); err != nil {
+			return err
+		}
+		return r.putNote(ctx, tx, relocated)
+	})
+	if err != nil {
+		slog.Error("failed to relocate note", "file", note.FilePath(), "target", newPath, "error", err)
+		return Note{}, err
+	}
+
+	return relocated, nil
+}
+
+func (r *SQLiteRepository) GetTrashedNotes(ctx context.Context) ([]TrashedNote, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, original_path, content, deleted_at FROM trash ORDER BY deleted_at DESC")
+	if err != nil {
+		slog.Error("failed to read trash", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trashed []TrashedNote
+	for rows.Next() {
+		var id, deletedAt int64
+		var originalPath, content string
+		if err := rows.Scan(&id, &originalPath, &content, &deletedAt); err != nil {
+			return nil, err
+		}
+
+		trashed = append(trashed, TrashedNote{
+			ID:           strconv.FormatInt(id, 10),
+			OriginalPath: originalPath,
+			DeletedAt:    time.Unix(0, deletedAt),
+			Note:         NewNote(originalPath, content).stored(),
+		})
+	}
+
+	return trashed, rows.Err()
+}
+
+// RestoreNote - put a trashed note back at its original path, failing if another note took its place
+func (r *SQLiteRepository) RestoreNote(ctx context.Context, id string) (Note, error) {
+	var restored Note
+	err := r.inTransaction(ctx, func(tx *sql.Tx) error {
+		var originalPath, content string
+		err := tx.QueryRowContext(ctx, "SELECT original_path, content FROM trash WHERE id = ?", id).Scan(&originalPath, &content)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: invalid trash id %q", ErrNotFound, id)
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := r.getNote(ctx, tx, originalPath); err == nil {
+			return fmt.Errorf("%w: %s", ErrAlreadyExists, originalPath)
+		}
+
+		restored = NewNote(originalPath, content).stored()
+		if err := r.putNote(ctx, tx, restored); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM trash WHERE id = ?", id)
+		return err
+	})
+	if err != nil {
+		slog.Error("failed to restore note", "id", id, "error", err)
+		return Note{}, err
+	}
+
+	return restored, nil
+}
+
+// PurgeNote - permanently delete a trashed note
+func (r *SQLiteRepository) PurgeNote(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM trash WHERE id = ?", id)
+	if err != nil {
+		slog.Error("failed to purge note", "id", id, "error", err)
+		return err
+	}
+
+	if count, err := result.RowsAffected(); err == nil && count == 0 {
+		return fmt.Errorf("%w: invalid trash id %q", ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// EmptyTrash - permanently delete every trashed note deleted at least olderThan ago, returning how many were purged
+func (r *SQLiteRepository) EmptyTrash(ctx context.Context, olderThan time.Duration) (int, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM trash WHERE deleted_at <= ?", time.Now().Add(-olderThan).UnixNano())
+	if err != nil {
+		slog.Error("failed to empty trash", "error", err)
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	slog.Info("emptied trash", "purged", purged)
+	return int(purged), err
+}
+
+// queryer - what reading a note needs from either the database or a transaction
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (r *SQLiteRepository) getNote(ctx context.Context, q queryer, notePath string) (Note, error) {
+	var content string
+	err := q.QueryRowContext(ctx, "SELECT content FROM notes WHERE path = ?", notePath).Scan(&content)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Note{}, fmt.Errorf("%w: %s", ErrNotFound, notePath)
+	}
+	if err != nil {
+		return Note{}, err
+	}
+
+	return NewNote(notePath, content).stored(), nil
+}
+
+func (r *SQLiteRepository) queryNotes(ctx context.Context, query string, args ...any) ([]Note, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []Note
+	for rows.Next() {
+		var notePath, content string
+		if err := rows.Scan(&notePath, &content); err != nil {
+			return nil, err
+		}
+
+		notes = append(notes, NewNote(notePath, content).stored())
+	}
+
+	return notes, rows.Err()
+}
+
+// putNote - insert or replace the note together with its tags and search entry
+func (r *SQLiteRepository) putNote(ctx context.Context, tx *sql.Tx, note Note) error {
+	metadata, err := json.Marshal(note.Metadata())
+	if err != nil {
+		slog.Warn("failed to encode note metadata", "file", note.FilePath(), "error", err)
+		metadata = []byte("{}")
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO notes (path, content, title, description, metadata, updated_at) VALUES (?, ?, ?, ?, ?, ?) "+
+			"ON CONFLICT (path) DO UPDATE SET content = excluded.content, title = excluded.title, "+
+			"description = excluded.description, metadata = excluded.metadata, updated_at = excluded.updated_at",
+		note.FilePath(), note.FileContent(), note.Title(), note.Description(), string(metadata), time.Now().UnixNano())
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM note_tags WHERE path = ?", note.FilePath()); err != nil {
+		return err
+	}
+	for _, tag := range note.Tags() {
+		if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO note_tags (path, tag) VALUES (?, ?)", note.FilePath(), normalizeTag(tag)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM notes_fts WHERE path = ?", note.FilePath()); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO notes_fts (path, title, content) VALUES (?, ?, ?)", note.FilePath(), note.Title(), note.Body())
+	return err
+}
+
+func (r *SQLiteRepository) removeNote(ctx context.Context, tx *sql.Tx, notePath string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM notes_fts WHERE path = ?", notePath); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, "DELETE FROM notes WHERE path = ?", notePath)
+	return err
+}
+
+func (r *SQLiteRepository) inTransaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
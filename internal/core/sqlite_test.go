@@ -0,0 +1,203 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func newTestSQLiteRepository(t *testing.T) *SQLiteRepository {
+	t.Helper()
+
+	tempDir := createTempDir(t)
+	t.Cleanup(func() { removeTempDir(t, tempDir) })
+
+	repo, err := NewSQLiteRepository(filepath.Join(tempDir, "notes.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteRepository failed: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.Close() })
+
+	return repo
+}
+
+func TestSQLiteRepository(t *testing.T) {
+	t.Run("creates, saves and reads notes", func(t *testing.T) {
+		repo := newTestSQLiteRepository(t)
+		ctx := context.Background()
+
+		note, err := repo.CreateEmptyNote(ctx, "projects/alpha")
+		if err != nil {
+			t.Fatalf("CreateEmptyNote failed: %v", err)
+		}
+		if note.FilePath() != "projects/alpha.md" || note.Folder() != "projects" || note.FileContent() != "# alpha.md" {
+			t.Errorf("Unexpected created note %q in %q: %q", note.FilePath(), note.Folder(), note.FileContent())
+		}
+
+		if _, err := repo.CreateEmptyNote(ctx, "projects/alpha"); !errors.Is(err, ErrAlreadyExists) {
+			t.Errorf("Expected ErrAlreadyExists, got %v", err)
+		}
+
+		saved, err := repo.SaveNote(ctx, note.WithContent("---\ntags: [work]\n---\nHello #world"))
+		if err != nil {
+			t.Fatalf("SaveNote failed: %v", err)
+		}
+
+		loaded, err := repo.GetNoteByTitle(ctx, "projects/alpha")
+		if err != nil {
+			t.Fatalf("GetNoteByTitle failed: %v", err)
+		}
+		if loaded.FileContent() != saved.FileContent() || loaded.Version() != saved.Version() {
+			t.Errorf("Expected the saved note, got %q", loaded.FileContent())
+		}
+
+		for _, tag := range []string{"work", "#World"} {
+			tagged, err := repo.GetNotesByTag(ctx, tag)
+			if err != nil || len(tagged) != 1 {
+				t.Errorf("Expected one note tagged %s, got %d, %v", tag, len(tagged), err)
+			}
+		}
+
+		if _, err := repo.GetNoteByTitle(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+		if _, err := repo.GetNoteByTitle(ctx, "../escape"); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("Expected ErrInvalidName, got %v", err)
+		}
+	})
+
+	t.Run("saving over a changed note conflicts", func(t *testing.T) {
+		repo := newTestSQLiteRepository(t)
+		ctx := context.Background()
+
+		note, _ := repo.CreateEmptyNote(ctx, "note")
+		if _, err := repo.SaveNote(ctx, note.WithContent("theirs")); err != nil {
+			t.Fatalf("SaveNote failed: %v", err)
+		}
+
+		_, err := repo.SaveNote(ctx, note.WithContent("mine"))
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) || conflict.Theirs.FileContent() != "theirs" {
+			t.Errorf("Expected a ConflictError with theirs, got %v", err)
+		}
+	})
+
+	t.Run("renames and moves notes", func(t *testing.T) {
+		repo := newTestSQLiteRepository(t)
+		ctx := context.Background()
+
+		note, _ := repo.CreateEmptyNote(ctx, "inbox/note")
+		_, _ = repo.CreateEmptyNote(ctx, "taken")
+
+		renamed, err := repo.RenameNote(ctx, note, "renamed")
+		if err != nil || renamed.FilePath() != "inbox/renamed.md" {
+			t.Fatalf("Expected inbox/renamed.md, got %q, %v", renamed.FilePath(), err)
+		}
+
+		moved, err := repo.MoveNote(ctx, renamed, "")
+		if err != nil || moved.FilePath() != "renamed.md" {
+			t.Fatalf("Expected renamed.md, got %q, %v", moved.FilePath(), err)
+		}
+
+		if _, err := repo.RenameNote(ctx, moved, "taken"); !errors.Is(err, ErrAlreadyExists) {
+			t.Errorf("Expected ErrAlreadyExists, got %v", err)
+		}
+		if _, err := repo.RenameNote(ctx, note, "other"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for the old path, got %v", err)
+		}
+
+		notes, _ := repo.GetAllNotes(ctx)
+		if len(notes) != 2 {
+			t.Errorf("Expected 2 notes, got %d", len(notes))
+		}
+	})
+
+	t.Run("deleted notes go to the trash", func(t *testing.T) {
+		repo := newTestSQLiteRepository(t)
+		ctx := context.Background()
+
+		note, _ := repo.CreateEmptyNote(ctx, "note")
+		if err := repo.DeleteNote(ctx, note); err != nil {
+			t.Fatalf("DeleteNote failed: %v", err)
+		}
+
+		trashed, err := repo.GetTrashedNotes(ctx)
+		if err != nil || len(trashed) != 1 || trashed[0].OriginalPath != "note.md" {
+			t.Fatalf("Expected the note in the trash, got %+v, %v", trashed, err)
+		}
+
+		restored, err := repo.RestoreNote(ctx, trashed[0].ID)
+		if err != nil || restored.FilePath() != "note.md" {
+			t.Fatalf("RestoreNote failed: %v", err)
+		}
+
+		_ = repo.DeleteNote(ctx, restored)
+		purged, err := repo.EmptyTrash(ctx, 0)
+		if err != nil || purged != 1 {
+			t.Errorf("Expected 1 purged note, got %d, %v", purged, err)
+		}
+
+		if err := repo.PurgeNote(ctx, "42"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("searches note content by relevance", func(t *testing.T) {
+		repo := newTestSQLiteRepository(t)
+		ctx := context.Background()
+
+		for name, content := range map[string]string{
+			"elephants": "Elephants remember everything. Elephants are large.",
+			"mice":      "Mice are small, unlike an elephant.",
+			"cats":      "Cats ignore everything.",
+		} {
+			note, _ := repo.CreateEmptyNote(ctx, name)
+			_, _ = repo.SaveNote(ctx, note.WithContent(content))
+		}
+
+		found, err := repo.SearchNotes(ctx, "elephant*")
+		if err != nil {
+			t.Fatalf("SearchNotes failed: %v", err)
+		}
+		if len(found) != 2 || found[0].Title() != "elephants" {
+			t.Errorf("Expected elephants to rank above mice, got %v", found)
+		}
+
+		found, _ = repo.SearchNotes(ctx, `everything "ignore`)
+		if len(found) != 1 || found[0].Title() != "cats" {
+			t.Errorf("Expected only cats to match both words, got %v", found)
+		}
+
+		found, _ = repo.SearchNotes(ctx, `"are small" OR (everything -remember)`)
+		if titles := noteTitles(found); len(titles) != 2 || !titles["mice"] || !titles["cats"] {
+			t.Errorf("Expected mice and cats, got %v", found)
+		}
+
+		if _, err := repo.SearchNotes(ctx, "-elephant"); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("Expected ErrInvalidQuery for a query only excluding notes, got %v", err)
+		}
+	})
+
+	t.Run("reopens an existing database", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+		ctx := context.Background()
+		dbPath := filepath.Join(tempDir, "notes.db")
+
+		repo, _ := NewSQLiteRepository(dbPath)
+		_, _ = repo.CreateEmptyNote(ctx, "note")
+		_ = repo.Close()
+
+		repo, err := NewSQLiteRepository(dbPath)
+		if err != nil {
+			t.Fatalf("NewSQLiteRepository failed: %v", err)
+		}
+		defer repo.Close()
+
+		notes, _ := repo.GetAllNotes(ctx)
+		if len(notes) != 1 {
+			t.Errorf("Expected the note to survive reopening, got %d notes", len(notes))
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Tag - a tag and the number of notes it appears in
+type Tag struct {
+	Name  string
+	Count int
+}
+
+var inlineTagPattern = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_/-]*[\p{L}_/-][\p{L}\p{N}_/-]*)`)
+
+// extractTags - collect the front matter tags and the inline #tags of a note, lower cased and without duplicates
+func extractTags(metadata Metadata, body string) []string {
+	var tags []string
+	seen := map[string]bool{}
+
+	add := func(tag string) {
+		tag = normalizeTag(tag)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	for _, tag := range metadata.Strings("tags") {
+		add(tag)
+	}
+
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		for _, match := range inlineTagPattern.FindAllStringSubmatch(stripInlineCode(line), -1) {
+			add(match[1])
+		}
+	}
+
+	return tags
+}
+
+// CollectTags - count the notes for every tag, ordered by tag name
+func CollectTags(notes []Note) []Tag {
+	counts := map[string]int{}
+	for _, note := range notes {
+		for _, tag := range note.Tags() {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]Tag, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, Tag{Name: name, Count: count})
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+
+	return tags
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+func stripInlineCode(line string) string {
+	parts := strings.Split(line, "`")
+	for i := 1; i < len(parts); i += 2 {
+		parts[i] = ""
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,271 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrTaskChanged - the line of the task no longer holds it, the note changed since the task was listed
+var ErrTaskChanged = errors.New("task changed since it was listed")
+
+// dateLayout - how due dates are written, due:2026-10-20
+const dateLayout = "2006-01-02"
+
+// Priority - how urgent a task is, from a !low, !medium or !high in its text
+type Priority int
+
+const (
+	NoPriority Priority = iota
+	LowPriority
+	MediumPriority
+	HighPriority
+)
+
+func (p Priority) String() string {
+	switch p {
+	case LowPriority:
+		return "low"
+	case MediumPriority:
+		return "medium"
+	case HighPriority:
+		return "high"
+	default:
+		return ""
+	}
+}
+
+// Recurrence - how often a task comes back once it is done, from an every:week, every:2days or every:month in its
+// text; the zero value for tasks that do not recur
+type Recurrence struct {
+	Count int `json:"count"`
+	// Unit - day, week, month or year
+	Unit string `json:"unit"`
+}
+
+// Recurs - whether the task comes back once it is done
+func (r Recurrence) Recurs() bool {
+	return r.Count > 0
+}
+
+// Next - the date the task comes back on after from
+func (r Recurrence) Next(from time.Time) time.Time {
+	switch r.Unit {
+	case "day":
+		return from.AddDate(0, 0, r.Count)
+	case "week":
+		return from.AddDate(0, 0, 7*r.Count)
+	case "month":
+		return from.AddDate(0, r.Count, 0)
+	default:
+		return from.AddDate(r.Count, 0, 0)
+	}
+}
+
+func (r Recurrence) String() string {
+	if r.Count == 1 {
+		return r.Unit
+	}
+	return strconv.Itoa(r.Count) + r.Unit + "s"
+}
+
+// Task - a GFM task list item of a note, "- [ ] open" or "- [x] done", with the due date, priority and recurrence
+// written in its text
+type Task struct {
+	// Note - the note holding the task, as it was when the task was read from it
+	Note Note `json:"-"`
+	// Line - the line of the note content the task is on, counted from 1
+	Line int    `json:"line"`
+	Text string `json:"text"`
+	Done bool   `json:"done,omitempty"`
+	// Tags - the lower cased inline #tags of the task text, without the leading #
+	Tags []string `json:"tags,omitempty"`
+	// Due - the day the task is due, in UTC, zero when it has no due:YYYY-MM-DD
+	Due      time.Time  `json:"due,omitzero"`
+	Priority Priority   `json:"priority,omitempty"`
+	Every    Recurrence `json:"every,omitzero"`
+}
+
+// Label - the text of the task without its due date, priority and recurrence
+func (t Task) Label() string {
+	var words []string
+	for _, word := range strings.Fields(t.Text) {
+		if !taskMetadataPattern.MatchString(word) {
+			words = append(words, word)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// HasTag - whether the task, or the note holding it, is tagged with tag, ignoring case and a leading #
+func (t Task) HasTag(tag string) bool {
+	tag = normalizeTag(tag)
+	for _, own := range t.Tags {
+		if own == tag {
+			return true
+		}
+	}
+
+	return t.Note.HasTag(tag)
+}
+
+var (
+	// taskPattern - a list item starting with a checkbox, the state of which is the second group
+	taskPattern = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+\[)([ xX])\]\s+(.*?)\s*$`)
+	// taskMetadataPattern - a word of the text of a task holding its due date, priority or recurrence
+	taskMetadataPattern = regexp.MustCompile(`(?i)^(?:due:(\d{4}-\d{2}-\d{2})|!(low|medium|high)|every:(\d*)(day|week|month|year)s?)$`)
+	// dueDatePattern - the due date in the text of a task, replaced when a recurring task comes back
+	dueDatePattern = regexp.MustCompile(`(?i)\bdue:\d{4}-\d{2}-\d{2}`)
+)
+
+// extractTasks - the tasks of body, which starts on line firstLine of the note content, leaving out those in code
+func extractTasks(body string, firstLine int) []Task {
+	var tasks []Task
+
+	lines := strings.Split(body, "\n")
+	code := fencedLines(lines)
+	for i, line := range lines {
+		if code[i] {
+			continue
+		}
+
+		if task, ok := parseTask(line); ok {
+			task.Line = firstLine + i
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks
+}
+
+func parseTask(line string) (Task, bool) {
+	match := taskPattern.FindStringSubmatch(line)
+	if match == nil || match[3] == "" {
+		return Task{}, false
+	}
+
+	task := Task{Text: match[3], Done: match[2] != " "}
+	for _, tag := range inlineTagPattern.FindAllStringSubmatch(stripInlineCode(task.Text), -1) {
+		if tag := normalizeTag(tag[1]); tag != "" {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+
+	// the first of every kind of marker counts
+	for _, word := range strings.Fields(task.Text) {
+		marker := taskMetadataPattern.FindStringSubmatch(word)
+		switch {
+		case marker == nil:
+		case marker[1] != "":
+			if due, err := time.Parse(dateLayout, marker[1]); err == nil && task.Due.IsZero() {
+				task.Due = due
+			}
+		case marker[2] != "" && task.Priority == NoPriority:
+			task.Priority = map[string]Priority{"low": LowPriority, "medium": MediumPriority, "high": HighPriority}[strings.ToLower(marker[2])]
+		case marker[4] != "" && !task.Every.Recurs():
+			count, err := strconv.Atoi(marker[3])
+			if err != nil {
+				count = 1
+			}
+			if count > 0 {
+				task.Every = Recurrence{Count: count, Unit: strings.ToLower(marker[4])}
+			}
+		}
+	}
+
+	return task, true
+}
+
+// CollectTasks - the tasks of notes, ordered by the path of their note and their line
+func CollectTasks(notes []Note) []Task {
+	var tasks []Task
+	for _, note := range notes {
+		tasks = append(tasks, note.Tasks()...)
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Note.RelativePath() != tasks[j].Note.RelativePath() {
+			return tasks[i].Note.RelativePath() < tasks[j].Note.RelativePath()
+		}
+		return tasks[i].Line < tasks[j].Line
+	})
+
+	return tasks
+}
+
+// ToggleTask - check or uncheck task, saving its note with only the checkbox of the task changed, and with the next
+// occurrence added below it when a recurring task is checked; ErrTaskChanged when its line no longer holds it
+func ToggleTask(ctx context.Context, repository Repository, task Task) (Task, error) {
+	return toggleTask(ctx, repository, task, Today(time.Now()))
+}
+
+// toggleTask - ToggleTask on the day today, which recurring tasks without a due date come back after
+func toggleTask(ctx context.Context, repository Repository, task Task, today time.Time) (Task, error) {
+	note, err := LoadNote(ctx, repository, task.Note)
+	if err != nil {
+		slog.Error("failed to toggle task", "file", task.Note.FilePath(), "error", err)
+		return Task{}, err
+	}
+
+	lines := strings.Split(note.FileContent(), "\n")
+	if task.Line < 1 || task.Line > len(lines) {
+		return Task{}, fmt.Errorf("%w: %s:%d", ErrTaskChanged, note.RelativePath(), task.Line)
+	}
+
+	line := lines[task.Line-1]
+	current, ok := parseTask(line)
+	match := taskPattern.FindStringSubmatchIndex(line)
+	if !ok || current.Text != task.Text || current.Done != task.Done {
+		return Task{}, fmt.Errorf("%w: %s:%d", ErrTaskChanged, note.RelativePath(), task.Line)
+	}
+
+	mark := "x"
+	if task.Done {
+		mark = " "
+	}
+	lines[task.Line-1] = line[:match[4]] + mark + line[match[5]:]
+
+	if !task.Done && task.Every.Recurs() {
+		lines = slices.Insert(lines, task.Line, nextOccurrence(line, task, today))
+	}
+
+	saved, err := repository.SaveNote(ctx, note.WithContent(strings.Join(lines, "\n")))
+	if err != nil {
+		slog.Error("failed to toggle task", "file", note.FilePath(), "error", err)
+		return Task{}, err
+	}
+
+	task.Note = saved
+	task.Done = !task.Done
+	return task, nil
+}
+
+// nextOccurrence - the line of the open recurring task, due once more after its due date, or after today when it has
+// none
+func nextOccurrence(line string, task Task, today time.Time) string {
+	from := task.Due
+	if from.IsZero() {
+		from = today
+	}
+	due := "due:" + task.Every.Next(from).Format(dateLayout)
+
+	if dueDatePattern.MatchString(line) {
+		return dueDatePattern.ReplaceAllLiteralString(line, due)
+	}
+
+	trimmed := strings.TrimRight(line, " \t\r")
+	return trimmed + " " + due + line[len(trimmed):]
+}
+
+// Today - the day of now, as due dates are kept: at midnight UTC
+func Today(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,155 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractTasks(t *testing.T) {
+	content := "---\ntags: [home]\n---\n# Menu\n- [ ] Buy #groceries\n  * [x] Eggs\n1. [X] Numbered\n- [ ]\n- plain item\n" +
+		"```\n- [ ] in code\n```\n- [ ] Windows line\r\n"
+
+	tasks := NewNote("menu.md", content).Tasks()
+
+	expected := []Task{
+		{Line: 5, Text: "Buy #groceries", Tags: []string{"groceries"}},
+		{Line: 6, Text: "Eggs", Done: true},
+		{Line: 7, Text: "Numbered", Done: true},
+		{Line: 13, Text: "Windows line"},
+	}
+
+	if len(tasks) != len(expected) {
+		t.Fatalf("Expected %d tasks, got %+v", len(expected), tasks)
+	}
+	for i, want := range expected {
+		got := tasks[i]
+		if got.Line != want.Line || got.Text != want.Text || got.Done != want.Done || len(got.Tags) != len(want.Tags) {
+			t.Errorf("Expected task %d to be %+v, got %+v", i, want, got)
+		}
+		if got.Note.RelativePath() != "menu.md" {
+			t.Errorf("Expected task %d to hold its note, got %q", i, got.Note.RelativePath())
+		}
+	}
+
+	if !tasks[0].HasTag("#Groceries") || !tasks[1].HasTag("home") || tasks[1].HasTag("work") {
+		t.Error("Expected tasks to carry their own tags and those of their note")
+	}
+}
+
+func TestCollectTasks(t *testing.T) {
+	tasks := CollectTasks([]Note{
+		NewNote("b.md", "- [ ] second\n- [ ] third"),
+		NewNote("a.md", "- [x] first"),
+	})
+
+	if len(tasks) != 3 || tasks[0].Text != "first" || tasks[1].Text != "second" || tasks[2].Text != "third" {
+		t.Errorf("Expected the tasks ordered by note and line, got %+v", tasks)
+	}
+}
+
+func TestToggleTask(t *testing.T) {
+	ctx := context.Background()
+	content := "# Menu\n- [ ] Soup\n- [x] Bread  \nText after.\n"
+	repository := NewMemoryRepository(NewNote("menu.md", content))
+
+	menu, _ := repository.GetNoteByTitle(ctx, "menu")
+	tasks := menu.Tasks()
+
+	toggled, err := ToggleTask(ctx, repository, tasks[0])
+	if err != nil {
+		t.Fatalf("ToggleTask failed: %v", err)
+	}
+	if !toggled.Done || toggled.Note.FileContent() != "# Menu\n- [x] Soup\n- [x] Bread  \nText after.\n" {
+		t.Errorf("Expected only the checkbox to change, got %q", toggled.Note.FileContent())
+	}
+
+	toggled, err = ToggleTask(ctx, repository, toggled.Note.Tasks()[1])
+	if err != nil {
+		t.Fatalf("ToggleTask failed: %v", err)
+	}
+	if toggled.Done || toggled.Note.FileContent() != "# Menu\n- [x] Soup\n- [ ] Bread  \nText after.\n" {
+		t.Errorf("Expected the done task to be unchecked, got %q", toggled.Note.FileContent())
+	}
+
+	if _, err := ToggleTask(ctx, repository, tasks[0]); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected toggling a task of a note loaded before it changed to conflict, got %v", err)
+	}
+
+	moved := toggled.Note.Tasks()[0]
+	moved.Line = 3
+	if _, err := ToggleTask(ctx, repository, moved); !errors.Is(err, ErrTaskChanged) {
+		t.Errorf("Expected toggling a task no longer on its line to fail, got %v", err)
+	}
+}
+
+func TestTaskMetadata(t *testing.T) {
+	tasks := NewNote("plan.md", "- [ ] Water plants due:2026-10-20 !HIGH every:week\n- [ ] Pay rent every:2months !low\n- [ ] Plain due:2026-13-40").Tasks()
+
+	water := tasks[0]
+	if !water.Due.Equal(time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)) || water.Priority != HighPriority || water.Every != (Recurrence{Count: 1, Unit: "week"}) {
+		t.Errorf("Expected the due date, priority and recurrence, got %+v", water)
+	}
+	if water.Label() != "Water plants" {
+		t.Errorf("Expected the label without the markers, got %q", water.Label())
+	}
+
+	if rent := tasks[1]; !rent.Due.IsZero() || rent.Priority != LowPriority || rent.Every.String() != "2months" {
+		t.Errorf("Expected a low priority task every 2 months, got %+v", rent)
+	}
+	if plain := tasks[2]; !plain.Due.IsZero() {
+		t.Errorf("Expected an invalid date to be ignored, got %v", plain.Due)
+	}
+}
+
+func TestToggleRecurringTask(t *testing.T) {
+	ctx := context.Background()
+	today := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	repository := NewMemoryRepository(NewNote("plan.md", "- [ ] Water plants due:2026-10-20 every:week\n- [ ] Stretch every:day\r\nEnd"))
+
+	plan, _ := repository.GetNoteByTitle(ctx, "plan")
+
+	toggled, err := toggleTask(ctx, repository, plan.Tasks()[0], today)
+	if err != nil {
+		t.Fatalf("toggleTask failed: %v", err)
+	}
+
+	tasks := toggled.Note.Tasks()
+	toggled, err = toggleTask(ctx, repository, tasks[2], today)
+	if err != nil {
+		t.Fatalf("toggleTask failed: %v", err)
+	}
+
+	expected := "- [x] Water plants due:2026-10-20 every:week\n- [ ] Water plants due:2026-10-27 every:week\n" +
+		"- [x] Stretch every:day\r\n- [ ] Stretch every:day due:2026-10-19\r\nEnd"
+	if content := toggled.Note.FileContent(); content != expected {
+		t.Errorf("Expected the next occurrences below the checked tasks, got %q", content)
+	}
+
+	toggled, _ = toggleTask(ctx, repository, toggled.Note.Tasks()[0], today)
+	if len(toggled.Note.Tasks()) != 4 {
+		t.Errorf("Expected unchecking a recurring task to add nothing, got %q", toggled.Note.FileContent())
+	}
+}
+
+func TestAgenda(t *testing.T) {
+	today := time.Date(2026, 10, 14, 15, 30, 0, 0, time.Local) // a Wednesday
+
+	tasks := CollectTasks([]Note{NewNote("plan.md", "- [ ] Late due:2026-10-01\n- [ ] Now due:2026-10-14\n- [ ] Sooner !high due:2026-10-18\n"+
+		"- [ ] Soon due:2026-10-16\n- [ ] Next week due:2026-10-19\n- [x] Done due:2026-10-01\n- [ ] Someday")})
+
+	var got []string
+	for _, section := range Agenda(tasks, today) {
+		got = append(got, section.Group.String()+":")
+		for _, task := range section.Tasks {
+			got = append(got, task.Label())
+		}
+	}
+
+	expected := "Overdue:,Late,Today:,Now,This week:,Soon,Sooner,Later:,Next week"
+	if strings.Join(got, ",") != expected {
+		t.Errorf("Expected %s, got %s", expected, strings.Join(got, ","))
+	}
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrEmbedCycle - a note embeds itself, directly or through the notes it embeds
+	ErrEmbedCycle = errors.New("note embeds itself")
+	// ErrEmbedDepth - embeds are nested deeper than Transclude follows
+	ErrEmbedDepth = errors.New("embeds nested too deep")
+)
+
+// Embed - what an ![[embed]] link shows in place: the note it points to and the part of it the fragment names, with
+// the embeds in that part already expanded; Err when it could not be resolved, with Note zero when the note is missing
+type Embed struct {
+	Link    Link
+	Note    Note
+	Content string
+	Depth   int
+	Err     error
+}
+
+// Transclude - the body of note with every ![[embed]] replaced by what wrap makes of it, following embeds inside
+// embedded parts up to maxDepth levels and stopping at cycles; ^block ids are left out of the text. Embeds resolve
+// through the link graph when the repository has one, and by their vault path otherwise
+func Transclude(ctx context.Context, repository Repository, note Note, maxDepth int, wrap func(Embed) string) (string, error) {
+	t := transclusion{repository: repository, maxDepth: maxDepth, wrap: wrap}
+	return t.expand(ctx, note, note.Body(), 0, map[string]bool{embedKey(note, ""): true})
+}
+
+type transclusion struct {
+	repository Repository
+	maxDepth   int
+	wrap       func(Embed) string
+}
+
+// expand - text of from with its embeds replaced, chain holding the notes and parts embedding it
+func (t transclusion) expand(ctx context.Context, from Note, text string, depth int, chain map[string]bool) (string, error) {
+	var expanded strings.Builder
+
+	last := 0
+	for _, link := range extractLinks(text, 0) {
+		if !link.Embed {
+			continue
+		}
+
+		embed, err := t.embed(ctx, from, link, depth, chain)
+		if err != nil {
+			return "", err
+		}
+
+		expanded.WriteString(StripBlockIDs(text[last:link.Start]))
+		expanded.WriteString(t.wrap(embed))
+		last = link.End
+	}
+	expanded.WriteString(StripBlockIDs(text[last:]))
+
+	return expanded.String(), nil
+}
+
+// embed - the part of the note link points to, expanded in turn; errors are those of ctx, the embeds that cannot be
+// shown carry theirs in Err
+func (t transclusion) embed(ctx context.Context, from Note, link Link, depth int, chain map[string]bool) (Embed, error) {
+	embed := Embed{Link: link, Depth: depth}
+
+	target, err := t.resolve(ctx, from, link)
+	if err == nil {
+		target, err = LoadNote(ctx, t.repository, target)
+	}
+	if ctx.Err() != nil {
+		return Embed{}, ctx.Err()
+	}
+	if err != nil {
+		embed.Err = err
+		return embed, nil
+	}
+	embed.Note = target
+
+	key := embedKey(target, link.Fragment)
+	switch {
+	case chain[key]:
+		embed.Err = fmt.Errorf("%w: %s", ErrEmbedCycle, target.RelativePath())
+		return embed, nil
+	case depth >= t.maxDepth:
+		embed.Err = fmt.Errorf("%w: %s", ErrEmbedDepth, target.RelativePath())
+		return embed, nil
+	}
+
+	section := target.Body()
+	if link.Fragment != "" {
+		if section, err = Section(section, link.Fragment); err != nil {
+			embed.Err = fmt.Errorf("%w: %s#%s", err, target.RelativePath(), link.Fragment)
+			return embed, nil
+		}
+	}
+
+	chain[key] = true
+	defer delete(chain, key)
+
+	embed.Content, err = t.expand(ctx, target, section, depth+1, chain)
+	return embed, err
+}
+
+// resolve - the note link in from points to, by the link graph when there is one
+func (t transclusion) resolve(ctx context.Context, from Note, link Link) (Note, error) {
+	if link.Target == "" {
+		return from, nil
+	}
+
+	if backlinker, ok := t.repository.(Backlinker); ok {
+		return backlinker.ResolveLink(ctx, from, link)
+	}
+
+	return t.repository.GetNoteByTitle(ctx, strings.TrimSuffix(strings.TrimPrefix(link.Target, "/"), ".md"))
+}
+
+// embedKey - the note and the part of it an embed shows, the whole note for an empty fragment
+func embedKey(note Note, fragment string) string {
+	switch {
+	case fragment == "":
+		return note.RelativePath()
+	case strings.HasPrefix(fragment, "^"):
+		return note.RelativePath() + "#" + fragment
+	}
+
+	return note.RelativePath() + "#" + headingSlug(fragment)
+}
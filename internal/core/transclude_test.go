@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestTransclude(t *testing.T) {
+	ctx := context.Background()
+
+	graph := NewLinkGraph()
+	repository := ChainRepository(NewMemoryRepository(
+		NewNote("index.md", "Start\n![[Plan#Goals]]\nEnd ^end"),
+		NewNote("projects/Plan.md", "# Plan\n## Goals\nShip it. ^ship\n![[Quote#^wise]]\n## Risks\nNone."),
+		NewNote("Quote.md", "Something wise. ^wise"),
+		NewNote("loop.md", "Loop\n![[back]]"),
+		NewNote("back.md", "Back\n![[loop]]"),
+		NewNote("broken.md", "![[Missing]] and ![[Quote#Nowhere]]"),
+	), WithLinkGraph(graph))
+	if err := graph.Build(ctx, repository); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	wrap := func(embed Embed) string {
+		if embed.Err != nil {
+			return fmt.Sprintf("<%s: %v>", embed.Link.Target, embed.Err)
+		}
+		return fmt.Sprintf("<%s:%d %s>", embed.Note.RelativePath(), embed.Depth, embed.Content)
+	}
+
+	t.Run("embeds are expanded recursively", func(t *testing.T) {
+		index, _ := repository.GetNoteByTitle(ctx, "index")
+
+		expanded, err := Transclude(ctx, repository, index, 4, wrap)
+		if err != nil {
+			t.Fatalf("Transclude failed: %v", err)
+		}
+
+		expected := "Start\n<projects/Plan.md:0 ## Goals\nShip it.\n<Quote.md:1 Something wise.>>\nEnd"
+		if expanded != expected {
+			t.Errorf("Expected %q, got %q", expected, expanded)
+		}
+	})
+
+	t.Run("embeds stop at the depth limit", func(t *testing.T) {
+		index, _ := repository.GetNoteByTitle(ctx, "index")
+
+		expanded, _ := Transclude(ctx, repository, index, 1, func(embed Embed) string {
+			if errors.Is(embed.Err, ErrEmbedDepth) {
+				return "<too deep>"
+			}
+			return wrap(embed)
+		})
+
+		if expanded != "Start\n<projects/Plan.md:0 ## Goals\nShip it.\n<too deep>>\nEnd" {
+			t.Errorf("Expected the nested embed to stop, got %q", expanded)
+		}
+	})
+
+	t.Run("cycles are cut", func(t *testing.T) {
+		loop, _ := repository.GetNoteByTitle(ctx, "loop")
+
+		expanded, err := Transclude(ctx, repository, loop, 10, func(embed Embed) string {
+			if errors.Is(embed.Err, ErrEmbedCycle) {
+				return "<cycle>"
+			}
+			return wrap(embed)
+		})
+		if err != nil {
+			t.Fatalf("Transclude failed: %v", err)
+		}
+
+		if expanded != "Loop\n<back.md:0 Back\n<cycle>>" {
+			t.Errorf("Expected the cycle to be cut, got %q", expanded)
+		}
+	})
+
+	t.Run("missing notes and sections are reported", func(t *testing.T) {
+		broken, _ := repository.GetNoteByTitle(ctx, "broken")
+
+		var errs []error
+		_, _ = Transclude(ctx, repository, broken, 4, func(embed Embed) string {
+			errs = append(errs, embed.Err)
+			return ""
+		})
+
+		if len(errs) != 2 || !errors.Is(errs[0], ErrNotFound) || !errors.Is(errs[1], ErrNoAnchor) {
+			t.Errorf("Expected a missing note and a missing heading, got %v", errs)
+		}
+	})
+}
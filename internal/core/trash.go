@@ -0,0 +1,232 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const trashDirectory = ".trash"
+
+// Trash - notes deleted from a vault that can still be restored or purged
+type Trash interface {
+	GetTrashedNotes(ctx context.Context) ([]TrashedNote, error)
+	RestoreNote(ctx context.Context, id string) (Note, error)
+	PurgeNote(ctx context.Context, id string) error
+	EmptyTrash(ctx context.Context, olderThan time.Duration) (int, error)
+}
+
+// TrashedNote - a deleted note together with where it came from and when it was deleted
+type TrashedNote struct {
+	ID           string
+	OriginalPath string
+	DeletedAt    time.Time
+	Note         Note
+}
+
+type trashEntry struct {
+	OriginalPath string    `json:"original_path"`
+	DeletedAt    time.Time `json:"deleted_at"`
+}
+
+func (r *NoteRepository) trashPath(names ...string) string {
+	return filepath.Join(append([]string{r.basePath, trashDirectory}, names...)...)
+}
+
+// moveToTrash - move the note file into the trash directory next to a sidecar recording its origin
+func (r *NoteRepository) moveToTrash(note Note, deletedAt time.Time) error {
+	err := os.MkdirAll(r.trashPath(), 0755)
+	if err != nil {
+		return err
+	}
+
+	originalPath, err := filepath.Rel(r.basePath, note.FilePath())
+	if err != nil {
+		return err
+	}
+
+	id := fmt.Sprintf("%d-%s", deletedAt.UnixNano(), strings.TrimSuffix(filepath.Base(note.FilePath()), ".md"))
+	entry, err := json.Marshal(trashEntry{OriginalPath: filepath.ToSlash(originalPath), DeletedAt: deletedAt})
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(r.trashPath(id+".json"), entry, 0644)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(note.FilePath(), r.trashPath(id+".md"))
+	if err != nil {
+		_ = os.Remove(r.trashPath(id + ".json"))
+		return err
+	}
+
+	return nil
+}
+
+func (r *NoteRepository) GetTrashedNotes(ctx context.Context) ([]TrashedNote, error) {
+	return awaitContext(ctx, func() ([]TrashedNote, error) {
+		return r.getTrashedNotes(ctx)
+	})
+}
+
+func (r *NoteRepository) getTrashedNotes(ctx context.Context) ([]TrashedNote, error) {
+	entries, err := filepath.Glob(r.trashPath("*.json"))
+	if err != nil {
+		slog.Error("failed to read trash", "error", err)
+		return nil, err
+	}
+
+	var trashed []TrashedNote
+	for _, entryPath := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		id := strings.TrimSuffix(filepath.Base(entryPath), ".json")
+
+		trashedNote, err := r.getTrashedNote(id)
+		if err != nil {
+			slog.Warn("failed to read trashed note", "id", id, "error", err)
+			continue
+		}
+
+		trashed = append(trashed, trashedNote)
+	}
+
+	sort.Slice(trashed, func(i, j int) bool {
+		return trashed[i].DeletedAt.After(trashed[j].DeletedAt)
+	})
+
+	return trashed, nil
+}
+
+func (r *NoteRepository) getTrashedNote(id string) (TrashedNote, error) {
+	if id == "" || filepath.Base(id) != id {
+		return TrashedNote{}, fmt.Errorf("%w: invalid trash id %q", ErrNotFound, id)
+	}
+
+	data, err := os.ReadFile(r.trashPath(id + ".json"))
+	if err != nil {
+		return TrashedNote{}, notFoundError(err, r.trashPath(id+".json"))
+	}
+
+	var entry trashEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return TrashedNote{}, err
+	}
+
+	content, err := os.ReadFile(r.trashPath(id + ".md"))
+	if err != nil {
+		return TrashedNote{}, err
+	}
+
+	originalPath := filepath.Join(r.basePath, filepath.FromSlash(entry.OriginalPath))
+
+	return TrashedNote{
+		ID:           id,
+		OriginalPath: entry.OriginalPath,
+		DeletedAt:    entry.DeletedAt,
+		Note:         newVaultNote(r.basePath, originalPath, string(content)),
+	}, nil
+}
+
+// RestoreNote - put a trashed note back at its original path, failing if another note took its place
+func (r *NoteRepository) RestoreNote(ctx context.Context, id string) (Note, error) {
+	return awaitContext(ctx, func() (Note, error) {
+		return r.restoreNote(id)
+	})
+}
+
+func (r *NoteRepository) restoreNote(id string) (Note, error) {
+
+	trashed, err := r.getTrashedNote(id)
+	if err != nil {
+		slog.Error("failed to read trashed note", "id", id, "error", err)
+		return Note{}, err
+	}
+
+	targetPath, err := r.notePath(trashed.OriginalPath)
+	if err != nil {
+		slog.Error("failed to restore note", "id", id, "error", err)
+		return Note{}, err
+	}
+	if _, err := os.Lstat(targetPath); err == nil {
+		err = fmt.Errorf("%w: %s", ErrAlreadyExists, trashed.OriginalPath)
+		slog.Error("failed to restore note", "id", id, "error", err)
+		return Note{}, err
+	}
+
+	err = os.MkdirAll(filepath.Dir(targetPath), 0755)
+	if err != nil {
+		slog.Error("failed to create note folder", "file", targetPath, "error", err)
+		return Note{}, err
+	}
+
+	err = os.Rename(r.trashPath(id+".md"), targetPath)
+	if err != nil {
+		slog.Error("failed to restore note", "id", id, "error", err)
+		return Note{}, err
+	}
+
+	if err := os.Remove(r.trashPath(id + ".json")); err != nil {
+		slog.Warn("failed to remove trash entry", "id", id, "error", err)
+	}
+
+	return trashed.Note, nil
+}
+
+// PurgeNote - permanently delete a trashed note
+func (r *NoteRepository) PurgeNote(ctx context.Context, id string) error {
+	_, err := awaitContext(ctx, func() (struct{}, error) {
+		return struct{}{}, r.purgeNote(id)
+	})
+	return err
+}
+
+func (r *NoteRepository) purgeNote(id string) error {
+
+	if _, err := r.getTrashedNote(id); err != nil {
+		slog.Error("failed to read trashed note", "id", id, "error", err)
+		return err
+	}
+
+	err := os.Remove(r.trashPath(id + ".md"))
+	if err != nil {
+		slog.Error("failed to purge note", "id", id, "error", err)
+		return err
+	}
+
+	return os.Remove(r.trashPath(id + ".json"))
+}
+
+// EmptyTrash - permanently delete every trashed note deleted at least olderThan ago, returning how many were purged
+func (r *NoteRepository) EmptyTrash(ctx context.Context, olderThan time.Duration) (int, error) {
+	trashed, err := r.GetTrashedNotes(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	cutoff := time.Now().Add(-olderThan)
+	for _, trashedNote := range trashed {
+		if trashedNote.DeletedAt.After(cutoff) {
+			continue
+		}
+
+		if err := r.PurgeNote(ctx, trashedNote.ID); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+
+	slog.Info("emptied trash", "purged", purged)
+	return purged, nil
+}
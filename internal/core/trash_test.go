@@ -0,0 +1,165 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNoteRepositoryTrash(t *testing.T) {
+	t.Run("DeleteNote moves the note to the trash", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		service := NewNoteRepository(tmpDir)
+		note, err := service.CreateEmptyNote(ctx, "projects/doomed")
+		if err != nil {
+			t.Fatalf("CreateEmptyNote failed: %v", err)
+		}
+
+		err = service.DeleteNote(ctx, note)
+		if err != nil {
+			t.Fatalf("DeleteNote failed: %v", err)
+		}
+
+		notes, err := service.GetAllNotes(ctx)
+		if err != nil {
+			t.Fatalf("GetAllNotes failed: %v", err)
+		}
+
+		if len(notes) != 0 {
+			t.Errorf("Expected trashed notes to be hidden, got %d notes", len(notes))
+		}
+
+		trashed, err := service.GetTrashedNotes(ctx)
+		if err != nil {
+			t.Fatalf("GetTrashedNotes failed: %v", err)
+		}
+
+		if len(trashed) != 1 {
+			t.Fatalf("Expected 1 trashed note, got %d", len(trashed))
+		}
+
+		if trashed[0].OriginalPath != "projects/doomed.md" {
+			t.Errorf("Expected original path 'projects/doomed.md', got '%s'", trashed[0].OriginalPath)
+		}
+
+		if trashed[0].Note.FileContent() != "# doomed.md" {
+			t.Errorf("Expected trashed content '# doomed.md', got '%s'", trashed[0].Note.FileContent())
+		}
+	})
+
+	t.Run("RestoreNote puts the note back at its original path", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		service := NewNoteRepository(tmpDir)
+		note, err := service.CreateEmptyNote(ctx, "projects/doomed")
+		if err != nil {
+			t.Fatalf("CreateEmptyNote failed: %v", err)
+		}
+
+		if err := service.DeleteNote(ctx, note); err != nil {
+			t.Fatalf("DeleteNote failed: %v", err)
+		}
+
+		trashed, _ := service.GetTrashedNotes(ctx)
+		restored, err := service.RestoreNote(ctx, trashed[0].ID)
+		if err != nil {
+			t.Fatalf("RestoreNote failed: %v", err)
+		}
+
+		if restored.FilePath() != note.FilePath() {
+			t.Errorf("Expected restored path '%s', got '%s'", note.FilePath(), restored.FilePath())
+		}
+
+		if _, err := os.Stat(note.FilePath()); err != nil {
+			t.Errorf("Expected restored file to exist: %v", err)
+		}
+
+		if trashed, _ := service.GetTrashedNotes(ctx); len(trashed) != 0 {
+			t.Errorf("Expected trash to be empty after restoring, got %d", len(trashed))
+		}
+	})
+
+	t.Run("RestoreNote does not overwrite a note created in its place", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		service := NewNoteRepository(tmpDir)
+		note, _ := service.CreateEmptyNote(ctx, "doomed")
+		if err := service.DeleteNote(ctx, note); err != nil {
+			t.Fatalf("DeleteNote failed: %v", err)
+		}
+
+		err := os.WriteFile(note.FilePath(), []byte("replacement"), 0644)
+		if err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		trashed, _ := service.GetTrashedNotes(ctx)
+		if _, err := service.RestoreNote(ctx, trashed[0].ID); !errors.Is(err, ErrAlreadyExists) {
+			t.Errorf("Expected an already exists error, got %v", err)
+		}
+
+		content, _ := os.ReadFile(note.FilePath())
+		if string(content) != "replacement" {
+			t.Error("Expected the replacement note to be left untouched")
+		}
+	})
+
+	t.Run("PurgeNote and EmptyTrash permanently delete notes", func(t *testing.T) {
+		tmpDir := createTempDir(t)
+		defer removeTempDir(t, tmpDir)
+		ctx := context.Background()
+
+		service := NewNoteRepository(tmpDir)
+		old, _ := service.CreateEmptyNote(ctx, "old")
+		recent, _ := service.CreateEmptyNote(ctx, "recent")
+		purged, _ := service.CreateEmptyNote(ctx, "purged")
+
+		if err := service.moveToTrash(old, time.Now().Add(-48*time.Hour)); err != nil {
+			t.Fatalf("moveToTrash failed: %v", err)
+		}
+		if err := service.DeleteNote(ctx, recent); err != nil {
+			t.Fatalf("DeleteNote failed: %v", err)
+		}
+		if err := service.DeleteNote(ctx, purged); err != nil {
+			t.Fatalf("DeleteNote failed: %v", err)
+		}
+
+		trashed, _ := service.GetTrashedNotes(ctx)
+		for _, trashedNote := range trashed {
+			if trashedNote.Note.Title() == "purged" {
+				if err := service.PurgeNote(ctx, trashedNote.ID); err != nil {
+					t.Fatalf("PurgeNote failed: %v", err)
+				}
+			}
+		}
+
+		count, err := service.EmptyTrash(ctx, 24*time.Hour)
+		if err != nil {
+			t.Fatalf("EmptyTrash failed: %v", err)
+		}
+
+		if count != 1 {
+			t.Errorf("Expected 1 note to be emptied, got %d", count)
+		}
+
+		trashed, _ = service.GetTrashedNotes(ctx)
+		if len(trashed) != 1 || trashed[0].Note.Title() != "recent" {
+			t.Errorf("Expected only the recent note to remain in the trash, got %v", trashed)
+		}
+
+		files, _ := filepath.Glob(filepath.Join(tmpDir, trashDirectory, "*"))
+		if len(files) != 2 {
+			t.Errorf("Expected only the recent note and its entry in the trash directory, got %v", files)
+		}
+	})
+}
@@ -0,0 +1,228 @@
+package core
+
+import (
+	"context"
+	"github.com/fsnotify/fsnotify"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// WatchMode - how the watcher notices changes
+type WatchMode int
+
+const (
+	// WatchAuto - use filesystem notifications, polling when they are not available
+	WatchAuto WatchMode = iota
+	// WatchPoll - always poll, for filesystems that do not deliver notifications such as network drives
+	WatchPoll
+)
+
+// debounceDelay - how long to wait for a burst of filesystem events to settle before rescanning
+const debounceDelay = 100 * time.Millisecond
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher - reports batches of note changes below a vault, found by comparing snapshots of the vault
+// whenever the filesystem reports an event or the poll interval passes
+type Watcher struct {
+	basePath string
+	interval time.Duration
+	changes  chan []NoteChange
+	done     chan struct{}
+	once     sync.Once
+
+	notify   *fsnotify.Watcher
+	snapshot map[string]fileState
+}
+
+// NewWatcher - start watching the vault at basePath, polling every interval when notifications are not used
+func NewWatcher(basePath string, mode WatchMode, interval time.Duration) (*Watcher, error) {
+	snapshot, err := takeSnapshot(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		basePath: basePath,
+		interval: interval,
+		changes:  make(chan []NoteChange),
+		done:     make(chan struct{}),
+		snapshot: snapshot,
+	}
+
+	if mode == WatchAuto {
+		w.notify = w.startNotify()
+	}
+
+	go w.run()
+
+	return w, nil
+}
+
+// Changes - the batches of changes, closed once the watcher is closed
+func (w *Watcher) Changes() <-chan []NoteChange {
+	return w.changes
+}
+
+func (w *Watcher) Close() error {
+	w.once.Do(func() {
+		close(w.done)
+	})
+
+	return nil
+}
+
+// startNotify - subscribe to filesystem notifications for every folder of the vault, nil when unavailable
+func (w *Watcher) startNotify() *fsnotify.Watcher {
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		slog.Warn("filesystem notifications are not available, polling instead", "error", err)
+		return nil
+	}
+
+	if err := w.watchFolders(notify); err != nil {
+		slog.Warn("failed to watch the notes directory, polling instead", "error", err)
+		_ = notify.Close()
+		return nil
+	}
+
+	return notify
+}
+
+func (w *Watcher) watchFolders(notify *fsnotify.Watcher) error {
+	return filepath.WalkDir(w.basePath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() {
+			return nil
+		}
+
+		if filePath != w.basePath && isHidden(entry.Name()) {
+			return fs.SkipDir
+		}
+
+		return notify.Add(filePath)
+	})
+}
+
+func (w *Watcher) run() {
+	defer close(w.changes)
+
+	var events <-chan fsnotify.Event
+	var errors <-chan error
+	if w.notify != nil {
+		defer w.notify.Close()
+		events, errors = w.notify.Events, w.notify.Errors
+	}
+
+	var ticker <-chan time.Time
+	if w.notify == nil {
+		pollTicker := time.NewTicker(w.interval)
+		defer pollTicker.Stop()
+		ticker = pollTicker.C
+	}
+
+	debounce := time.NewTimer(debounceDelay)
+	debounce.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case event := <-events:
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() && !isHidden(info.Name()) {
+					_ = w.watchFolders(w.notify)
+				}
+			}
+			debounce.Reset(debounceDelay)
+		case err := <-errors:
+			slog.Warn("filesystem notification error", "error", err)
+			debounce.Reset(debounceDelay)
+		case <-debounce.C:
+			w.rescan()
+		case <-ticker:
+			w.rescan()
+		}
+	}
+}
+
+// rescan - compare the vault with the last snapshot and publish what changed
+func (w *Watcher) rescan() {
+	snapshot, err := takeSnapshot(w.basePath)
+	if err != nil {
+		slog.Warn("failed to scan the notes directory", "error", err)
+		return
+	}
+
+	var changes []NoteChange
+	for relativePath, state := range snapshot {
+		previous, existed := w.snapshot[relativePath]
+		if existed && previous == state {
+			continue
+		}
+
+		filePath := filepath.Join(w.basePath, filepath.FromSlash(relativePath))
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			slog.Warn("failed to read changed note", "file", filePath, "error", err)
+			delete(snapshot, relativePath)
+			continue
+		}
+
+		kind := NoteUpdated
+		if !existed {
+			kind = NoteCreated
+		}
+
+		changes = append(changes, NoteChange{Kind: kind, Note: newVaultNote(w.basePath, filePath, string(content))})
+	}
+
+	for relativePath := range w.snapshot {
+		if _, exists := snapshot[relativePath]; !exists {
+			filePath := filepath.Join(w.basePath, filepath.FromSlash(relativePath))
+			changes = append(changes, NoteChange{Kind: NoteDeleted, Note: newVaultNote(w.basePath, filePath, "")})
+		}
+	}
+
+	w.snapshot = snapshot
+
+	if len(changes) == 0 {
+		return
+	}
+
+	select {
+	case w.changes <- changes:
+	case <-w.done:
+	}
+}
+
+func takeSnapshot(basePath string) (map[string]fileState, error) {
+	snapshot := map[string]fileState{}
+
+	err := walkNotes(context.Background(), basePath, func(filePath string, entry fs.DirEntry) error {
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(basePath, filePath)
+		if err != nil {
+			return nil
+		}
+
+		snapshot[filepath.ToSlash(relativePath)] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+
+	return snapshot, err
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	for name, mode := range map[string]WatchMode{"auto": WatchAuto, "poll": WatchPoll} {
+		t.Run(name+" reports created, updated and deleted notes", func(t *testing.T) {
+			tempDir := createTempDir(t)
+			defer removeTempDir(t, tempDir)
+
+			existing := filepath.Join(tempDir, "existing.md")
+			if err := os.WriteFile(existing, []byte("old"), 0644); err != nil {
+				t.Fatalf("Failed to write note: %v", err)
+			}
+
+			watcher, err := NewWatcher(tempDir, mode, 20*time.Millisecond)
+			if err != nil {
+				t.Fatalf("NewWatcher failed: %v", err)
+			}
+			defer watcher.Close()
+
+			if err := os.WriteFile(filepath.Join(tempDir, "new.md"), []byte("# New"), 0644); err != nil {
+				t.Fatalf("Failed to write note: %v", err)
+			}
+			change := waitForChange(t, watcher, "new.md")
+			if change.Kind != NoteCreated || change.Note.FileContent() != "# New" {
+				t.Errorf("Expected a created note with its content, got %+v", change)
+			}
+
+			if err := os.WriteFile(existing, []byte("changed content"), 0644); err != nil {
+				t.Fatalf("Failed to write note: %v", err)
+			}
+			change = waitForChange(t, watcher, "existing.md")
+			if change.Kind != NoteUpdated || change.Note.FileContent() != "changed content" {
+				t.Errorf("Expected an updated note with its content, got %+v", change)
+			}
+			if change.Note.Version() != HashContent("changed content") {
+				t.Error("Expected the updated note to carry its version")
+			}
+
+			if err := os.Remove(existing); err != nil {
+				t.Fatalf("Failed to remove note: %v", err)
+			}
+			change = waitForChange(t, watcher, "existing.md")
+			if change.Kind != NoteDeleted {
+				t.Errorf("Expected a deleted note, got %+v", change)
+			}
+		})
+
+		t.Run(name+" reports notes in new folders and ignores hidden files", func(t *testing.T) {
+			tempDir := createTempDir(t)
+			defer removeTempDir(t, tempDir)
+
+			watcher, err := NewWatcher(tempDir, mode, 20*time.Millisecond)
+			if err != nil {
+				t.Fatalf("NewWatcher failed: %v", err)
+			}
+			defer watcher.Close()
+
+			if err := os.WriteFile(filepath.Join(tempDir, ".hidden.md"), []byte("hidden"), 0644); err != nil {
+				t.Fatalf("Failed to write note: %v", err)
+			}
+			if err := os.MkdirAll(filepath.Join(tempDir, "projects"), 0755); err != nil {
+				t.Fatalf("Failed to create folder: %v", err)
+			}
+			time.Sleep(50 * time.Millisecond)
+			if err := os.WriteFile(filepath.Join(tempDir, "projects", "alpha.md"), []byte("alpha"), 0644); err != nil {
+				t.Fatalf("Failed to write note: %v", err)
+			}
+
+			change := waitForChange(t, watcher, "projects/alpha.md")
+			if change.Kind != NoteCreated || change.Note.Folder() != "projects" {
+				t.Errorf("Expected a created note in projects, got %+v", change)
+			}
+		})
+	}
+
+	t.Run("closing the watcher closes the changes channel", func(t *testing.T) {
+		tempDir := createTempDir(t)
+		defer removeTempDir(t, tempDir)
+
+		watcher, err := NewWatcher(tempDir, WatchPoll, 20*time.Millisecond)
+		if err != nil {
+			t.Fatalf("NewWatcher failed: %v", err)
+		}
+		watcher.Close()
+
+		select {
+		case _, ok := <-watcher.Changes():
+			if ok {
+				t.Error("Expected no changes after closing")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected the changes channel to be closed")
+		}
+	})
+}
+
+// waitForChange - wait for a change to the note at relativePath, failing on anything hidden
+func waitForChange(t *testing.T, watcher *Watcher, relativePath string) NoteChange {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case changes := <-watcher.Changes():
+			for _, change := range changes {
+				if isHidden(filepath.Base(change.Note.FilePath())) {
+					t.Errorf("Expected hidden files to be ignored, got %s", change.Note.RelativePath())
+				}
+				if change.Note.RelativePath() == relativePath {
+					return change
+				}
+			}
+		case <-timeout:
+			t.Fatalf("Timed out waiting for a change to %s", relativePath)
+			return NoteChange{}
+		}
+	}
+}
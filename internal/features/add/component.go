@@ -4,17 +4,24 @@ import (
 	"elephant/internal/core"
 	"elephant/internal/features/commands"
 	"elephant/internal/theme"
+	"errors"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"log/slog"
+	"strings"
 )
 
+// createNoteFailedMsg - the note could not be created and the add state stays open to show why
+type createNoteFailedMsg struct{ err error }
+
 type Component struct {
 	width, height int
 	textInput     textinput.Model
 	keys          componentKeyMap
 	repository    core.Repository
+	creating      commands.Operation
+	err           error
 }
 
 func NewComponent(repository core.Repository) Component {
@@ -41,10 +48,17 @@ func (ac *Component) BackgroundUpdate(msg tea.Msg) tea.Cmd {
 		ac.width = msg.Width - h
 		ac.height = msg.Height - v
 
+	case commands.AddNoteMsg:
+		ac.err = nil
+
 	case commands.CreateNoteMsg:
+		ac.err = nil
 		return func() tea.Msg {
 			return commands.ViewNoteMsg{Note: msg.Note}
 		}
+
+	case createNoteFailedMsg:
+		ac.err = msg.err
 	}
 
 	return nil
@@ -56,17 +70,22 @@ func (ac *Component) ForegroundUpdate(msg tea.Msg) tea.Cmd {
 		case key.Matches(keyMsg, ac.keys.createNote):
 			filename := ac.textInput.Value()
 			if filename != "" {
+				ctx := ac.creating.Start()
 				return func() tea.Msg {
-					note, err := ac.repository.CreateEmptyNote(filename)
+					note, err := ac.repository.CreateEmptyNote(ctx, filename)
+					if commands.IsSuperseded(err) {
+						return nil
+					}
 					if err != nil {
 						slog.Error("failed to create note", "error", err)
-						return nil
+						return createNoteFailedMsg{err: err}
 					}
 
 					return commands.CreateNoteMsg{Note: note}
 				}
 			}
 		case key.Matches(keyMsg, ac.keys.quitAddNote):
+			ac.creating.Cancel()
 			return func() tea.Msg {
 				return commands.QuitAddNoteMsg{}
 			}
@@ -80,5 +99,22 @@ func (ac *Component) ForegroundUpdate(msg tea.Msg) tea.Cmd {
 
 func (ac *Component) View() string {
 	content := "Create New Note\n\n" + ac.textInput.View() + "\n\nPress Enter to create, Esc to cancel"
+	if ac.err != nil {
+		content += "\n\n" + describeError(ac.err)
+	}
+
 	return theme.Style.Width(ac.width).Height(ac.height).Render(content)
 }
+
+func describeError(err error) string {
+	switch {
+	case errors.Is(err, core.ErrAlreadyExists):
+		return "A note with this name already exists."
+	case errors.Is(err, core.ErrInvalidName):
+		return "Invalid note name: " + strings.TrimPrefix(err.Error(), core.ErrInvalidName.Error()+": ")
+	case errors.Is(err, core.ErrOutsideVault):
+		return "Notes can only be created inside the notes directory."
+	default:
+		return "Could not create note: " + commands.DescribeError(err)
+	}
+}
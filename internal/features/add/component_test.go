@@ -5,49 +5,15 @@ import (
 	"elephant/internal/features/commands"
 	"errors"
 	tea "github.com/charmbracelet/bubbletea"
+	"strings"
 	"testing"
 )
 
-type mockRepository struct {
-	notes []core.Note
-	err   error
-}
-
-func (m *mockRepository) GetAllNotes() ([]core.Note, error) {
-	if m.err != nil {
-		return nil, m.err
-	}
-	return m.notes, nil
-}
-
-func (m *mockRepository) GetNoteByTitle(title string) (core.Note, error) {
-	if m.err != nil {
-		return core.Note{}, m.err
-	}
-	for _, note := range m.notes {
-		if note.Title() == title {
-			return note, nil
-		}
-	}
-	return core.Note{}, errors.New("note not found")
-}
-
-func (m *mockRepository) SaveNote(_ core.Note) error {
-	return m.err
-}
-
-func (m *mockRepository) CreateEmptyNote(filename string) (core.Note, error) {
-	if m.err != nil {
-		return core.Note{}, m.err
-	}
-	return core.NewNote(filename+".md", ""), nil
-}
-
 func TestNewAddComponent(t *testing.T) {
-	mockRepo := &mockRepository{}
-	component := NewComponent(mockRepo)
+	repo := core.NewMemoryRepository()
+	component := NewComponent(repo)
 
-	if component.repository != mockRepo {
+	if component.repository != repo {
 		t.Error("Expected repository to be set correctly")
 	}
 
@@ -65,8 +31,8 @@ func TestNewAddComponent(t *testing.T) {
 }
 
 func TestAddComponentInit(t *testing.T) {
-	mockRepo := &mockRepository{}
-	component := NewComponent(mockRepo)
+	repo := core.NewMemoryRepository()
+	component := NewComponent(repo)
 
 	cmd := component.Init()
 
@@ -77,8 +43,8 @@ func TestAddComponentInit(t *testing.T) {
 
 func TestAddComponentBackgroundUpdate(t *testing.T) {
 	t.Run("CreateNoteMsg returns ViewNoteMsg", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		note := core.NewNote("testnote.md", "")
 		msg := commands.CreateNoteMsg{Note: note}
@@ -106,8 +72,8 @@ func TestAddComponentBackgroundUpdate(t *testing.T) {
 
 func TestAddComponentForegroundUpdate(t *testing.T) {
 	t.Run("Escape key creates QuitAddNoteMsg", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		keyMsg := tea.KeyMsg{Type: tea.KeyEsc}
 		cmd := component.ForegroundUpdate(keyMsg)
@@ -124,8 +90,8 @@ func TestAddComponentForegroundUpdate(t *testing.T) {
 	})
 
 	t.Run("Enter key with filename creates note and CreateNoteMsg", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		component.textInput.SetValue("mynote")
 
@@ -152,10 +118,9 @@ func TestAddComponentForegroundUpdate(t *testing.T) {
 	})
 
 	t.Run("Enter key with filename handles repository error gracefully", func(t *testing.T) {
-		mockRepo := &mockRepository{
-			err: errors.New("repository error"),
-		}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		repo.FailWith(errors.New("repository error"))
+		component := NewComponent(repo)
 
 		component.textInput.SetValue("mynote")
 
@@ -167,14 +132,40 @@ func TestAddComponentForegroundUpdate(t *testing.T) {
 		}
 
 		msg := cmd()
-		if msg != nil {
-			t.Error("Expected nil result when repository fails")
+		if _, ok := msg.(createNoteFailedMsg); !ok {
+			t.Fatal("Expected createNoteFailedMsg when repository fails")
+		}
+
+		component.BackgroundUpdate(msg)
+
+		if !strings.Contains(component.View(), "repository error") {
+			t.Error("Expected the repository error to be shown")
+		}
+	})
+
+	t.Run("Enter key with an existing filename shows a friendly error", func(t *testing.T) {
+		repo := core.NewMemoryRepository(core.NewNote("mynote.md", "# mynote.md"))
+		component := NewComponent(repo)
+
+		component.textInput.SetValue("mynote")
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEnter})
+		component.BackgroundUpdate(cmd())
+
+		if !strings.Contains(component.View(), "already exists") {
+			t.Error("Expected the already exists error to be shown")
+		}
+
+		component.BackgroundUpdate(commands.AddNoteMsg{})
+
+		if component.err != nil {
+			t.Error("Expected the error to be cleared when adding a note again")
 		}
 	})
 
 	t.Run("Enter key with empty filename returns nil", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		component.textInput.SetValue("")
 
@@ -0,0 +1,227 @@
+package agenda
+
+import (
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	"elephant/internal/theme"
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// agendaMsg - the open tasks of the vault with a due date, grouped by when they are due
+type agendaMsg struct{ sections []core.AgendaSection }
+
+// taskCompletedMsg - task was checked and its note saved
+type taskCompletedMsg struct{ task core.Task }
+
+// agendaFailedMsg - an agenda operation failed
+type agendaFailedMsg struct{ err error }
+
+// agendaItem - a task shown in the agenda, with the group it is in
+type agendaItem struct {
+	core.Task
+	group core.AgendaGroup
+}
+
+func (a agendaItem) Title() string {
+	return "[ ] " + a.Label()
+}
+
+// Description - the group and due date of the task, its priority and recurrence when it has them, and where it is
+func (a agendaItem) Description() string {
+	details := []string{a.group.String(), "due " + a.Due.Format("Mon Jan 2")}
+	if a.Priority != core.NoPriority {
+		details = append(details, a.Priority.String())
+	}
+	if a.Every.Recurs() {
+		details = append(details, "every "+a.Every.String())
+	}
+	details = append(details, fmt.Sprintf("%s:%d", a.Note.RelativePath(), a.Line))
+
+	return strings.Join(details, " · ")
+}
+
+// FilterValue - the text of the task with its group and note, so filtering finds tasks by any of them
+func (a agendaItem) FilterValue() string {
+	return strings.Join([]string{a.Text, a.group.String(), a.Note.RelativePath()}, " ")
+}
+
+type Component struct {
+	width, height int
+	list          list.Model
+	keys          componentKeyMap
+	repository    core.Repository
+
+	// open - whether the screen is shown, when changes to the vault reload the agenda
+	open bool
+
+	loading   commands.Operation
+	operation commands.Operation
+}
+
+func NewComponent(repository core.Repository) Component {
+	keys := newComponentKeyMap()
+	itemList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	itemList.Title = "Agenda"
+	itemList.AdditionalFullHelpKeys = keys.getListOfBindings
+
+	return Component{
+		width:      itemList.Width(),
+		height:     itemList.Height(),
+		list:       itemList,
+		keys:       keys,
+		repository: repository,
+	}
+}
+
+func (ac *Component) Init() tea.Cmd {
+	return nil
+}
+
+func (ac *Component) loadAgenda() tea.Cmd {
+	ctx := ac.loading.Start()
+
+	return func() tea.Msg {
+		notes, err := ac.repository.GetAllNotes(ctx)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to load agenda", "error", err)
+			return agendaFailedMsg{err: err}
+		}
+
+		return agendaMsg{sections: core.Agenda(core.CollectTasks(notes), core.Today(time.Now()))}
+	}
+}
+
+func (ac *Component) BackgroundUpdate(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := theme.Style.GetFrameSize()
+
+		ac.width = msg.Width - h
+		ac.height = msg.Height - v
+
+		ac.list.SetSize(ac.width, ac.height)
+
+	case commands.ShowAgendaMsg:
+		ac.open = true
+		return ac.loadAgenda()
+
+	case commands.QuitAgendaMsg:
+		ac.open = false
+
+	case commands.NotesChangedMsg:
+		if ac.open {
+			return ac.loadAgenda()
+		}
+
+	case agendaMsg:
+		return ac.showAgenda(msg.sections)
+
+	case taskCompletedMsg:
+		status := "Completed " + msg.task.Label()
+		if msg.task.Every.Recurs() {
+			status += ", next one added"
+		}
+		// the next occurrence of a recurring task and the lines below it moved, so the agenda is read again
+		return tea.Batch(ac.loadAgenda(), ac.list.NewStatusMessage(status))
+
+	case agendaFailedMsg:
+		return ac.list.NewStatusMessage("Error: " + commands.DescribeError(msg.err))
+	}
+
+	return nil
+}
+
+// showAgenda - list the tasks of sections in order, with how many each group holds in the title
+func (ac *Component) showAgenda(sections []core.AgendaSection) tea.Cmd {
+	var items []list.Item
+	var counts []string
+	for _, section := range sections {
+		for _, task := range section.Tasks {
+			items = append(items, agendaItem{Task: task, group: section.Group})
+		}
+		counts = append(counts, fmt.Sprintf("%d %s", len(section.Tasks), strings.ToLower(section.Group.String())))
+	}
+
+	ac.list.Title = "Agenda"
+	if len(counts) > 0 {
+		ac.list.Title += ": " + strings.Join(counts, ", ")
+	}
+
+	return ac.list.SetItems(items)
+}
+
+func (ac *Component) ForegroundUpdate(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && ac.list.FilterState() != list.Filtering {
+		selectedItem, selected := ac.list.SelectedItem().(agendaItem)
+
+		switch {
+		case key.Matches(keyMsg, ac.keys.completeTask):
+			if selected {
+				return ac.completeTask(selectedItem.Task)
+			}
+			return nil
+		case key.Matches(keyMsg, ac.keys.openTask):
+			if selected {
+				return ac.openTask(selectedItem.Task)
+			}
+			return nil
+		case key.Matches(keyMsg, ac.keys.quitAgenda) && ac.list.FilterState() == list.Unfiltered:
+			ac.loading.Cancel()
+			return func() tea.Msg {
+				return commands.QuitAgendaMsg{}
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	ac.list, cmd = ac.list.Update(msg)
+	return cmd
+}
+
+func (ac *Component) completeTask(task core.Task) tea.Cmd {
+	ctx := ac.operation.Start()
+
+	return func() tea.Msg {
+		completed, err := core.ToggleTask(ctx, ac.repository, task)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to complete task", "error", err)
+			return agendaFailedMsg{err: err}
+		}
+
+		return taskCompletedMsg{task: completed}
+	}
+}
+
+// openTask - view the note of task, scrolled to the task
+func (ac *Component) openTask(task core.Task) tea.Cmd {
+	ctx := ac.operation.Start()
+
+	return func() tea.Msg {
+		loaded, err := core.LoadNote(ctx, ac.repository, task.Note)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to load note", "file", task.Note.FilePath(), "error", err)
+			return agendaFailedMsg{err: err}
+		}
+
+		return commands.ViewNoteMsg{Note: loaded, Match: task.Text}
+	}
+}
+
+func (ac *Component) View() string {
+	return theme.Style.Width(ac.width).Height(ac.height).Render(ac.list.View())
+}
@@ -0,0 +1,77 @@
+package agenda
+
+import (
+	"context"
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	tea "github.com/charmbracelet/bubbletea"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openAgenda(t *testing.T, repository core.Repository) Component {
+	t.Helper()
+
+	component := NewComponent(repository)
+	component.BackgroundUpdate(tea.WindowSizeMsg{Width: 100, Height: 30})
+
+	cmd := component.BackgroundUpdate(commands.ShowAgendaMsg{})
+	if cmd == nil {
+		t.Fatal("Expected ShowAgendaMsg to load the agenda")
+	}
+	component.BackgroundUpdate(cmd())
+
+	return component
+}
+
+func TestAgendaComponentGroups(t *testing.T) {
+	today := core.Today(time.Now())
+	due := func(days int) string { return "due:" + today.AddDate(0, 0, days).Format("2006-01-02") }
+
+	component := openAgenda(t, core.NewMemoryRepository(
+		core.NewNote("home.md", "- [ ] Pay rent "+due(-2)+"\n- [ ] Call mum !high "+due(0)+"\n- [x] Done "+due(-1)),
+		core.NewNote("work.md", "- [ ] Plan trip "+due(30)+"\n- [ ] Email "+due(0)+"\n- [ ] Someday"),
+	))
+
+	var texts []string
+	for _, item := range component.list.Items() {
+		texts = append(texts, item.(agendaItem).group.String()+": "+item.(agendaItem).Label())
+	}
+	if expected := "Overdue: Pay rent,Today: Call mum,Today: Email,Later: Plan trip"; strings.Join(texts, ",") != expected {
+		t.Errorf("Expected %s, got %v", expected, texts)
+	}
+
+	if view := component.View(); !strings.Contains(view, "1 overdue, 2 today, 1 later") {
+		t.Errorf("Expected the title to count the tasks of every group, got %q", view)
+	}
+}
+
+func TestAgendaComponentCompleteRecurring(t *testing.T) {
+	today := core.Today(time.Now())
+	due := today.Format("2006-01-02")
+	next := today.AddDate(0, 0, 7).Format("2006-01-02")
+
+	repository := core.NewMemoryRepository(core.NewNote("home.md", "- [ ] Water plants due:"+due+" every:week\n"))
+	component := openAgenda(t, repository)
+
+	cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if cmd == nil {
+		t.Fatal("Expected x to complete the selected task")
+	}
+	component.BackgroundUpdate(cmd())
+
+	home, _ := repository.GetNoteByTitle(context.Background(), "home")
+	expected := "- [x] Water plants due:" + due + " every:week\n- [ ] Water plants due:" + next + " every:week\n"
+	if home.FileContent() != expected {
+		t.Errorf("Expected the next occurrence below the completed task, got %q", home.FileContent())
+	}
+
+	cmd = component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected enter to open the note of the task")
+	}
+	if viewMsg, ok := cmd().(commands.ViewNoteMsg); !ok || viewMsg.Note.Title() != "home" {
+		t.Errorf("Expected the note opened at the task, got %+v", viewMsg)
+	}
+}
@@ -0,0 +1,36 @@
+package agenda
+
+import "github.com/charmbracelet/bubbles/key"
+
+type componentKeyMap struct {
+	completeTask key.Binding
+	openTask     key.Binding
+	quitAgenda   key.Binding
+}
+
+func newComponentKeyMap() componentKeyMap {
+	km := componentKeyMap{
+		completeTask: key.NewBinding(
+			key.WithKeys(" ", "x"),
+			key.WithHelp("space/x", "complete task"),
+		),
+		openTask: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "open note at task"),
+		),
+		quitAgenda: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back to list note"),
+		),
+	}
+
+	return km
+}
+
+func (a componentKeyMap) getListOfBindings() []key.Binding {
+	return []key.Binding{
+		a.completeTask,
+		a.openTask,
+		a.quitAgenda,
+	}
+}
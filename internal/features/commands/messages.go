@@ -2,11 +2,17 @@ package commands
 
 import "elephant/internal/core"
 
-// ListNotesMsg - show the list of notes in the base path
-type ListNotesMsg struct{ Notes []core.Note }
+// ListNotesMsg - show the list of notes in the base path, or why they could not be loaded
+type ListNotesMsg struct {
+	Notes []core.Note
+	Err   error
+}
 
-// ViewNoteMsg - select a single note to view and edit
-type ViewNoteMsg struct{ Note core.Note }
+// ViewNoteMsg - select a single note to view and edit, scrolled to the first line holding Match when it is set
+type ViewNoteMsg struct {
+	Note  core.Note
+	Match string
+}
 
 // QuitViewNoteMsg - quit the view note state
 type QuitViewNoteMsg struct{}
@@ -25,3 +31,94 @@ type QuitAddNoteMsg struct{}
 
 // CreateNoteMsg - create a new note with the given filename
 type CreateNoteMsg struct{ Note core.Note }
+
+// ListTagsMsg - show the tags used across the notes in the base path, or why they could not be loaded
+type ListTagsMsg struct {
+	Tags []core.Tag
+	Err  error
+}
+
+// ListTaggedNotesMsg - show only the notes with the given tag, or why they could not be loaded
+type ListTaggedNotesMsg struct {
+	Tag   string
+	Notes []core.Note
+	Err   error
+}
+
+// DeleteNotePromptMsg - ask for confirmation before deleting the given note
+type DeleteNotePromptMsg struct{ Note core.Note }
+
+// RenameNotePromptMsg - ask for a new name for the given note
+type RenameNotePromptMsg struct{ Note core.Note }
+
+// MoveNotePromptMsg - ask for the folder to move the given note into
+type MoveNotePromptMsg struct{ Note core.Note }
+
+// QuitDialogMsg - quit the dialog state without changing anything
+type QuitDialogMsg struct{}
+
+// DeleteNoteMsg - the given note was deleted
+type DeleteNoteMsg struct{ Note core.Note }
+
+// RenameNoteMsg - the old note was renamed to the new note
+type RenameNoteMsg struct{ OldNote, Note core.Note }
+
+// MoveNoteMsg - the old note was moved to the new note
+type MoveNoteMsg struct{ OldNote, Note core.Note }
+
+// ShowTrashMsg - enter the trash state
+type ShowTrashMsg struct{}
+
+// QuitTrashMsg - quit the trash state
+type QuitTrashMsg struct{}
+
+// ListTrashMsg - show the notes in the trash
+type ListTrashMsg struct{ Notes []core.TrashedNote }
+
+// RestoreNoteMsg - the given note was restored from the trash
+type RestoreNoteMsg struct{ Note core.Note }
+
+// ConflictMsg - saving mine found theirs changed underneath, enter the conflict state to resolve it
+type ConflictMsg struct{ Mine, Theirs core.Note }
+
+// ResumeEditNoteMsg - go back to editing the given note
+type ResumeEditNoteMsg struct{ Note core.Note }
+
+// NotesChangedMsg - notes were created, updated, renamed or deleted, through the application or outside of it
+type NotesChangedMsg struct{ Changes []core.NoteChange }
+
+// OpenVaultMsg - open a vault that another instance holds the lock of, read-only or shared through per-note locks
+type OpenVaultMsg struct{ ReadOnly bool }
+
+// ShowHistoryMsg - enter the history state for the given note
+type ShowHistoryMsg struct{ Note core.Note }
+
+// QuitHistoryMsg - quit the history state back to viewing the note
+type QuitHistoryMsg struct{}
+
+// ListRevisionsMsg - show the recorded revisions of the given note
+type ListRevisionsMsg struct {
+	Note      core.Note
+	Revisions []core.Revision
+}
+
+// RestoreRevisionMsg - an earlier revision was restored as the current version of the given note
+type RestoreRevisionMsg struct{ Note core.Note }
+
+// ShowSearchMsg - enter the search state
+type ShowSearchMsg struct{}
+
+// QuitSearchMsg - quit the search state
+type QuitSearchMsg struct{}
+
+// ShowTasksMsg - enter the tasks state
+type ShowTasksMsg struct{}
+
+// QuitTasksMsg - quit the tasks state
+type QuitTasksMsg struct{}
+
+// ShowAgendaMsg - enter the agenda state
+type ShowAgendaMsg struct{}
+
+// QuitAgendaMsg - quit the agenda state
+type QuitAgendaMsg struct{}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"context"
+	"elephant/internal/core"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// OperationTimeout - how long a repository call made from the UI may take before it is reported as failed
+var OperationTimeout = 10 * time.Second
+
+// Operation - the in-flight repository call of a component, cancelled when a newer call supersedes it
+type Operation struct {
+	cancel context.CancelFunc
+}
+
+// Start - cancel the previous call and return the context for the next one, to be created outside the tea.Cmd
+func (o *Operation) Start() context.Context {
+	o.Cancel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	o.cancel = cancel
+
+	return ctx
+}
+
+// StartWithoutTimeout - Start for a long running load, which only ends when cancelled, such as the streamed load of
+// a whole vault that waits on the UI to take every batch
+func (o *Operation) StartWithoutTimeout() context.Context {
+	o.Cancel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	o.cancel = cancel
+
+	return ctx
+}
+
+// Cancel - cancel the in-flight call, if there is one
+func (o *Operation) Cancel() {
+	if o.cancel != nil {
+		o.cancel()
+		o.cancel = nil
+	}
+}
+
+// IsSuperseded - whether the call failed only because a newer call or leaving the screen cancelled it
+func IsSuperseded(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
+
+// DescribeError - a user facing description of a failed repository call
+func DescribeError(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "The notes directory did not respond in time, please try again."
+	}
+
+	if errors.Is(err, core.ErrReadOnly) {
+		return "The vault is open read-only because another instance is using it."
+	}
+
+	var locked *core.LockedError
+	if errors.As(err, &locked) {
+		return fmt.Sprintf("The note is open in another instance (pid %d on %s).", locked.Owner.PID, locked.Owner.Host)
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"elephant/internal/core"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ListenForChanges - wait for the next batch of changes from a change feed subscription and deliver it as a
+// NotesChangedMsg, to be issued again after every batch; nothing is delivered once the subscription ends
+func ListenForChanges(changes <-chan []core.NoteChange) tea.Cmd {
+	if changes == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		batch, ok := <-changes
+		if !ok {
+			return nil
+		}
+		return NotesChangedMsg{Changes: batch}
+	}
+}
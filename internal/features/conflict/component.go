@@ -0,0 +1,144 @@
+package conflict
+
+import (
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	"elephant/internal/theme"
+	"errors"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"log/slog"
+	"strings"
+)
+
+const header = "Conflict: this note changed on disk while you were editing it\n\n" +
+	"m keep mine • t keep theirs • 3 three-way merge • esc back to editing\n\n" +
+	"Changes from theirs to mine:\n\n"
+
+// saveMineFailedMsg - keeping mine failed and the conflict state stays open to show why
+type saveMineFailedMsg struct{ err error }
+
+type Component struct {
+	width, height int
+	diff          viewport.Model
+	keys          componentKeyMap
+	repository    core.Repository
+
+	mine, theirs core.Note
+	saving       commands.Operation
+	err          error
+}
+
+func NewComponent(repository core.Repository) Component {
+	keys := newComponentKeyMap()
+	vp := viewport.New(0, 0)
+
+	return Component{
+		width:      vp.Width,
+		height:     vp.Height,
+		diff:       vp,
+		keys:       keys,
+		repository: repository,
+	}
+}
+
+func (cc *Component) Init() tea.Cmd {
+	return nil
+}
+
+func (cc *Component) BackgroundUpdate(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := theme.Style.GetFrameSize()
+
+		cc.width = msg.Width - h
+		cc.height = msg.Height - v
+
+		cc.diff.Width = cc.width
+		cc.diff.Height = cc.height - strings.Count(header, "\n") - 2
+
+	case commands.ConflictMsg:
+		cc.mine = msg.Mine
+		cc.theirs = msg.Theirs
+		cc.err = nil
+		cc.diff.SetContent(theme.RenderDiff(core.DiffLines(msg.Theirs.FileContent(), msg.Mine.FileContent())))
+		cc.diff.GotoTop()
+
+	case saveMineFailedMsg:
+		cc.err = msg.err
+	}
+
+	return nil
+}
+
+func (cc *Component) ForegroundUpdate(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(keyMsg, cc.keys.keepMine):
+			return cc.keepMine()
+		case key.Matches(keyMsg, cc.keys.keepTheirs):
+			cc.saving.Cancel()
+			theirs := cc.theirs
+			return func() tea.Msg {
+				return commands.QuitEditNoteMsg{Note: theirs}
+			}
+		case key.Matches(keyMsg, cc.keys.merge):
+			cc.saving.Cancel()
+			merged, _ := core.Merge3(cc.mine.BaseContent(), cc.mine.FileContent(), cc.theirs.FileContent())
+			note := cc.mine.WithContent(merged).Rebase(cc.theirs)
+			return func() tea.Msg {
+				return commands.ResumeEditNoteMsg{Note: note}
+			}
+		case key.Matches(keyMsg, cc.keys.quitConflict):
+			cc.saving.Cancel()
+			mine := cc.mine
+			return func() tea.Msg {
+				return commands.ResumeEditNoteMsg{Note: mine}
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	cc.diff, cmd = cc.diff.Update(msg)
+	return cmd
+}
+
+// keepMine - save mine over theirs, which may conflict again if the note keeps changing on disk, recreating the note
+// when it was deleted meanwhile
+func (cc *Component) keepMine() tea.Cmd {
+	note := cc.mine.Rebase(cc.theirs)
+	ctx := cc.saving.Start()
+
+	return func() tea.Msg {
+		saved, err := cc.repository.SaveNote(ctx, note)
+		if errors.Is(err, core.ErrNotFound) {
+			// theirs was deleted since, keeping mine brings the note back as a new one
+			saved, err = cc.repository.SaveNote(ctx, note.Rebase(core.Note{}))
+		}
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+
+		var conflict *core.ConflictError
+		if errors.As(err, &conflict) {
+			return commands.ConflictMsg{Mine: conflict.Mine, Theirs: conflict.Theirs}
+		}
+
+		if err != nil {
+			slog.Error("failed to save note", "error", err)
+			return saveMineFailedMsg{err: err}
+		}
+
+		return commands.QuitEditNoteMsg{Note: saved}
+	}
+}
+
+func (cc *Component) View() string {
+	content := header + cc.diff.View()
+	if cc.err != nil {
+		content = "Could not save note: " + commands.DescribeError(cc.err) + "\n\n" + content
+	}
+
+	return theme.Style.Width(cc.width).Height(cc.height).Render(content)
+}
@@ -0,0 +1,144 @@
+package conflict
+
+import (
+	"context"
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	"errors"
+	tea "github.com/charmbracelet/bubbletea"
+	"strings"
+	"testing"
+)
+
+func TestNewConflictComponent(t *testing.T) {
+	repo := core.NewMemoryRepository()
+	component := NewComponent(repo)
+
+	if component.repository != repo {
+		t.Error("Expected repository to be set correctly")
+	}
+}
+
+func newConflictMsg() commands.ConflictMsg {
+	base := core.NewNote("shared.md", "a\nb\nc")
+	mine := base.WithContent("A\nb\nc")
+	theirs := core.NewNote("shared.md", "a\nb\nC")
+	return commands.ConflictMsg{Mine: mine, Theirs: theirs}
+}
+
+func TestConflictComponentBackgroundUpdate(t *testing.T) {
+	t.Run("ConflictMsg shows the diff from theirs to mine", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+		component.BackgroundUpdate(tea.WindowSizeMsg{Width: 80, Height: 40})
+
+		component.BackgroundUpdate(newConflictMsg())
+
+		view := component.View()
+		if !strings.Contains(view, "+ A") || !strings.Contains(view, "- a") {
+			t.Errorf("Expected the diff to be shown, got '%s'", view)
+		}
+	})
+}
+
+func TestConflictComponentForegroundUpdate(t *testing.T) {
+	t.Run("'m' key saves mine", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+		component.BackgroundUpdate(newConflictMsg())
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for 'm' key")
+		}
+
+		quitMsg, ok := cmd().(commands.QuitEditNoteMsg)
+		if !ok {
+			t.Fatal("Expected QuitEditNoteMsg from 'm' key command")
+		}
+
+		if quitMsg.Note.FileContent() != "A\nb\nc" {
+			t.Errorf("Expected mine to be saved, got '%s'", quitMsg.Note.FileContent())
+		}
+	})
+
+	t.Run("'t' key keeps theirs", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+		component.BackgroundUpdate(newConflictMsg())
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for 't' key")
+		}
+
+		quitMsg, ok := cmd().(commands.QuitEditNoteMsg)
+		if !ok || quitMsg.Note.FileContent() != "a\nb\nC" {
+			t.Error("Expected QuitEditNoteMsg with theirs from 't' key command")
+		}
+	})
+
+	t.Run("'3' key resumes editing the merged note", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+		component.BackgroundUpdate(newConflictMsg())
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'3'}})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for '3' key")
+		}
+
+		resumeMsg, ok := cmd().(commands.ResumeEditNoteMsg)
+		if !ok {
+			t.Fatal("Expected ResumeEditNoteMsg from '3' key command")
+		}
+
+		if !strings.Contains(resumeMsg.Note.FileContent(), "<<<<<<< mine") {
+			t.Errorf("Expected conflict markers for notes that were never stored, got '%s'", resumeMsg.Note.FileContent())
+		}
+	})
+
+	t.Run("Escape key resumes editing mine", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+		component.BackgroundUpdate(newConflictMsg())
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEsc})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for Escape key")
+		}
+
+		resumeMsg, ok := cmd().(commands.ResumeEditNoteMsg)
+		if !ok || resumeMsg.Note.FileContent() != "A\nb\nc" {
+			t.Error("Expected ResumeEditNoteMsg with mine from Escape key command")
+		}
+	})
+
+	t.Run("'m' key recreates mine when theirs was deleted since", func(t *testing.T) {
+		repo := core.NewMemoryRepository(core.NewNote("shared.md", "a\nb\nC"))
+		theirs, _ := repo.GetNoteByTitle(context.Background(), "shared")
+		_ = repo.DeleteNote(context.Background(), theirs)
+
+		component := NewComponent(repo)
+		component.BackgroundUpdate(commands.ConflictMsg{Mine: theirs.WithContent("A\nb\nc"), Theirs: theirs})
+
+		quitMsg, ok := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})().(commands.QuitEditNoteMsg)
+		if !ok || quitMsg.Note.FileContent() != "A\nb\nc" {
+			t.Errorf("Expected mine to be saved as a new note, got %+v", quitMsg)
+		}
+	})
+
+	t.Run("save errors are shown", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		repo.FailWith(errors.New("disk full"))
+		component := NewComponent(repo)
+		component.BackgroundUpdate(newConflictMsg())
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+		component.BackgroundUpdate(cmd())
+
+		if !strings.Contains(component.View(), "disk full") {
+			t.Error("Expected the save error to be shown")
+		}
+	})
+}
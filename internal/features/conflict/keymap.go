@@ -0,0 +1,33 @@
+package conflict
+
+import "github.com/charmbracelet/bubbles/key"
+
+type componentKeyMap struct {
+	keepMine     key.Binding
+	keepTheirs   key.Binding
+	merge        key.Binding
+	quitConflict key.Binding
+}
+
+func newComponentKeyMap() componentKeyMap {
+	km := componentKeyMap{
+		keepMine: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "keep mine"),
+		),
+		keepTheirs: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "keep theirs"),
+		),
+		merge: key.NewBinding(
+			key.WithKeys("3"),
+			key.WithHelp("3", "three-way merge"),
+		),
+		quitConflict: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back to edit note"),
+		),
+	}
+
+	return km
+}
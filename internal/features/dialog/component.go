@@ -0,0 +1,282 @@
+package dialog
+
+import (
+	"context"
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	"elephant/internal/theme"
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"log/slog"
+	"path"
+	"strings"
+)
+
+type mode int
+
+const (
+	deleteMode mode = iota
+	renameMode
+	moveMode
+	previewMode
+)
+
+// operationFailedMsg - a dialog operation failed and the dialog stays open to show why
+type operationFailedMsg struct{ err error }
+
+// renamePlannedMsg - the links a rename or move rewrites, shown for confirmation before any is applied
+type renamePlannedMsg struct{ plan core.RenamePlan }
+
+type Component struct {
+	width, height int
+	textInput     textinput.Model
+	keys          componentKeyMap
+	repository    core.Repository
+
+	mode        mode
+	currentNote core.Note
+	operation   commands.Operation
+	err         error
+
+	// plan, planMode, previewOffset - the rename or move being previewed, the mode it was asked for in and the
+	// first edit shown
+	plan          core.RenamePlan
+	planMode      mode
+	previewOffset int
+}
+
+func NewComponent(repository core.Repository) Component {
+	keys := newComponentKeyMap()
+	ti := textinput.New()
+	ti.Focus()
+
+	return Component{
+		textInput:  ti,
+		keys:       keys,
+		repository: repository,
+	}
+}
+
+func (dc *Component) Init() tea.Cmd {
+	return nil
+}
+
+func (dc *Component) BackgroundUpdate(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := theme.Style.GetFrameSize()
+		dc.width = msg.Width - h
+		dc.height = msg.Height - v
+
+	case commands.DeleteNotePromptMsg:
+		dc.open(deleteMode, msg.Note, "")
+
+	case commands.RenameNotePromptMsg:
+		// the file name rather than the title, which front matter may set to anything
+		dc.open(renameMode, msg.Note, strings.TrimSuffix(path.Base(msg.Note.RelativePath()), ".md"))
+		dc.textInput.Placeholder = "Enter new note filename (without .md)"
+
+	case commands.MoveNotePromptMsg:
+		dc.open(moveMode, msg.Note, msg.Note.Folder())
+		dc.textInput.Placeholder = "Enter folder (empty for the root)"
+
+	case operationFailedMsg:
+		dc.err = msg.err
+
+	case renamePlannedMsg:
+		dc.plan = msg.plan
+		dc.planMode = dc.mode
+		dc.mode = previewMode
+		dc.previewOffset = 0
+	}
+
+	return nil
+}
+
+func (dc *Component) open(mode mode, note core.Note, value string) {
+	dc.mode = mode
+	dc.currentNote = note
+	dc.err = nil
+	dc.textInput.SetValue(value)
+	dc.textInput.CursorEnd()
+}
+
+func (dc *Component) ForegroundUpdate(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(keyMsg, dc.keys.quitDialog):
+			dc.operation.Cancel()
+			return func() tea.Msg {
+				return commands.QuitDialogMsg{}
+			}
+		case dc.mode == deleteMode && key.Matches(keyMsg, dc.keys.cancel):
+			return func() tea.Msg {
+				return commands.QuitDialogMsg{}
+			}
+		case dc.mode == previewMode && key.Matches(keyMsg, dc.keys.cancel):
+			dc.mode = dc.planMode
+			dc.err = nil
+			return nil
+		case dc.mode == previewMode && (key.Matches(keyMsg, dc.keys.confirm) || key.Matches(keyMsg, dc.keys.submit)):
+			return dc.applyRename(dc.plan, dc.planMode)
+		case dc.mode == previewMode && key.Matches(keyMsg, dc.keys.scrollUp):
+			dc.previewOffset = max(dc.previewOffset-1, 0)
+			return nil
+		case dc.mode == previewMode && key.Matches(keyMsg, dc.keys.scrollDown):
+			dc.previewOffset = max(min(dc.previewOffset+1, len(dc.plan.Edits)-1), 0)
+			return nil
+		case dc.mode == deleteMode && key.Matches(keyMsg, dc.keys.confirm):
+			return dc.deleteNote()
+		case dc.mode == renameMode && key.Matches(keyMsg, dc.keys.submit):
+			if name := dc.textInput.Value(); name != "" {
+				return dc.renameNote(name)
+			}
+			return nil
+		case dc.mode == moveMode && key.Matches(keyMsg, dc.keys.submit):
+			return dc.moveNote(dc.textInput.Value())
+		}
+	}
+
+	if dc.mode == deleteMode || dc.mode == previewMode {
+		return nil
+	}
+
+	var cmd tea.Cmd
+	dc.textInput, cmd = dc.textInput.Update(msg)
+	return cmd
+}
+
+func (dc *Component) deleteNote() tea.Cmd {
+	note := dc.currentNote
+	ctx := dc.operation.Start()
+
+	return func() tea.Msg {
+		err := dc.repository.DeleteNote(ctx, note)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to delete note", "error", err)
+			return operationFailedMsg{err: err}
+		}
+
+		return commands.DeleteNoteMsg{Note: note}
+	}
+}
+
+func (dc *Component) renameNote(name string) tea.Cmd {
+	note := dc.currentNote
+	ctx := dc.operation.Start()
+
+	return dc.planRename(ctx, renameMode, func() (core.RenamePlan, error) {
+		return core.PlanRename(ctx, dc.repository, note, name)
+	})
+}
+
+func (dc *Component) moveNote(folder string) tea.Cmd {
+	note := dc.currentNote
+	ctx := dc.operation.Start()
+
+	return dc.planRename(ctx, moveMode, func() (core.RenamePlan, error) {
+		return core.PlanMove(ctx, dc.repository, note, folder)
+	})
+}
+
+// planRename - look up the links a rename or move rewrites, applying it at once when there are none and asking for
+// confirmation otherwise
+func (dc *Component) planRename(ctx context.Context, mode mode, plan func() (core.RenamePlan, error)) tea.Cmd {
+	return func() tea.Msg {
+		renamePlan, err := plan()
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to plan rename", "error", err)
+			return operationFailedMsg{err: err}
+		}
+
+		if len(renamePlan.Edits) > 0 {
+			return renamePlannedMsg{plan: renamePlan}
+		}
+
+		return dc.apply(ctx, renamePlan, mode)
+	}
+}
+
+func (dc *Component) applyRename(plan core.RenamePlan, mode mode) tea.Cmd {
+	ctx := dc.operation.Start()
+
+	return func() tea.Msg {
+		return dc.apply(ctx, plan, mode)
+	}
+}
+
+func (dc *Component) apply(ctx context.Context, plan core.RenamePlan, mode mode) tea.Msg {
+	renamed, err := core.ApplyRename(ctx, dc.repository, plan)
+	if commands.IsSuperseded(err) {
+		return nil
+	}
+	if err != nil {
+		slog.Error("failed to rename note", "error", err)
+		return operationFailedMsg{err: err}
+	}
+
+	if mode == moveMode {
+		return commands.MoveNoteMsg{OldNote: plan.Note, Note: renamed}
+	}
+	return commands.RenameNoteMsg{OldNote: plan.Note, Note: renamed}
+}
+
+func (dc *Component) View() string {
+	var content string
+
+	switch dc.mode {
+	case deleteMode:
+		content = "Delete Note\n\nMove '" + dc.currentNote.RelativePath() + "' to the trash?\n\nPress y to delete, n or Esc to cancel"
+	case renameMode:
+		content = "Rename Note\n\n" + dc.textInput.View() + "\n\nPress Enter to rename, Esc to cancel"
+	case moveMode:
+		content = "Move Note\n\n" + dc.textInput.View() + "\n\nPress Enter to move, Esc to cancel"
+	case previewMode:
+		content = dc.previewView()
+	}
+
+	if dc.err != nil {
+		content += "\n\nError: " + commands.DescribeError(dc.err)
+	}
+
+	return theme.Style.Width(dc.width).Height(dc.height).Render(content)
+}
+
+// previewView - the lines a rename or move rewrites, as many as fit the dialog from the scrolled to edit on
+func (dc *Component) previewView() string {
+	action := "Rename"
+	if dc.planMode == moveMode {
+		action = "Move"
+	}
+
+	header := fmt.Sprintf("%s Note\n\n%s '%s' to '%s' and update %d links in %d notes:\n",
+		action, action, dc.plan.Note.RelativePath(), dc.plan.Target, len(dc.plan.Edits), dc.plan.Notes())
+	footer := "\nPress y or Enter to apply, ↑/↓ to scroll, n to go back, Esc to cancel"
+
+	// every edit takes three lines, and the header, footer and error take about eight
+	rows := max((dc.height-8)/3, 1)
+
+	var lines []string
+	edits := dc.plan.Edits
+	for i := dc.previewOffset; i < min(dc.previewOffset+rows, len(edits)); i++ {
+		edit := edits[i]
+		lines = append(lines,
+			theme.PropertyKeyStyle.Render(fmt.Sprintf("%s:%d", edit.Path, edit.Line)),
+			theme.DiffDeleteStyle.Render("- "+edit.Before),
+			theme.DiffInsertStyle.Render("+ "+edit.After),
+		)
+	}
+	if more := len(edits) - dc.previewOffset - rows; more > 0 {
+		lines = append(lines, fmt.Sprintf("… %d more", more))
+	}
+
+	return header + "\n" + strings.Join(lines, "\n") + "\n" + footer
+}
@@ -0,0 +1,212 @@
+package dialog
+
+import (
+	"context"
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	"errors"
+	tea "github.com/charmbracelet/bubbletea"
+	"strings"
+	"testing"
+)
+
+func TestNewDialogComponent(t *testing.T) {
+	repo := core.NewMemoryRepository()
+	component := NewComponent(repo)
+
+	if component.repository != repo {
+		t.Error("Expected repository to be set correctly")
+	}
+
+	if !component.textInput.Focused() {
+		t.Error("Expected text input to be focused on initialization")
+	}
+}
+
+func TestDialogComponentBackgroundUpdate(t *testing.T) {
+	t.Run("RenameNotePromptMsg prefills the current name", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+
+		note := core.NewNote("projects/draft.md", "# Draft")
+		component.BackgroundUpdate(commands.RenameNotePromptMsg{Note: note})
+
+		if component.mode != renameMode {
+			t.Error("Expected dialog to be in rename mode")
+		}
+
+		if component.textInput.Value() != "draft" {
+			t.Errorf("Expected text input to be 'draft', got '%s'", component.textInput.Value())
+		}
+	})
+
+	t.Run("RenameNotePromptMsg prefills the file name of a note titled in its front matter", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+
+		note := core.NewNote("projects/draft.md", "---\ntitle: My Great Note: Part 1\n---\n# Draft")
+		component.BackgroundUpdate(commands.RenameNotePromptMsg{Note: note})
+
+		if component.textInput.Value() != "draft" {
+			t.Errorf("Expected text input to be 'draft', got '%s'", component.textInput.Value())
+		}
+	})
+
+	t.Run("MoveNotePromptMsg prefills the current folder", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+
+		note := core.NewNote("projects/draft.md", "# Draft")
+		component.BackgroundUpdate(commands.MoveNotePromptMsg{Note: note})
+
+		if component.mode != moveMode {
+			t.Error("Expected dialog to be in move mode")
+		}
+
+		if component.textInput.Value() != "projects" {
+			t.Errorf("Expected text input to be 'projects', got '%s'", component.textInput.Value())
+		}
+	})
+}
+
+func TestDialogComponentForegroundUpdate(t *testing.T) {
+	t.Run("'y' key confirms deletion", func(t *testing.T) {
+		note := core.NewNote("doomed.md", "# Doomed")
+		repo := core.NewMemoryRepository(note)
+		component := NewComponent(repo)
+		component.BackgroundUpdate(commands.DeleteNotePromptMsg{Note: note})
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for 'y' key")
+		}
+
+		deleteMsg, ok := cmd().(commands.DeleteNoteMsg)
+		if !ok {
+			t.Fatal("Expected DeleteNoteMsg from 'y' key command")
+		}
+
+		if deleteMsg.Note.FilePath() != "doomed.md" {
+			t.Errorf("Expected deleted note 'doomed.md', got '%s'", deleteMsg.Note.FilePath())
+		}
+	})
+
+	t.Run("'n' key cancels deletion", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+
+		component.BackgroundUpdate(commands.DeleteNotePromptMsg{Note: core.NewNote("doomed.md", "")})
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for 'n' key")
+		}
+
+		if _, ok := cmd().(commands.QuitDialogMsg); !ok {
+			t.Error("Expected QuitDialogMsg from 'n' key command")
+		}
+	})
+
+	t.Run("Enter key renames the note", func(t *testing.T) {
+		note := core.NewNote("draft.md", "# Draft")
+		repo := core.NewMemoryRepository(note)
+		component := NewComponent(repo)
+		component.BackgroundUpdate(commands.RenameNotePromptMsg{Note: note})
+		component.textInput.SetValue("final")
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEnter})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for Enter key")
+		}
+
+		renameMsg, ok := cmd().(commands.RenameNoteMsg)
+		if !ok {
+			t.Fatal("Expected RenameNoteMsg from Enter key command")
+		}
+
+		if renameMsg.OldNote.Title() != "draft" || renameMsg.Note.Title() != "final" {
+			t.Errorf("Expected draft to be renamed to final, got '%s' to '%s'", renameMsg.OldNote.Title(), renameMsg.Note.Title())
+		}
+	})
+
+	t.Run("renames rewriting links are previewed before they apply", func(t *testing.T) {
+		ctx := context.Background()
+		graph := core.NewLinkGraph()
+		repo := core.ChainRepository(core.NewMemoryRepository(
+			core.NewNote("draft.md", "# Draft"),
+			core.NewNote("index.md", "Read [[draft]]."),
+		), core.WithLinkGraph(graph))
+		if err := graph.Build(ctx, repo); err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+
+		note, _ := repo.GetNoteByTitle(ctx, "draft")
+		component := NewComponent(repo)
+		component.BackgroundUpdate(tea.WindowSizeMsg{Width: 80, Height: 24})
+		component.BackgroundUpdate(commands.RenameNotePromptMsg{Note: note})
+		component.textInput.SetValue("final")
+
+		component.BackgroundUpdate(component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEnter})())
+
+		if component.mode != previewMode {
+			t.Fatal("Expected the rename to be previewed")
+		}
+		if view := component.View(); !strings.Contains(view, "index.md:1") || !strings.Contains(view, "+ Read [[final]].") {
+			t.Errorf("Expected the preview to show the rewritten line, got %q", view)
+		}
+
+		component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+		if component.mode != renameMode || component.textInput.Value() != "final" {
+			t.Error("Expected 'n' to go back to the name")
+		}
+
+		component.BackgroundUpdate(component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEnter})())
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		if cmd == nil {
+			t.Fatal("Expected 'y' to apply the rename")
+		}
+
+		if renameMsg, ok := cmd().(commands.RenameNoteMsg); !ok || renameMsg.Note.Title() != "final" {
+			t.Fatalf("Expected RenameNoteMsg for the renamed note, got %+v", renameMsg)
+		}
+
+		index, _ := repo.GetNoteByTitle(ctx, "index")
+		if index, _ = core.LoadNote(ctx, repo, index); index.FileContent() != "Read [[final]]." {
+			t.Errorf("Expected the link to be rewritten, got %q", index.FileContent())
+		}
+	})
+
+	t.Run("repository errors keep the dialog open with the error", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		repo.FailWith(errors.New("target exists"))
+		component := NewComponent(repo)
+
+		component.BackgroundUpdate(commands.MoveNotePromptMsg{Note: core.NewNote("draft.md", "")})
+		component.textInput.SetValue("archive")
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEnter})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for Enter key")
+		}
+
+		component.BackgroundUpdate(cmd())
+
+		if component.err == nil || !strings.Contains(component.View(), "target exists") {
+			t.Error("Expected the repository error to be shown in the dialog")
+		}
+	})
+
+	t.Run("Escape key creates QuitDialogMsg", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEsc})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for Escape key")
+		}
+
+		if _, ok := cmd().(commands.QuitDialogMsg); !ok {
+			t.Error("Expected QuitDialogMsg from Escape key command")
+		}
+	})
+}
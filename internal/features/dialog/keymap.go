@@ -0,0 +1,43 @@
+package dialog
+
+import "github.com/charmbracelet/bubbles/key"
+
+type componentKeyMap struct {
+	confirm    key.Binding
+	cancel     key.Binding
+	submit     key.Binding
+	quitDialog key.Binding
+	scrollUp   key.Binding
+	scrollDown key.Binding
+}
+
+func newComponentKeyMap() componentKeyMap {
+	km := componentKeyMap{
+		confirm: key.NewBinding(
+			key.WithKeys("y", "Y"),
+			key.WithHelp("y", "confirm"),
+		),
+		cancel: key.NewBinding(
+			key.WithKeys("n", "N"),
+			key.WithHelp("n", "cancel"),
+		),
+		submit: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "apply"),
+		),
+		quitDialog: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back to list note"),
+		),
+		scrollUp: key.NewBinding(
+			key.WithKeys("up"),
+			key.WithHelp("↑", "previous change"),
+		),
+		scrollDown: key.NewBinding(
+			key.WithKeys("down"),
+			key.WithHelp("↓", "next change"),
+		),
+	}
+
+	return km
+}
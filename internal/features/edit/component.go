@@ -1,21 +1,30 @@
 package edit
 
 import (
+	"context"
 	"elephant/internal/core"
 	"elephant/internal/features/commands"
 	"elephant/internal/theme"
+	"errors"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
 	"log/slog"
 )
 
+// saveNoteFailedMsg - the note could not be saved and the edit state stays open to show why
+type saveNoteFailedMsg struct{ err error }
+
 type Component struct {
 	width, height int
 	textarea      textarea.Model
 	repository    core.Repository
 	keys          componentKeyMap
 	currentNote   core.Note
+	saving        commands.Operation
+	err           error
+	// deleted - saving found the note deleted elsewhere since it was loaded, so it can be recreated or discarded
+	deleted bool
 }
 
 func NewComponent(repository core.Repository) Component {
@@ -52,29 +61,88 @@ func (ec *Component) BackgroundUpdate(msg tea.Msg) tea.Cmd {
 		ec.textarea.SetHeight(ec.height)
 
 	case commands.ViewNoteMsg:
+		ec.currentNote = msg.Note
+		ec.err = nil
+		ec.deleted = false
+		ec.textarea.SetValue(msg.Note.FileContent())
+
+	case commands.ResumeEditNoteMsg:
+		ec.currentNote = msg.Note
+		ec.err = nil
+		ec.deleted = false
+		ec.textarea.SetValue(msg.Note.FileContent())
+
+	case commands.QuitEditNoteMsg:
+		ec.currentNote = msg.Note
+		ec.err = nil
+		ec.deleted = false
+		return ec.unlockNote(msg.Note)
+
+	case commands.RestoreRevisionMsg:
 		ec.currentNote = msg.Note
 		ec.textarea.SetValue(msg.Note.FileContent())
 
+	case commands.NotesChangedMsg:
+		ec.reloadNote(msg.Changes)
+
+	case saveNoteFailedMsg:
+		ec.err = msg.err
+		ec.deleted = errors.Is(msg.err, core.ErrNotFound)
+
 	}
 
 	return nil
 }
 
+// unlockNote - release the lock taken on the note when editing started, when the repository supports it
+func (ec *Component) unlockNote(note core.Note) tea.Cmd {
+	locker, ok := ec.repository.(core.NoteLocker)
+	if !ok {
+		return nil
+	}
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), commands.OperationTimeout)
+		defer cancel()
+
+		if err := locker.UnlockNote(ctx, note); err != nil {
+			slog.Error("failed to unlock note", "file", note.FilePath(), "error", err)
+		}
+		return nil
+	}
+}
+
+// reloadNote - follow the current note when it is renamed and pick up changes made to it elsewhere as long as
+// it has no unsaved edits, otherwise saving reports the conflict
+func (ec *Component) reloadNote(changes []core.NoteChange) {
+	for _, change := range changes {
+		switch {
+		case change.Kind == core.NoteRenamed && change.Previous.FilePath() == ec.currentNote.FilePath():
+			ec.currentNote = change.Note.WithContent(ec.currentNote.FileContent())
+		case change.Kind == core.NoteUpdated && change.Note.FilePath() == ec.currentNote.FilePath():
+			if ec.textarea.Value() == ec.currentNote.FileContent() {
+				ec.currentNote = change.Note
+				ec.textarea.SetValue(change.Note.FileContent())
+			}
+		}
+	}
+}
+
 func (ec *Component) ForegroundUpdate(msg tea.Msg) tea.Cmd {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		switch {
 		case key.Matches(keyMsg, ec.keys.quitEditNote):
-			ec.currentNote = core.NewNote(ec.currentNote.FilePath(), ec.textarea.Value())
-
-			return func() tea.Msg {
-				err := ec.repository.SaveNote(ec.currentNote)
-				if err != nil {
-					slog.Error("failed to save note", "error", err)
-					return nil
-				}
-
-				return commands.QuitEditNoteMsg{Note: ec.currentNote}
-			}
+			ec.currentNote = ec.currentNote.WithContent(ec.textarea.Value())
+			return ec.saveNote(ec.currentNote)
+		case ec.deleted && key.Matches(keyMsg, ec.keys.recreateNote):
+			// rebased onto no stored version, the note is saved as a new one
+			ec.currentNote = ec.currentNote.WithContent(ec.textarea.Value()).Rebase(core.Note{})
+			return ec.saveNote(ec.currentNote)
+		case ec.deleted && key.Matches(keyMsg, ec.keys.discardEdits):
+			ec.saving.Cancel()
+			return tea.Batch(ec.unlockNote(ec.currentNote), func() tea.Msg {
+				return commands.QuitViewNoteMsg{}
+			})
 		}
 	}
 
@@ -83,7 +151,39 @@ func (ec *Component) ForegroundUpdate(msg tea.Msg) tea.Cmd {
 	return cmd
 }
 
+func (ec *Component) saveNote(note core.Note) tea.Cmd {
+	ctx := ec.saving.Start()
+
+	return func() tea.Msg {
+		saved, err := ec.repository.SaveNote(ctx, note)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+
+		var conflict *core.ConflictError
+		if errors.As(err, &conflict) {
+			slog.Warn("note changed on disk while editing", "file", note.FilePath())
+			return commands.ConflictMsg{Mine: conflict.Mine, Theirs: conflict.Theirs}
+		}
+
+		if err != nil {
+			slog.Error("failed to save note", "error", err)
+			return saveNoteFailedMsg{err: err}
+		}
+
+		return commands.QuitEditNoteMsg{Note: saved}
+	}
+}
+
 func (ec *Component) View() string {
 	listView := ec.textarea.View()
+	switch {
+	case ec.deleted:
+		listView = "This note was deleted elsewhere while you were editing it\n\n" +
+			"ctrl+r recreate the note • ctrl+x discard your changes\n\n" + listView
+	case ec.err != nil:
+		listView = "Could not save note: " + commands.DescribeError(ec.err) + "\n\n" + listView
+	}
+
 	return theme.Style.Width(ec.width).Height(ec.height).Render(listView)
 }
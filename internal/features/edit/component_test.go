@@ -1,53 +1,20 @@
 package edit
 
 import (
+	"context"
 	"elephant/internal/core"
 	"elephant/internal/features/commands"
 	"errors"
 	tea "github.com/charmbracelet/bubbletea"
+	"strings"
 	"testing"
 )
 
-type mockRepository struct {
-	notes []core.Note
-	err   error
-}
-
-func (m *mockRepository) GetAllNotes() ([]core.Note, error) {
-	if m.err != nil {
-		return nil, m.err
-	}
-	return m.notes, nil
-}
-
-func (m *mockRepository) GetNoteByTitle(title string) (core.Note, error) {
-	if m.err != nil {
-		return core.Note{}, m.err
-	}
-	for _, note := range m.notes {
-		if note.Title() == title {
-			return note, nil
-		}
-	}
-	return core.Note{}, errors.New("note not found")
-}
-
-func (m *mockRepository) SaveNote(_ core.Note) error {
-	return m.err
-}
-
-func (m *mockRepository) CreateEmptyNote(filename string) (core.Note, error) {
-	if m.err != nil {
-		return core.Note{}, m.err
-	}
-	return core.NewNote(filename+".md", ""), nil
-}
-
 func TestNewEditComponent(t *testing.T) {
-	mockRepo := &mockRepository{}
-	component := NewComponent(mockRepo)
+	repo := core.NewMemoryRepository()
+	component := NewComponent(repo)
 
-	if component.repository != mockRepo {
+	if component.repository != repo {
 		t.Error("Expected repository to be set correctly")
 	}
 
@@ -62,8 +29,8 @@ func TestNewEditComponent(t *testing.T) {
 
 func TestEditComponentBackgroundUpdate(t *testing.T) {
 	t.Run("ViewNoteMsg sets current note and textarea content", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		note := core.NewNote("test.md", "# Test Note\nThis is test content")
 		msg := commands.ViewNoteMsg{Note: note}
@@ -88,10 +55,44 @@ func TestEditComponentBackgroundUpdate(t *testing.T) {
 	})
 }
 
+func TestEditComponentNotesChanged(t *testing.T) {
+	t.Run("reloads the note when it has no unsaved edits", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+		component.BackgroundUpdate(commands.ViewNoteMsg{Note: core.NewNote("test.md", "original")})
+
+		component.BackgroundUpdate(commands.NotesChangedMsg{Changes: []core.NoteChange{
+			{Kind: core.NoteUpdated, Note: core.NewNote("test.md", "reloaded")},
+		}})
+
+		if component.textarea.Value() != "reloaded" {
+			t.Errorf("Expected the textarea to be reloaded, got '%s'", component.textarea.Value())
+		}
+	})
+
+	t.Run("keeps unsaved edits", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+		component.BackgroundUpdate(commands.ViewNoteMsg{Note: core.NewNote("test.md", "original")})
+		component.textarea.SetValue("my edits")
+
+		component.BackgroundUpdate(commands.NotesChangedMsg{Changes: []core.NoteChange{
+			{Kind: core.NoteUpdated, Note: core.NewNote("test.md", "reloaded")},
+		}})
+
+		if component.textarea.Value() != "my edits" {
+			t.Errorf("Expected unsaved edits to be kept, got '%s'", component.textarea.Value())
+		}
+		if component.currentNote.FileContent() != "original" {
+			t.Error("Expected the note to stay at the loaded version so saving reports the conflict")
+		}
+	})
+}
+
 func TestEditComponentForegroundUpdate(t *testing.T) {
 	t.Run("Escape key creates QuitEditNoteMsg with updated content", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		originalNote := core.NewNote("test.md", "# Original Content")
 		viewMsg := commands.ViewNoteMsg{Note: originalNote}
@@ -121,3 +122,113 @@ func TestEditComponentForegroundUpdate(t *testing.T) {
 		}
 	})
 }
+
+func TestEditComponentSaveErrors(t *testing.T) {
+	t.Run("failed saves keep the editor open and show the error", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		repo.FailWith(errors.New("disk full"))
+		component := NewComponent(repo)
+		component.BackgroundUpdate(tea.WindowSizeMsg{Width: 80, Height: 20})
+		component.BackgroundUpdate(commands.ViewNoteMsg{Note: core.NewNote("test.md", "# Original")})
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEsc})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for Escape key")
+		}
+
+		msg := cmd()
+		if _, ok := msg.(commands.QuitEditNoteMsg); ok {
+			t.Fatal("Expected no QuitEditNoteMsg when saving fails")
+		}
+
+		component.BackgroundUpdate(msg)
+
+		if !strings.Contains(component.View(), "disk full") {
+			t.Error("Expected the save error to be shown")
+		}
+	})
+}
+
+func TestEditComponentDeletedNote(t *testing.T) {
+	newDeleted := func(t *testing.T) (Component, core.Repository) {
+		t.Helper()
+
+		repo := core.NewMemoryRepository(core.NewNote("test.md", "# Original"))
+		loaded, _ := repo.GetNoteByTitle(context.Background(), "test")
+		_ = repo.DeleteNote(context.Background(), loaded)
+
+		component := NewComponent(repo)
+		component.BackgroundUpdate(commands.ViewNoteMsg{Note: loaded})
+		component.textarea.SetValue("# Mine")
+		component.BackgroundUpdate(component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEsc})())
+
+		if !strings.Contains(component.View(), "deleted elsewhere") {
+			t.Fatalf("Expected the deleted note to be reported, got %q", component.View())
+		}
+		return component, repo
+	}
+
+	t.Run("ctrl+r recreates the note with the edits", func(t *testing.T) {
+		component, repo := newDeleted(t)
+
+		quitMsg, ok := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyCtrlR})().(commands.QuitEditNoteMsg)
+		if !ok || quitMsg.Note.FileContent() != "# Mine" {
+			t.Fatalf("Expected the note to be saved again, got %+v", quitMsg)
+		}
+		if recreated, err := repo.GetNoteByTitle(context.Background(), "test"); err != nil || recreated.FileContent() != "# Mine" {
+			t.Errorf("Expected the note to be recreated, got %q, %v", recreated.FileContent(), err)
+		}
+	})
+
+	t.Run("ctrl+x discards the edits and leaves the note", func(t *testing.T) {
+		component, repo := newDeleted(t)
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyCtrlX})
+		if cmd == nil {
+			t.Fatal("Expected ctrl+x to leave the editor")
+		}
+		if _, ok := cmd().(commands.QuitViewNoteMsg); !ok {
+			t.Error("Expected QuitViewNoteMsg back to the list")
+		}
+		if _, err := repo.GetNoteByTitle(context.Background(), "test"); !errors.Is(err, core.ErrNotFound) {
+			t.Errorf("Expected the note to stay deleted, got %v", err)
+		}
+	})
+}
+
+func TestEditComponentConflicts(t *testing.T) {
+	t.Run("conflicting saves enter the conflict state", func(t *testing.T) {
+		repo := core.NewMemoryRepository(core.NewNote("test.md", "# Original"))
+		loaded, _ := repo.GetNoteByTitle(context.Background(), "test")
+		_, _ = repo.SaveNote(context.Background(), loaded.WithContent("# Theirs"))
+
+		component := NewComponent(repo)
+		component.BackgroundUpdate(commands.ViewNoteMsg{Note: loaded})
+		component.textarea.SetValue("# Mine")
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEsc})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for Escape key")
+		}
+
+		conflictMsg, ok := cmd().(commands.ConflictMsg)
+		if !ok {
+			t.Fatal("Expected ConflictMsg from Escape key command")
+		}
+
+		if conflictMsg.Theirs.FileContent() != "# Theirs" {
+			t.Errorf("Expected theirs to be '# Theirs', got '%s'", conflictMsg.Theirs.FileContent())
+		}
+	})
+
+	t.Run("ResumeEditNoteMsg puts the note back in the editor", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+
+		component.BackgroundUpdate(commands.ResumeEditNoteMsg{Note: core.NewNote("test.md", "# Merged")})
+
+		if component.textarea.Value() != "# Merged" {
+			t.Errorf("Expected textarea to be '# Merged', got '%s'", component.textarea.Value())
+		}
+	})
+}
@@ -4,6 +4,8 @@ import "github.com/charmbracelet/bubbles/key"
 
 type componentKeyMap struct {
 	quitEditNote key.Binding
+	recreateNote key.Binding
+	discardEdits key.Binding
 }
 
 func newComponentKeyMap() componentKeyMap {
@@ -12,6 +14,14 @@ func newComponentKeyMap() componentKeyMap {
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "back to view note"),
 		),
+		recreateNote: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "recreate the note"),
+		),
+		discardEdits: key.NewBinding(
+			key.WithKeys("ctrl+x"),
+			key.WithHelp("ctrl+x", "discard your changes"),
+		),
 	}
 
 	return km
@@ -0,0 +1,200 @@
+package history
+
+import (
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	"elephant/internal/theme"
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"log/slog"
+	"strings"
+)
+
+// historyFailedMsg - loading or restoring revisions failed
+type historyFailedMsg struct{ err error }
+
+// revisionItem - a revision shown in the history list
+type revisionItem struct {
+	core.Revision
+	current bool
+}
+
+func (r revisionItem) Title() string {
+	title := r.SavedAt.Local().Format("2006-01-02 15:04:05")
+	if r.current {
+		title += " (current)"
+	}
+	return title
+}
+
+func (r revisionItem) Description() string {
+	description := fmt.Sprintf("%s • %d lines", r.Hash[:8], strings.Count(r.Content, "\n")+1)
+	if r.Message != "" {
+		description += " • " + r.Message
+	}
+	return description
+}
+
+func (r revisionItem) FilterValue() string {
+	return r.Title()
+}
+
+// Component - the revisions of a note with a diff of the selected one against the current version
+type Component struct {
+	width, height int
+	list          list.Model
+	diff          viewport.Model
+	keys          componentKeyMap
+	history       core.History
+
+	currentNote core.Note
+	selected    string
+	loading     commands.Operation
+	restoring   commands.Operation
+}
+
+func NewComponent(history core.History) Component {
+	keys := newComponentKeyMap()
+	itemList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	itemList.Title = "History"
+	itemList.SetShowHelp(false)
+	itemList.AdditionalFullHelpKeys = keys.getListOfBindings
+
+	return Component{
+		list:    itemList,
+		diff:    viewport.New(0, 0),
+		keys:    keys,
+		history: history,
+	}
+}
+
+func (hc *Component) Init() tea.Cmd {
+	return nil
+}
+
+func (hc *Component) loadRevisions(note core.Note) tea.Cmd {
+	ctx := hc.loading.Start()
+
+	return func() tea.Msg {
+		revisions, err := hc.history.GetRevisions(ctx, note)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to load history", "file", note.FilePath(), "error", err)
+			return historyFailedMsg{err: err}
+		}
+
+		return commands.ListRevisionsMsg{Note: note, Revisions: revisions}
+	}
+}
+
+func (hc *Component) BackgroundUpdate(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := theme.Style.GetFrameSize()
+
+		hc.width = msg.Width - h
+		hc.height = msg.Height - v
+
+		listHeight := hc.height / 3
+		hc.list.SetSize(hc.width, listHeight)
+		hc.diff.Width = hc.width
+		hc.diff.Height = hc.height - listHeight - 2
+
+	case commands.ShowHistoryMsg:
+		hc.currentNote = msg.Note
+		hc.selected = ""
+		hc.list.SetItems(nil)
+		hc.diff.SetContent("")
+		return hc.loadRevisions(msg.Note)
+
+	case commands.ListRevisionsMsg:
+		if msg.Note.FilePath() != hc.currentNote.FilePath() {
+			return nil
+		}
+
+		items := make([]list.Item, len(msg.Revisions))
+		for i, revision := range msg.Revisions {
+			items[i] = revisionItem{Revision: revision, current: revision.Hash == core.HashContent(hc.currentNote.FileContent())}
+		}
+
+		hc.list.SetItems(items)
+		hc.list.Select(0)
+		hc.updateDiff()
+
+	case commands.RestoreRevisionMsg:
+		hc.currentNote = msg.Note
+
+	case historyFailedMsg:
+		return hc.list.NewStatusMessage("Error: " + commands.DescribeError(msg.err))
+	}
+
+	return nil
+}
+
+func (hc *Component) ForegroundUpdate(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && hc.list.FilterState() != list.Filtering {
+		switch {
+		case key.Matches(keyMsg, hc.keys.restoreRevision):
+			if selectedItem, ok := hc.list.SelectedItem().(revisionItem); ok && !selectedItem.current {
+				return hc.restoreRevision(selectedItem.Hash)
+			}
+			return nil
+		case key.Matches(keyMsg, hc.keys.scrollDiff):
+			var cmd tea.Cmd
+			hc.diff, cmd = hc.diff.Update(msg)
+			return cmd
+		case key.Matches(keyMsg, hc.keys.quitHistory) && hc.list.FilterState() == list.Unfiltered:
+			hc.loading.Cancel()
+			hc.restoring.Cancel()
+			return func() tea.Msg {
+				return commands.QuitHistoryMsg{}
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	hc.list, cmd = hc.list.Update(msg)
+	hc.updateDiff()
+	return cmd
+}
+
+// updateDiff - show what changed from the selected revision to the current version
+func (hc *Component) updateDiff() {
+	selectedItem, ok := hc.list.SelectedItem().(revisionItem)
+	if !ok || selectedItem.Hash == hc.selected {
+		return
+	}
+
+	hc.selected = selectedItem.Hash
+	hc.diff.SetContent(theme.RenderDiff(core.DiffLines(selectedItem.Content, hc.currentNote.FileContent())))
+	hc.diff.GotoTop()
+}
+
+func (hc *Component) restoreRevision(hash string) tea.Cmd {
+	note := hc.currentNote
+	ctx := hc.restoring.Start()
+
+	return func() tea.Msg {
+		restored, err := hc.history.RestoreRevision(ctx, note, hash)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to restore revision", "file", note.FilePath(), "error", err)
+			return historyFailedMsg{err: err}
+		}
+
+		return commands.RestoreRevisionMsg{Note: restored}
+	}
+}
+
+func (hc *Component) View() string {
+	content := hc.list.View() + "\n\nChanges from the selected revision to the current version (pgup/pgdown to scroll):\n" + hc.diff.View()
+
+	return theme.Style.Width(hc.width).Height(hc.height).Render(content)
+}
@@ -0,0 +1,136 @@
+package history
+
+import (
+	"context"
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	"errors"
+	tea "github.com/charmbracelet/bubbletea"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockHistory struct {
+	revisions []core.Revision
+	err       error
+	restored  string
+}
+
+func (m *mockHistory) GetRevisions(_ context.Context, _ core.Note) ([]core.Revision, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.revisions, nil
+}
+
+func (m *mockHistory) RestoreRevision(_ context.Context, note core.Note, hash string) (core.Note, error) {
+	if m.err != nil {
+		return core.Note{}, m.err
+	}
+	for _, revision := range m.revisions {
+		if revision.Hash == hash {
+			m.restored = hash
+			return note.WithContent(revision.Content), nil
+		}
+	}
+	return core.Note{}, core.ErrNotFound
+}
+
+func newRevision(content string, savedAt time.Time) core.Revision {
+	return core.Revision{Hash: core.HashContent(content), SavedAt: savedAt, Content: content}
+}
+
+func openHistory(t *testing.T, component *Component, note core.Note) {
+	t.Helper()
+
+	component.BackgroundUpdate(tea.WindowSizeMsg{Width: 120, Height: 40})
+	cmd := component.BackgroundUpdate(commands.ShowHistoryMsg{Note: note})
+	if cmd == nil {
+		t.Fatal("Expected ShowHistoryMsg to load the revisions")
+	}
+	component.BackgroundUpdate(cmd())
+}
+
+func TestHistoryComponent(t *testing.T) {
+	now := time.Now()
+	note := core.NewNote("note.md", "line one\nline two")
+	revisions := []core.Revision{
+		newRevision("line one\nline two", now),
+		newRevision("line one\nold line", now.Add(-time.Hour)),
+	}
+
+	t.Run("lists the revisions and marks the current one", func(t *testing.T) {
+		component := NewComponent(&mockHistory{revisions: revisions})
+		openHistory(t, &component, note)
+
+		items := component.list.Items()
+		if len(items) != 2 {
+			t.Fatalf("Expected 2 revisions, got %d", len(items))
+		}
+		if !items[0].(revisionItem).current || items[1].(revisionItem).current {
+			t.Error("Expected only the first revision to be marked current")
+		}
+		if !strings.Contains(component.View(), "(current)") {
+			t.Error("Expected the current revision to be labelled")
+		}
+	})
+
+	t.Run("shows the diff of the selected revision against the current version", func(t *testing.T) {
+		component := NewComponent(&mockHistory{revisions: revisions})
+		openHistory(t, &component, note)
+
+		component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyDown})
+
+		view := component.View()
+		if !strings.Contains(view, "- old line") || !strings.Contains(view, "+ line two") {
+			t.Errorf("Expected the diff to the older revision, got '%s'", view)
+		}
+	})
+
+	t.Run("'r' restores the selected revision", func(t *testing.T) {
+		mock := &mockHistory{revisions: revisions}
+		component := NewComponent(mock)
+		openHistory(t, &component, note)
+
+		if cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}}); cmd != nil {
+			t.Error("Expected the current revision not to be restorable")
+		}
+
+		component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyDown})
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+		if cmd == nil {
+			t.Fatal("Expected a command for 'r'")
+		}
+
+		msg, ok := cmd().(commands.RestoreRevisionMsg)
+		if !ok {
+			t.Fatal("Expected RestoreRevisionMsg")
+		}
+		if msg.Note.FileContent() != "line one\nold line" || mock.restored != revisions[1].Hash {
+			t.Errorf("Expected the older revision to be restored, got '%s'", msg.Note.FileContent())
+		}
+	})
+
+	t.Run("errors are shown to the user", func(t *testing.T) {
+		component := NewComponent(&mockHistory{err: errors.New("disk on fire")})
+		component.BackgroundUpdate(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+		msg := component.BackgroundUpdate(commands.ShowHistoryMsg{Note: note})()
+		component.BackgroundUpdate(msg)
+
+		if !strings.Contains(component.View(), "disk on fire") {
+			t.Errorf("Expected the error to be shown, got '%s'", component.View())
+		}
+	})
+
+	t.Run("esc quits the history", func(t *testing.T) {
+		component := NewComponent(&mockHistory{revisions: revisions})
+		openHistory(t, &component, note)
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEsc})
+		if _, ok := cmd().(commands.QuitHistoryMsg); !ok {
+			t.Error("Expected QuitHistoryMsg from esc")
+		}
+	})
+}
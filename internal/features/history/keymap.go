@@ -0,0 +1,36 @@
+package history
+
+import "github.com/charmbracelet/bubbles/key"
+
+type componentKeyMap struct {
+	restoreRevision key.Binding
+	scrollDiff      key.Binding
+	quitHistory     key.Binding
+}
+
+func newComponentKeyMap() componentKeyMap {
+	km := componentKeyMap{
+		restoreRevision: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "restore revision"),
+		),
+		scrollDiff: key.NewBinding(
+			key.WithKeys("pgup", "pgdown"),
+			key.WithHelp("pgup/pgdown", "scroll diff"),
+		),
+		quitHistory: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back to view note"),
+		),
+	}
+
+	return km
+}
+
+func (a componentKeyMap) getListOfBindings() []key.Binding {
+	return []key.Binding{
+		a.restoreRevision,
+		a.scrollDiff,
+		a.quitHistory,
+	}
+}
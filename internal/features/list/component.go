@@ -4,29 +4,58 @@ import (
 	"elephant/internal/core"
 	"elephant/internal/features/commands"
 	"elephant/internal/theme"
+	"fmt"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"log/slog"
 )
 
+const title = "Elephant Notes"
+
+// notesBatchMsg - more notes loaded while the list is loading
+type notesBatchMsg struct {
+	stream *noteStream
+	notes  []core.Note
+}
+
+// openNoteFailedMsg - the selected note could not be read to view it
+type openNoteFailedMsg struct{ err error }
+
 type Component struct {
 	width, height int
 	list          list.Model
+	tags          list.Model
 	keys          componentKeyMap
 	repository    core.Repository
+
+	browsingTags bool
+	activeTag    string
+	readOnly     bool
+	loading      commands.Operation
+	loadingTags  commands.Operation
+	opening      commands.Operation
+
+	// stream - the load in progress, whose batches are listed as they come until all notes are listed together
+	stream *noteStream
+	loaded int
 }
 
 func NewComponent(repository core.Repository) Component {
 	keys := newComponentKeyMap()
-	itemList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
-	itemList.Title = "Elephant Notes"
+	itemList := list.New([]list.Item{}, newNoteDelegate(), 0, 0)
+	itemList.Title = title
 	itemList.AdditionalFullHelpKeys = keys.getListOfBindings
 
+	tagList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	tagList.Title = "Tags"
+	tagList.AdditionalFullHelpKeys = keys.getListOfTagBindings
+
 	lc := Component{
 		width:      itemList.Width(),
 		height:     itemList.Height(),
 		list:       itemList,
+		tags:       tagList,
 		keys:       keys,
 		repository: repository,
 	}
@@ -35,14 +64,50 @@ func NewComponent(repository core.Repository) Component {
 }
 
 func (lc *Component) Init() tea.Cmd {
+	ctx := lc.loading.StartWithoutTimeout()
+	stream := newNoteStream()
+	lc.stream = stream
+	lc.loaded = 0
+	lc.updateTitle()
+
 	return func() tea.Msg {
-		notes, err := lc.repository.GetAllNotes()
+		go stream.run(ctx, lc.repository)
+		return stream.next()
+	}
+}
+
+func (lc *Component) loadTags() tea.Cmd {
+	ctx := lc.loadingTags.Start()
+
+	return func() tea.Msg {
+		notes, err := lc.repository.GetAllNotes(ctx)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
 		if err != nil {
-			slog.Error("failed to load notes", "error", err)
-			return commands.ListNotesMsg{}
+			slog.Error("failed to load tags", "error", err)
+			return commands.ListTagsMsg{Err: err}
 		}
 
-		return commands.ListNotesMsg{Notes: notes}
+		return commands.ListTagsMsg{Tags: core.CollectTags(notes)}
+	}
+}
+
+func (lc *Component) loadTaggedNotes(tag string) tea.Cmd {
+	ctx := lc.loading.Start()
+	lc.stream = nil
+
+	return func() tea.Msg {
+		notes, err := lc.repository.GetNotesByTag(ctx, tag)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to load notes by tag", "tag", tag, "error", err)
+			return commands.ListTaggedNotesMsg{Tag: tag, Err: err}
+		}
+
+		return commands.ListTaggedNotesMsg{Tag: tag, Notes: notes}
 	}
 }
 
@@ -55,51 +120,216 @@ func (lc *Component) BackgroundUpdate(msg tea.Msg) tea.Cmd {
 		lc.height = msg.Height - v
 
 		lc.list.SetSize(lc.width, lc.height)
+		lc.tags.SetSize(lc.width, lc.height)
+
+	case notesBatchMsg:
+		if msg.stream != lc.stream {
+			return nil
+		}
+
+		if lc.loaded == 0 {
+			lc.activeTag = ""
+			lc.setNotes(msg.notes)
+		} else {
+			lc.list.SetItems(append(lc.list.Items(), notesToItems(msg.notes)...))
+		}
+		lc.loaded += len(msg.notes)
+		lc.updateTitle()
+
+		return msg.stream.next
 
 	case commands.ListNotesMsg:
-		notes := msg.Notes
-		items := make([]list.Item, len(notes))
+		lc.stream = nil
+		if msg.Err != nil {
+			lc.updateTitle()
+			return lc.list.NewStatusMessage("Error: " + commands.DescribeError(msg.Err))
+		}
+
+		lc.activeTag = ""
+		lc.updateTitle()
+		lc.keepSelection(func() { lc.setNotes(msg.Notes) })
+
+	case openNoteFailedMsg:
+		return lc.list.NewStatusMessage("Error: " + commands.DescribeError(msg.err))
 
-		for i, note := range notes {
-			items[i] = note
+	case commands.ListTaggedNotesMsg:
+		if msg.Err != nil {
+			return lc.list.NewStatusMessage("Error: " + commands.DescribeError(msg.Err))
 		}
 
-		lc.list.SetItems(items)
+		lc.activeTag = msg.Tag
+		lc.updateTitle()
+		lc.setNotes(msg.Notes)
 
-	case commands.QuitEditNoteMsg:
-		items := lc.list.Items()
-		updatedNote := msg.Note
+	case commands.ListTagsMsg:
+		if msg.Err != nil {
+			lc.browsingTags = false
+			return lc.list.NewStatusMessage("Error: " + commands.DescribeError(msg.Err))
+		}
 
-		for i, item := range items {
-			note := item.(core.Note)
-			if note.FilePath() == updatedNote.FilePath() {
-				items[i] = updatedNote
-				break
-			}
+		items := make([]list.Item, len(msg.Tags))
+
+		for i, tag := range msg.Tags {
+			items[i] = tagItem{tag: tag}
 		}
 
-		lc.list.SetItems(items)
+		lc.tags.SetItems(items)
 
-	case commands.CreateNoteMsg:
-		totalItems := append(lc.list.Items(), msg.Note)
-		lc.list.SetItems(totalItems)
+	case commands.NotesChangedMsg:
+		lc.applyChanges(msg.Changes)
+
+	case commands.OpenVaultMsg:
+		lc.readOnly = msg.ReadOnly
+		lc.updateTitle()
 	}
 
 	return nil
 }
 
+// updateTitle - show the active tag and whether the vault is read-only in the list title
+func (lc *Component) updateTitle() {
+	lc.list.Title = title
+	if lc.activeTag != "" {
+		lc.list.Title += " #" + lc.activeTag
+	}
+	if lc.readOnly {
+		lc.list.Title += " (read-only)"
+	}
+	if lc.stream != nil {
+		lc.list.Title += fmt.Sprintf(" (loading %d…)", lc.loaded)
+	}
+}
+
+func (lc *Component) setNotes(notes []core.Note) {
+	lc.list.SetItems(notesToItems(notes))
+}
+
+func notesToItems(notes []core.Note) []list.Item {
+	items := make([]list.Item, len(notes))
+
+	for i, note := range notes {
+		items[i] = note
+	}
+
+	return items
+}
+
+// keepSelection - keep the selected note selected while update reorders the listed notes
+func (lc *Component) keepSelection(update func()) {
+	selected, ok := lc.list.SelectedItem().(core.Note)
+	update()
+	if !ok {
+		return
+	}
+
+	for i, item := range lc.list.VisibleItems() {
+		if item.(core.Note).FilePath() == selected.FilePath() {
+			lc.list.Select(i)
+			return
+		}
+	}
+}
+
+func (lc *Component) removeNote(removed core.Note) {
+	for i, item := range lc.list.Items() {
+		if item.(core.Note).FilePath() == removed.FilePath() {
+			lc.list.RemoveItem(i)
+			return
+		}
+	}
+}
+
+func (lc *Component) replaceNote(oldNote, newNote core.Note) bool {
+	for i, item := range lc.list.Items() {
+		if item.(core.Note).FilePath() == oldNote.FilePath() {
+			lc.list.SetItem(i, newNote)
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyChanges - patch the listed notes with the changes made to the vault, keeping the selection and any active filter
+func (lc *Component) applyChanges(changes []core.NoteChange) {
+	for _, change := range changes {
+		switch {
+		case change.Kind == core.NoteDeleted:
+			lc.removeNote(change.Note)
+		case lc.activeTag != "" && !change.Note.HasTag(lc.activeTag):
+			lc.removeNote(change.Previous)
+			lc.removeNote(change.Note)
+		case change.Kind == core.NoteRenamed:
+			if !lc.replaceNote(change.Previous, change.Note) {
+				lc.upsertNote(change.Note)
+			}
+		default:
+			lc.upsertNote(change.Note)
+		}
+	}
+}
+
+// upsertNote - replace the listed note with the same path, or add it when it is not listed yet
+func (lc *Component) upsertNote(note core.Note) {
+	if !lc.replaceNote(note, note) {
+		lc.list.SetItems(append(lc.list.Items(), note))
+	}
+}
+
 func (lc *Component) ForegroundUpdate(msg tea.Msg) tea.Cmd {
+	if lc.browsingTags {
+		return lc.tagsForegroundUpdate(msg)
+	}
+
 	if keyMsg, ok := msg.(tea.KeyMsg); ok && lc.list.FilterState() != list.Filtering {
 		switch {
+		case key.Matches(keyMsg, lc.keys.deleteNote):
+			if selectedItem, ok := lc.list.SelectedItem().(core.Note); ok {
+				return func() tea.Msg {
+					return commands.DeleteNotePromptMsg{Note: selectedItem}
+				}
+			}
+		case key.Matches(keyMsg, lc.keys.renameNote):
+			if selectedItem, ok := lc.list.SelectedItem().(core.Note); ok {
+				return func() tea.Msg {
+					return commands.RenameNotePromptMsg{Note: selectedItem}
+				}
+			}
+		case key.Matches(keyMsg, lc.keys.moveNote):
+			if selectedItem, ok := lc.list.SelectedItem().(core.Note); ok {
+				return func() tea.Msg {
+					return commands.MoveNotePromptMsg{Note: selectedItem}
+				}
+			}
+		case key.Matches(keyMsg, lc.keys.showTrash):
+			return func() tea.Msg {
+				return commands.ShowTrashMsg{}
+			}
+		case key.Matches(keyMsg, lc.keys.search):
+			return func() tea.Msg {
+				return commands.ShowSearchMsg{}
+			}
+		case key.Matches(keyMsg, lc.keys.showTasks):
+			return func() tea.Msg {
+				return commands.ShowTasksMsg{}
+			}
+		case key.Matches(keyMsg, lc.keys.showAgenda):
+			return func() tea.Msg {
+				return commands.ShowAgendaMsg{}
+			}
+		case key.Matches(keyMsg, lc.keys.browseTags):
+			lc.browsingTags = true
+			lc.tags.ResetFilter()
+			return lc.loadTags()
+		case key.Matches(keyMsg, lc.keys.clearTag) && lc.activeTag != "" && lc.list.FilterState() == list.Unfiltered:
+			return lc.Init()
 		case key.Matches(keyMsg, lc.keys.addNote):
 			return func() tea.Msg {
 				return commands.AddNoteMsg{}
 			}
 		case key.Matches(keyMsg, lc.keys.viewNote):
 			selectedItem := lc.list.SelectedItem().(core.Note)
-			return func() tea.Msg {
-				return commands.ViewNoteMsg{Note: selectedItem}
-			}
+			return lc.viewNote(selectedItem)
 		}
 	}
 
@@ -108,7 +338,51 @@ func (lc *Component) ForegroundUpdate(msg tea.Msg) tea.Cmd {
 	return cmd
 }
 
+// viewNote - view the note, reading its content first when it was listed without it
+func (lc *Component) viewNote(note core.Note) tea.Cmd {
+	ctx := lc.opening.Start()
+
+	return func() tea.Msg {
+		loaded, err := core.LoadNote(ctx, lc.repository, note)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to load note", "file", note.FilePath(), "error", err)
+			return openNoteFailedMsg{err: err}
+		}
+
+		return commands.ViewNoteMsg{Note: loaded}
+	}
+}
+
+func (lc *Component) tagsForegroundUpdate(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && lc.tags.FilterState() != list.Filtering {
+		switch {
+		case key.Matches(keyMsg, lc.keys.selectTag):
+			lc.browsingTags = false
+			lc.loadingTags.Cancel()
+			if selectedItem, ok := lc.tags.SelectedItem().(tagItem); ok {
+				return lc.loadTaggedNotes(selectedItem.tag.Name)
+			}
+			return nil
+		case key.Matches(keyMsg, lc.keys.clearTag) && lc.tags.FilterState() == list.Unfiltered:
+			lc.browsingTags = false
+			lc.loadingTags.Cancel()
+			return nil
+		}
+	}
+
+	var cmd tea.Cmd
+	lc.tags, cmd = lc.tags.Update(msg)
+	return cmd
+}
+
 func (lc *Component) View() string {
 	listView := lc.list.View()
+	if lc.browsingTags {
+		listView = lc.tags.View()
+	}
+
 	return theme.Style.Width(lc.width).Height(lc.height).Render(listView)
 }
@@ -1,53 +1,61 @@
 package list
 
 import (
+	"context"
 	"elephant/internal/core"
 	"elephant/internal/features/commands"
 	"errors"
 	tea "github.com/charmbracelet/bubbletea"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
-type mockRepository struct {
-	notes []core.Note
-	err   error
+// loadAll - run the load started by cmd through the component batch by batch, returning the message it ends with
+func loadAll(component *Component, cmd tea.Cmd) tea.Msg {
+	msg := cmd()
+	for {
+		batch, ok := msg.(notesBatchMsg)
+		if !ok {
+			return msg
+		}
+		msg = component.BackgroundUpdate(batch)()
+	}
 }
 
-func (m *mockRepository) GetAllNotes() ([]core.Note, error) {
-	if m.err != nil {
-		return nil, m.err
-	}
-	return m.notes, nil
+// streamingRepository - a repository streaming its notes one at a time
+type streamingRepository struct {
+	*core.MemoryRepository
 }
 
-func (m *mockRepository) GetNoteByTitle(title string) (core.Note, error) {
-	if m.err != nil {
-		return core.Note{}, m.err
-	}
-	for _, note := range m.notes {
-		if note.Title() == title {
-			return note, nil
+func (r streamingRepository) StreamNotes(ctx context.Context, batch func([]core.Note)) error {
+	notes, err := r.GetAllNotes(ctx)
+	for _, note := range notes {
+		batch([]core.Note{note})
+		if err := ctx.Err(); err != nil {
+			return err
 		}
 	}
-	return core.Note{}, errors.New("note not found")
+	return err
 }
 
-func (m *mockRepository) SaveNote(_ core.Note) error {
-	return m.err
+// hangingRepository - a repository whose stream never hands a batch
+type hangingRepository struct {
+	*core.MemoryRepository
 }
 
-func (m *mockRepository) CreateEmptyNote(filename string) (core.Note, error) {
-	if m.err != nil {
-		return core.Note{}, m.err
-	}
-	return core.NewNote(filename+".md", ""), nil
+func (r hangingRepository) StreamNotes(ctx context.Context, _ func([]core.Note)) error {
+	<-ctx.Done()
+	return ctx.Err()
 }
 
 func TestNewListComponent(t *testing.T) {
-	mockRepo := &mockRepository{}
-	component := NewComponent(mockRepo)
+	repo := core.NewMemoryRepository()
+	component := NewComponent(repo)
 
-	if component.repository != mockRepo {
+	if component.repository != repo {
 		t.Error("Expected repository to be set correctly")
 	}
 
@@ -64,18 +72,16 @@ func TestListComponentInit(t *testing.T) {
 	t.Run("note loading is successful", func(t *testing.T) {
 		note1 := core.NewNote("note1.md", "# Note 1\nContent 1")
 		note2 := core.NewNote("note2.md", "# Note 2\nContent 2")
-		mockRepo := &mockRepository{
-			notes: []core.Note{note1, note2},
-		}
+		repo := core.NewMemoryRepository(note1, note2)
 
-		component := NewComponent(mockRepo)
+		component := NewComponent(repo)
 		cmd := component.Init()
 
 		if cmd == nil {
 			t.Fatal("Expected Init to return a command")
 		}
 
-		msg := cmd()
+		msg := loadAll(&component, cmd)
 		listMsg, ok := msg.(commands.ListNotesMsg)
 		if !ok {
 			t.Fatal("Expected ListNotesMsg from Init command")
@@ -87,11 +93,10 @@ func TestListComponentInit(t *testing.T) {
 	})
 
 	t.Run("note loading fails because GetAllNotes errors", func(t *testing.T) {
-		mockRepo := &mockRepository{
-			err: errors.New("repository error"),
-		}
+		repo := core.NewMemoryRepository()
+		repo.FailWith(errors.New("repository error"))
 
-		component := NewComponent(mockRepo)
+		component := NewComponent(repo)
 		cmd := component.Init()
 
 		if cmd == nil {
@@ -110,10 +115,105 @@ func TestListComponentInit(t *testing.T) {
 	})
 }
 
+func TestListComponentProgressiveLoading(t *testing.T) {
+	t.Run("notes are listed batch by batch while loading", func(t *testing.T) {
+		repo := streamingRepository{core.NewMemoryRepository(core.NewNote("b.md", ""), core.NewNote("a.md", ""))}
+		component := NewComponent(repo)
+
+		msg := component.Init()()
+		if _, ok := msg.(notesBatchMsg); !ok {
+			t.Fatalf("Expected a batch of notes first, got %T", msg)
+		}
+
+		next := component.BackgroundUpdate(msg)
+		if len(component.list.Items()) != 1 || !strings.Contains(component.list.Title, "loading 1") {
+			t.Errorf("Expected the first note listed while loading, got %d items titled '%s'", len(component.list.Items()), component.list.Title)
+		}
+
+		listMsg, ok := loadAll(&component, next).(commands.ListNotesMsg)
+		if !ok {
+			t.Fatal("Expected ListNotesMsg once every batch is listed")
+		}
+		component.BackgroundUpdate(listMsg)
+
+		items := component.list.Items()
+		if len(items) != 2 || items[0].(core.Note).Title() != "a" || component.list.Title != title {
+			t.Errorf("Expected the sorted notes and no loading title, got %v titled '%s'", items, component.list.Title)
+		}
+	})
+
+	t.Run("batches of a superseded load are ignored", func(t *testing.T) {
+		repo := streamingRepository{core.NewMemoryRepository(core.NewNote("a.md", ""))}
+		component := NewComponent(repo)
+
+		stale := component.Init()
+		staleMsg := stale()
+		loadAll(&component, component.Init())
+
+		if cmd := component.BackgroundUpdate(staleMsg); cmd != nil || len(component.list.Items()) != 1 {
+			t.Errorf("Expected the stale batch to be ignored, got %d items", len(component.list.Items()))
+		}
+	})
+
+	t.Run("a slow list does not time the load out", func(t *testing.T) {
+		defer func(timeout time.Duration) { commands.OperationTimeout = timeout }(commands.OperationTimeout)
+		commands.OperationTimeout = 20 * time.Millisecond
+
+		repo := streamingRepository{core.NewMemoryRepository(core.NewNote("a.md", ""), core.NewNote("b.md", ""), core.NewNote("c.md", ""))}
+		component := NewComponent(repo)
+
+		msg := component.Init()()
+		for {
+			batch, ok := msg.(notesBatchMsg)
+			if !ok {
+				break
+			}
+			time.Sleep(3 * commands.OperationTimeout)
+			msg = component.BackgroundUpdate(batch)()
+		}
+
+		if listMsg, ok := msg.(commands.ListNotesMsg); !ok || listMsg.Err != nil || len(listMsg.Notes) != 3 {
+			t.Errorf("Expected every note once the list took all batches, got %+v", msg)
+		}
+	})
+
+	t.Run("a stream handing no batch in time fails", func(t *testing.T) {
+		defer func(timeout time.Duration) { commands.OperationTimeout = timeout }(commands.OperationTimeout)
+		commands.OperationTimeout = 20 * time.Millisecond
+
+		component := NewComponent(hangingRepository{core.NewMemoryRepository()})
+
+		if listMsg, ok := component.Init()().(commands.ListNotesMsg); !ok || !errors.Is(listMsg.Err, context.DeadlineExceeded) {
+			t.Errorf("Expected the stalled load to time out, got %+v", listMsg)
+		}
+	})
+
+	t.Run("a note listed without its content is read before it is viewed", func(t *testing.T) {
+		tempDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(tempDir, "note.md"), []byte("# Note\nbody"), 0644); err != nil {
+			t.Fatalf("Failed to write note: %v", err)
+		}
+
+		noteRepository := core.NewNoteRepository(tempDir)
+		component := NewComponent(&noteRepository)
+		component.BackgroundUpdate(loadAll(&component, component.Init()))
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEnter})
+		viewMsg, ok := cmd().(commands.ViewNoteMsg)
+		if !ok {
+			t.Fatal("Expected ViewNoteMsg from Enter key command")
+		}
+
+		if viewMsg.Note.FileContent() != "# Note\nbody" {
+			t.Errorf("Expected the note content, got %q", viewMsg.Note.FileContent())
+		}
+	})
+}
+
 func TestListComponentBackgroundUpdate(t *testing.T) {
 	t.Run("ListNotesMsg sets items on the list", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		note1 := core.NewNote("note1.md", "# Note 1\nContent 1")
 		note2 := core.NewNote("note2.md", "# Note 2\nContent 2")
@@ -131,9 +231,9 @@ func TestListComponentBackgroundUpdate(t *testing.T) {
 		}
 	})
 
-	t.Run("QuitEditNoteMsg updates selected item on the list", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+	t.Run("an updated note is replaced on the list", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		note1 := core.NewNote("/path/note1.md", "# Note 1\nOriginal content")
 		note2 := core.NewNote("/path/note2.md", "# Note 2\nContent 2")
@@ -141,12 +241,12 @@ func TestListComponentBackgroundUpdate(t *testing.T) {
 		component.BackgroundUpdate(listMsg)
 
 		updatedNote1 := core.NewNote("/path/note1.md", "# Note 1\nUpdated content")
-		quitMsg := commands.QuitEditNoteMsg{Note: updatedNote1}
+		changedMsg := commands.NotesChangedMsg{Changes: []core.NoteChange{{Kind: core.NoteUpdated, Note: updatedNote1}}}
 
-		cmd := component.BackgroundUpdate(quitMsg)
+		cmd := component.BackgroundUpdate(changedMsg)
 
 		if cmd != nil {
-			t.Error("Expected backgroundUpdate to return nil for QuitEditNoteMsg")
+			t.Error("Expected backgroundUpdate to return nil for NotesChangedMsg")
 		}
 
 		items := component.list.Items()
@@ -165,9 +265,9 @@ func TestListComponentBackgroundUpdate(t *testing.T) {
 		}
 	})
 
-	t.Run("CreateNoteMsg adds note to existing list", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+	t.Run("a created note is added to the existing list", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		note1 := core.NewNote("note1.md", "# Note 1\nContent 1")
 		note2 := core.NewNote("note2.md", "# Note 2\nContent 2")
@@ -175,12 +275,12 @@ func TestListComponentBackgroundUpdate(t *testing.T) {
 		component.BackgroundUpdate(listMsg)
 
 		newNote := core.NewNote("newnote.md", "# New Note\nNew content")
-		createMsg := commands.CreateNoteMsg{Note: newNote}
+		changedMsg := commands.NotesChangedMsg{Changes: []core.NoteChange{{Kind: core.NoteCreated, Note: newNote}}}
 
-		cmd := component.BackgroundUpdate(createMsg)
+		cmd := component.BackgroundUpdate(changedMsg)
 
 		if cmd != nil {
-			t.Error("Expected backgroundUpdate to return nil for CreateNoteMsg")
+			t.Error("Expected backgroundUpdate to return nil for NotesChangedMsg")
 		}
 
 		items := component.list.Items()
@@ -197,8 +297,8 @@ func TestListComponentBackgroundUpdate(t *testing.T) {
 
 func TestListComponentForegroundUpdate(t *testing.T) {
 	t.Run("Enter key creates ViewNoteMsg", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		note1 := core.NewNote("note1.md", "# Note 1\nContent 1")
 		listMsg := commands.ListNotesMsg{Notes: []core.Note{note1}}
@@ -223,8 +323,8 @@ func TestListComponentForegroundUpdate(t *testing.T) {
 	})
 
 	t.Run("Enter key during filtering does not create ViewNoteMsg", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		note1 := core.NewNote("note1.md", "# Note 1\nContent 1")
 		listMsg := commands.ListNotesMsg{Notes: []core.Note{note1}}
@@ -245,8 +345,8 @@ func TestListComponentForegroundUpdate(t *testing.T) {
 	})
 
 	t.Run("'n' key creates AddNoteMsg", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		keyMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}}
 		cmd := component.ForegroundUpdate(keyMsg)
@@ -263,8 +363,8 @@ func TestListComponentForegroundUpdate(t *testing.T) {
 	})
 
 	t.Run("'n' key during filtering does not create AddNoteMsg", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		note1 := core.NewNote("note1.md", "# Note 1\nContent 1")
 		listMsg := commands.ListNotesMsg{Notes: []core.Note{note1}}
@@ -284,3 +384,319 @@ func TestListComponentForegroundUpdate(t *testing.T) {
 		}
 	})
 }
+
+func TestListComponentView(t *testing.T) {
+	t.Run("notes in folders are shown with their folder", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+		component.BackgroundUpdate(tea.WindowSizeMsg{Width: 80, Height: 20})
+
+		note := core.NewNote("projects/alpha/kickoff.md", "# Kickoff")
+		listMsg := commands.ListNotesMsg{Notes: []core.Note{note}}
+		component.BackgroundUpdate(listMsg)
+
+		view := component.View()
+		if !strings.Contains(view, "projects/alpha/kickoff") {
+			t.Errorf("Expected view to contain the note folder, got '%s'", view)
+		}
+	})
+}
+
+func TestListComponentTags(t *testing.T) {
+	t.Run("'t' key opens the tag browser and loads tags", func(t *testing.T) {
+		repo := core.NewMemoryRepository(
+			core.NewNote("standup.md", "# Standup\n#meeting"),
+			core.NewNote("retro.md", "# Retro\n#meeting #team"),
+		)
+		component := NewComponent(repo)
+
+		keyMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}}
+		cmd := component.ForegroundUpdate(keyMsg)
+
+		if !component.browsingTags {
+			t.Error("Expected tag browser to be open")
+		}
+
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for 't' key")
+		}
+
+		tagsMsg, ok := cmd().(commands.ListTagsMsg)
+		if !ok {
+			t.Fatal("Expected ListTagsMsg from 't' key command")
+		}
+
+		if len(tagsMsg.Tags) != 2 || tagsMsg.Tags[0].Name != "meeting" || tagsMsg.Tags[0].Count != 2 {
+			t.Errorf("Expected meeting and team tags, got %v", tagsMsg.Tags)
+		}
+	})
+
+	t.Run("selecting a tag loads the tagged notes", func(t *testing.T) {
+		notes := []core.Note{
+			core.NewNote("standup.md", "# Standup\n#meeting"),
+			core.NewNote("groceries.md", "# Groceries"),
+		}
+		repo := core.NewMemoryRepository(notes...)
+		component := NewComponent(repo)
+		component.BackgroundUpdate(commands.ListNotesMsg{Notes: notes})
+
+		component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+		component.BackgroundUpdate(commands.ListTagsMsg{Tags: []core.Tag{{Name: "meeting", Count: 1}}})
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEnter})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for Enter key")
+		}
+
+		if component.browsingTags {
+			t.Error("Expected tag browser to be closed after selecting a tag")
+		}
+
+		taggedMsg, ok := cmd().(commands.ListTaggedNotesMsg)
+		if !ok {
+			t.Fatal("Expected ListTaggedNotesMsg from Enter key command")
+		}
+
+		component.BackgroundUpdate(taggedMsg)
+
+		if component.activeTag != "meeting" {
+			t.Errorf("Expected active tag 'meeting', got '%s'", component.activeTag)
+		}
+
+		items := component.list.Items()
+		if len(items) != 1 || items[0].(core.Note).Title() != "standup" {
+			t.Errorf("Expected only the standup note, got %v", items)
+		}
+	})
+
+	t.Run("Escape key clears the active tag", func(t *testing.T) {
+		notes := []core.Note{core.NewNote("standup.md", "#meeting"), core.NewNote("groceries.md", "")}
+		repo := core.NewMemoryRepository(notes...)
+		component := NewComponent(repo)
+		component.BackgroundUpdate(commands.ListTaggedNotesMsg{Tag: "meeting", Notes: notes[:1]})
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEsc})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for Escape key")
+		}
+
+		listMsg, ok := loadAll(&component, cmd).(commands.ListNotesMsg)
+		if !ok {
+			t.Fatal("Expected ListNotesMsg from Escape key command")
+		}
+
+		component.BackgroundUpdate(listMsg)
+
+		if component.activeTag != "" {
+			t.Error("Expected active tag to be cleared")
+		}
+
+		if len(component.list.Items()) != 2 {
+			t.Errorf("Expected 2 items in list, got %d", len(component.list.Items()))
+		}
+	})
+}
+
+func TestListComponentNoteOperations(t *testing.T) {
+	t.Run("'x' key asks to delete the selected note", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+		component.BackgroundUpdate(commands.ListNotesMsg{Notes: []core.Note{core.NewNote("note1.md", "")}})
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for 'x' key")
+		}
+
+		promptMsg, ok := cmd().(commands.DeleteNotePromptMsg)
+		if !ok {
+			t.Fatal("Expected DeleteNotePromptMsg from 'x' key command")
+		}
+
+		if promptMsg.Note.Title() != "note1" {
+			t.Errorf("Expected note title 'note1', got '%s'", promptMsg.Note.Title())
+		}
+	})
+
+	t.Run("'r' and 'm' keys do nothing on an empty list", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+
+		for _, r := range []rune{'r', 'm'} {
+			cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			if cmd != nil {
+				if _, ok := cmd().(commands.RenameNotePromptMsg); ok {
+					t.Error("Expected no RenameNotePromptMsg on an empty list")
+				}
+				if _, ok := cmd().(commands.MoveNotePromptMsg); ok {
+					t.Error("Expected no MoveNotePromptMsg on an empty list")
+				}
+			}
+		}
+	})
+
+	t.Run("a deleted note is removed from the list", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+
+		note1 := core.NewNote("note1.md", "")
+		note2 := core.NewNote("note2.md", "")
+		component.BackgroundUpdate(commands.ListNotesMsg{Notes: []core.Note{note1, note2}})
+		component.BackgroundUpdate(commands.NotesChangedMsg{Changes: []core.NoteChange{{Kind: core.NoteDeleted, Note: note1}}})
+
+		items := component.list.Items()
+		if len(items) != 1 || items[0].(core.Note).Title() != "note2" {
+			t.Errorf("Expected only note2 to remain, got %v", items)
+		}
+	})
+
+	t.Run("a renamed note keeps its place in the list", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+
+		note1 := core.NewNote("note1.md", "")
+		note2 := core.NewNote("note2.md", "")
+		component.BackgroundUpdate(commands.ListNotesMsg{Notes: []core.Note{note1, note2}})
+		component.BackgroundUpdate(commands.NotesChangedMsg{Changes: []core.NoteChange{
+			{Kind: core.NoteRenamed, Note: core.NewNote("renamed.md", ""), Previous: note1},
+		}})
+
+		items := component.list.Items()
+		if len(items) != 2 || items[0].(core.Note).Title() != "renamed" {
+			t.Errorf("Expected the renamed note first in the list, got %v", items)
+		}
+	})
+}
+
+func TestListComponentNotesChanged(t *testing.T) {
+	t.Run("applies created, updated and deleted notes", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+
+		note1 := core.NewNote("note1.md", "old")
+		note2 := core.NewNote("note2.md", "")
+		component.BackgroundUpdate(commands.ListNotesMsg{Notes: []core.Note{note1, note2}})
+
+		component.BackgroundUpdate(commands.NotesChangedMsg{Changes: []core.NoteChange{
+			{Kind: core.NoteUpdated, Note: core.NewNote("note1.md", "new")},
+			{Kind: core.NoteDeleted, Note: note2},
+			{Kind: core.NoteCreated, Note: core.NewNote("note3.md", "")},
+		}})
+
+		items := component.list.Items()
+		if len(items) != 2 {
+			t.Fatalf("Expected 2 notes, got %d", len(items))
+		}
+		if items[0].(core.Note).FileContent() != "new" {
+			t.Errorf("Expected note1 to be updated in place, got '%s'", items[0].(core.Note).FileContent())
+		}
+		if items[1].(core.Note).Title() != "note3" {
+			t.Errorf("Expected note3 to be added, got '%s'", items[1].(core.Note).Title())
+		}
+	})
+
+	t.Run("a note created in the app and reported by the watcher is listed once", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+
+		note := core.NewNote("note1.md", "")
+		component.BackgroundUpdate(commands.NotesChangedMsg{Changes: []core.NoteChange{{Kind: core.NoteCreated, Note: note}}})
+		component.BackgroundUpdate(commands.NotesChangedMsg{Changes: []core.NoteChange{{Kind: core.NoteCreated, Note: note}}})
+
+		if len(component.list.Items()) != 1 {
+			t.Errorf("Expected the note to be listed once, got %d items", len(component.list.Items()))
+		}
+	})
+
+	t.Run("notes leaving the active tag are removed", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+
+		note := core.NewNote("note1.md", "#work")
+		component.BackgroundUpdate(commands.ListTaggedNotesMsg{Tag: "work", Notes: []core.Note{note}})
+		component.BackgroundUpdate(commands.NotesChangedMsg{Changes: []core.NoteChange{
+			{Kind: core.NoteUpdated, Note: core.NewNote("note1.md", "no tags")},
+			{Kind: core.NoteCreated, Note: core.NewNote("note2.md", "")},
+		}})
+
+		if len(component.list.Items()) != 0 {
+			t.Errorf("Expected untagged notes to be left out, got %d items", len(component.list.Items()))
+		}
+	})
+}
+
+func TestListComponentChangeFeed(t *testing.T) {
+	t.Run("changes made through the repository reach the list", func(t *testing.T) {
+		ctx := context.Background()
+		feed := core.NewChangeFeed()
+		defer feed.Close()
+		changes, unsubscribe := feed.Subscribe()
+		defer unsubscribe()
+
+		repo := core.ChainRepository(core.NewMemoryRepository(), core.WithChangeFeed(feed))
+		component := NewComponent(repo)
+		component.BackgroundUpdate(component.Init()())
+
+		note, _ := repo.CreateEmptyNote(ctx, "draft")
+		_, _ = repo.RenameNote(ctx, note, "final")
+
+		for len(component.list.Items()) == 0 || component.list.Items()[0].(core.Note).Title() != "final" {
+			component.BackgroundUpdate(commands.ListenForChanges(changes)())
+		}
+
+		if len(component.list.Items()) != 1 {
+			t.Errorf("Expected only the renamed note, got %v", component.list.Items())
+		}
+	})
+}
+
+func TestListComponentReadOnly(t *testing.T) {
+	t.Run("OpenVaultMsg marks the list read-only", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+
+		component.BackgroundUpdate(commands.OpenVaultMsg{ReadOnly: true})
+		component.BackgroundUpdate(commands.ListTaggedNotesMsg{Tag: "work"})
+
+		if component.list.Title != title+" #work (read-only)" {
+			t.Errorf("Expected the title to show the tag and read-only, got '%s'", component.list.Title)
+		}
+	})
+}
+
+func TestListComponentCancellation(t *testing.T) {
+	t.Run("a newer load supersedes the in-flight one", func(t *testing.T) {
+		repo := core.NewMemoryRepository(core.NewNote("note1.md", ""))
+		component := NewComponent(repo)
+
+		first := component.Init()
+		second := component.Init()
+
+		if msg := first(); msg != nil {
+			t.Errorf("Expected the superseded load to return no message, got %T", msg)
+		}
+
+		if _, ok := loadAll(&component, second).(commands.ListNotesMsg); !ok {
+			t.Error("Expected the latest load to return ListNotesMsg")
+		}
+	})
+
+	t.Run("timeouts are shown to the user", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		repo.FailWith(context.DeadlineExceeded)
+		component := NewComponent(repo)
+		component.BackgroundUpdate(tea.WindowSizeMsg{Width: 120, Height: 20})
+
+		msg := component.Init()()
+		listMsg, ok := msg.(commands.ListNotesMsg)
+		if !ok || listMsg.Err == nil {
+			t.Fatal("Expected ListNotesMsg with an error")
+		}
+
+		component.BackgroundUpdate(listMsg)
+
+		if !strings.Contains(component.View(), "did not respond in time") {
+			t.Errorf("Expected the timeout to be shown, got '%s'", component.View())
+		}
+	})
+}
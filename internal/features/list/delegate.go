@@ -0,0 +1,33 @@
+package list
+
+import (
+	"elephant/internal/core"
+	"github.com/charmbracelet/bubbles/list"
+	"io"
+)
+
+// noteDelegate - renders notes like the default delegate, prefixing the title with the note folder
+type noteDelegate struct {
+	list.DefaultDelegate
+}
+
+func newNoteDelegate() noteDelegate {
+	return noteDelegate{DefaultDelegate: list.NewDefaultDelegate()}
+}
+
+func (d noteDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if note, ok := item.(core.Note); ok && note.Folder() != "" {
+		item = folderItem{Note: note}
+	}
+
+	d.DefaultDelegate.Render(w, m, index, item)
+}
+
+// folderItem - a note shown with its folder, kept in sync with core.Note.FilterValue so filter matches line up
+type folderItem struct {
+	core.Note
+}
+
+func (f folderItem) Title() string {
+	return f.Folder() + "/" + f.Note.Title()
+}
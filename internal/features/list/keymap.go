@@ -5,8 +5,18 @@ import (
 )
 
 type componentKeyMap struct {
-	addNote  key.Binding
-	viewNote key.Binding
+	addNote    key.Binding
+	viewNote   key.Binding
+	deleteNote key.Binding
+	renameNote key.Binding
+	moveNote   key.Binding
+	showTrash  key.Binding
+	search     key.Binding
+	showTasks  key.Binding
+	showAgenda key.Binding
+	browseTags key.Binding
+	selectTag  key.Binding
+	clearTag   key.Binding
 }
 
 func newComponentKeyMap() componentKeyMap {
@@ -19,6 +29,46 @@ func newComponentKeyMap() componentKeyMap {
 			key.WithKeys("enter", " "),
 			key.WithHelp("enter/space", "view note"),
 		),
+		deleteNote: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "delete note"),
+		),
+		renameNote: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "rename note"),
+		),
+		moveNote: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "move note"),
+		),
+		showTrash: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "trash"),
+		),
+		search: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "search contents"),
+		),
+		showTasks: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "tasks"),
+		),
+		showAgenda: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "agenda"),
+		),
+		browseTags: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "browse tags"),
+		),
+		selectTag: key.NewBinding(
+			key.WithKeys("enter", " "),
+			key.WithHelp("enter/space", "show notes with tag"),
+		),
+		clearTag: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "clear tag / close tags"),
+		),
 	}
 
 	return km
@@ -28,5 +78,20 @@ func (a componentKeyMap) getListOfBindings() []key.Binding {
 	return []key.Binding{
 		a.addNote,
 		a.viewNote,
+		a.deleteNote,
+		a.renameNote,
+		a.moveNote,
+		a.showTrash,
+		a.search,
+		a.showTasks,
+		a.showAgenda,
+		a.browseTags,
+	}
+}
+
+func (a componentKeyMap) getListOfTagBindings() []key.Binding {
+	return []key.Binding{
+		a.selectTag,
+		a.clearTag,
 	}
 }
@@ -0,0 +1,68 @@
+package list
+
+import (
+	"context"
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	"errors"
+	tea "github.com/charmbracelet/bubbletea"
+	"log/slog"
+	"time"
+)
+
+// noteStream - the notes of a load in progress, delivered a batch at a time and then all together once loaded
+type noteStream struct {
+	batches chan []core.Note
+	done    chan tea.Msg
+}
+
+func newNoteStream() *noteStream {
+	return &noteStream{batches: make(chan []core.Note), done: make(chan tea.Msg, 1)}
+}
+
+// run - load the notes of repository, handing every batch on until ctx is done; the load fails once the repository
+// hands no batch for commands.OperationTimeout, not counting the time the list takes to take a batch
+func (s *noteStream) run(ctx context.Context, repository core.Repository) {
+	defer close(s.batches)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	stalled := time.AfterFunc(commands.OperationTimeout, func() { cancel(context.DeadlineExceeded) })
+
+	var notes []core.Note
+	err := core.StreamNotes(ctx, repository, func(batch []core.Note) {
+		stalled.Stop()
+		notes = append(notes, batch...)
+
+		select {
+		case s.batches <- batch:
+		case <-ctx.Done():
+		}
+		stalled.Reset(commands.OperationTimeout)
+	})
+	stalled.Stop()
+
+	if err != nil && errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+		err = context.DeadlineExceeded
+	}
+
+	switch {
+	case commands.IsSuperseded(err):
+		s.done <- nil
+	case err != nil:
+		slog.Error("failed to load notes", "error", err)
+		s.done <- commands.ListNotesMsg{Err: err}
+	default:
+		core.SortNotes(notes)
+		s.done <- commands.ListNotesMsg{Notes: notes}
+	}
+}
+
+// next - wait for the next batch, or for all the notes once there are no more batches
+func (s *noteStream) next() tea.Msg {
+	if batch, ok := <-s.batches; ok {
+		return notesBatchMsg{stream: s, notes: batch}
+	}
+
+	return <-s.done
+}
@@ -0,0 +1,26 @@
+package list
+
+import (
+	"elephant/internal/core"
+	"fmt"
+)
+
+// tagItem - a tag shown in the tag browser
+type tagItem struct {
+	tag core.Tag
+}
+
+func (t tagItem) Title() string {
+	return "#" + t.tag.Name
+}
+
+func (t tagItem) Description() string {
+	if t.tag.Count == 1 {
+		return "1 note"
+	}
+	return fmt.Sprintf("%d notes", t.tag.Count)
+}
+
+func (t tagItem) FilterValue() string {
+	return t.tag.Name
+}
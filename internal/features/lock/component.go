@@ -0,0 +1,70 @@
+package lock
+
+import (
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	"elephant/internal/theme"
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"time"
+)
+
+// Component - asks how to open a vault that another instance holds the lock of
+type Component struct {
+	width, height int
+	keys          componentKeyMap
+	owner         core.LockInfo
+}
+
+func NewComponent(owner core.LockInfo) Component {
+	return Component{
+		keys:  newComponentKeyMap(),
+		owner: owner,
+	}
+}
+
+func (lc *Component) Init() tea.Cmd {
+	return nil
+}
+
+func (lc *Component) BackgroundUpdate(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		h, v := theme.Style.GetFrameSize()
+		lc.width = msg.Width - h
+		lc.height = msg.Height - v
+	}
+
+	return nil
+}
+
+func (lc *Component) ForegroundUpdate(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(keyMsg, lc.keys.readOnly):
+			return func() tea.Msg {
+				return commands.OpenVaultMsg{ReadOnly: true}
+			}
+		case key.Matches(keyMsg, lc.keys.shared):
+			return func() tea.Msg {
+				return commands.OpenVaultMsg{ReadOnly: false}
+			}
+		case key.Matches(keyMsg, lc.keys.quit):
+			return tea.Quit
+		}
+	}
+
+	return nil
+}
+
+func (lc *Component) View() string {
+	content := fmt.Sprintf(
+		"Vault In Use\n\nAnother elephant instance (pid %d on %s) has been using this vault since %s.\n\n"+
+			"Press r to open it read-only,\n"+
+			"s to share it, blocking only notes that are open in the other instance,\n"+
+			"or q to quit",
+		lc.owner.PID, lc.owner.Host, lc.owner.AcquiredAt.Format(time.DateTime),
+	)
+
+	return theme.Style.Width(lc.width).Height(lc.height).Render(content)
+}
@@ -0,0 +1,53 @@
+package lock
+
+import (
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	tea "github.com/charmbracelet/bubbletea"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockComponent(t *testing.T) {
+	owner := core.LockInfo{PID: 42, Host: "laptop", AcquiredAt: time.Now()}
+
+	t.Run("shows who holds the vault", func(t *testing.T) {
+		component := NewComponent(owner)
+		component.BackgroundUpdate(tea.WindowSizeMsg{Width: 120, Height: 20})
+
+		if !strings.Contains(component.View(), "pid 42 on laptop") {
+			t.Errorf("Expected the lock owner to be shown, got '%s'", component.View())
+		}
+	})
+
+	t.Run("'r' opens the vault read-only and 's' shares it", func(t *testing.T) {
+		component := NewComponent(owner)
+
+		tests := map[rune]bool{'r': true, 's': false}
+		for r, readOnly := range tests {
+			cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			if cmd == nil {
+				t.Fatalf("Expected a command for '%c'", r)
+			}
+
+			msg, ok := cmd().(commands.OpenVaultMsg)
+			if !ok || msg.ReadOnly != readOnly {
+				t.Errorf("Expected OpenVaultMsg{ReadOnly: %v} for '%c', got %v", readOnly, r, msg)
+			}
+		}
+	})
+
+	t.Run("'q' quits", func(t *testing.T) {
+		component := NewComponent(owner)
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+		if cmd == nil {
+			t.Fatal("Expected a command for 'q'")
+		}
+
+		if _, ok := cmd().(tea.QuitMsg); !ok {
+			t.Error("Expected tea.QuitMsg from 'q'")
+		}
+	})
+}
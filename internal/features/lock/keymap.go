@@ -0,0 +1,28 @@
+package lock
+
+import "github.com/charmbracelet/bubbles/key"
+
+type componentKeyMap struct {
+	readOnly key.Binding
+	shared   key.Binding
+	quit     key.Binding
+}
+
+func newComponentKeyMap() componentKeyMap {
+	km := componentKeyMap{
+		readOnly: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "open read-only"),
+		),
+		shared: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "share with per-note locks"),
+		),
+		quit: key.NewBinding(
+			key.WithKeys("q", "esc", "ctrl+c"),
+			key.WithHelp("q", "quit"),
+		),
+	}
+
+	return km
+}
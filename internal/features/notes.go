@@ -1,14 +1,34 @@
 package features
 
 import (
+	"context"
 	"elephant/internal/core"
 	"elephant/internal/features/add"
+	"elephant/internal/features/agenda"
 	"elephant/internal/features/commands"
+	"elephant/internal/features/conflict"
+	"elephant/internal/features/dialog"
 	"elephant/internal/features/edit"
+	"elephant/internal/features/history"
 	"elephant/internal/features/list"
+	"elephant/internal/features/lock"
+	"elephant/internal/features/search"
+	"elephant/internal/features/tasks"
+	"elephant/internal/features/trash"
 	"elephant/internal/features/view"
+	"errors"
 	tea "github.com/charmbracelet/bubbletea"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTrashMaxAge      = 30 * 24 * time.Hour
+	defaultOperationTimeout = 10 * time.Second
+	defaultWatchInterval    = 2 * time.Second
 )
 
 type State int
@@ -18,32 +38,202 @@ const (
 	ViewState
 	EditState
 	AddState
+	DialogState
+	TrashState
+	ConflictState
+	LockState
+	HistoryState
+	SearchState
+	TasksState
+	AgendaState
 )
 
 type NotesFeature struct {
-	State         State
-	listComponent *list.Component
-	viewComponent *view.Component
-	editComponent *edit.Component
-	addComponent  *add.Component
+	State             State
+	listComponent     *list.Component
+	viewComponent     *view.Component
+	editComponent     *edit.Component
+	addComponent      *add.Component
+	dialogComponent   *dialog.Component
+	trashComponent    *trash.Component
+	conflictComponent *conflict.Component
+	lockComponent     *lock.Component
+	historyComponent  *history.Component
+	searchComponent   *search.Component
+	tasksComponent    *tasks.Component
+	agendaComponent   *agenda.Component
+
+	repository core.RepositoryWrapper
+	readOnly   *core.ReadOnlyGuard
+	cache      *core.NoteCache
+	counters   *core.OperationCounters
+	git        *core.GitRepository
+	database   *core.SQLiteRepository
+	vaultLock  *core.VaultLock
+	noteLocks  *core.NoteLocks
+	watcher    *core.Watcher
+	feed       *core.ChangeFeed
+	changes    <-chan []core.NoteChange
 }
 
-func NewFeature() NotesFeature {
-	notesDirectory := getNotesDirectory()
+func NewFeature() (NotesFeature, error) {
+	location, err := NotesLocation()
+	if err != nil {
+		return NotesFeature{}, err
+	}
+	commands.OperationTimeout = getOperationTimeout()
+
+	// the directory holding the vault, where the locks of its instances are kept
+	vaultDirectory := location.Path
+
+	var storage core.Repository
+	var gitRepository *core.GitRepository
+	var database *core.SQLiteRepository
+	var watcher *core.Watcher
+
+	switch location.Backend {
+	case core.BackendSQLite:
+		database, err = core.NewSQLiteRepository(location.Path)
+		if err != nil {
+			return NotesFeature{}, err
+		}
+		storage = database
+		vaultDirectory = filepath.Dir(location.Path)
+	default:
+		noteRepository := core.NewNoteRepository(location.Path)
+		storage = &noteRepository
+		if gitRepository = newGitRepository(&noteRepository, location.Path); gitRepository != nil {
+			// the commits keep the history of the notes
+			noteRepository.DisableHistory()
+			storage = gitRepository
+		}
+		watcher = newWatcher(location.Path)
+	}
+
+	noteLocks := core.NewNoteLocks(vaultDirectory)
+	readOnly := &core.ReadOnlyGuard{}
+	counters := core.NewOperationCounters()
+	feed := core.NewChangeFeed()
+	linkGraph := core.NewLinkGraph()
+
+	middlewares := []core.Middleware{
+		core.WithLogging(slog.Default()),
+		core.WithCounters(counters),
+		core.WithReadOnly(readOnly),
+		core.WithNoteLocks(noteLocks),
+		core.WithChangeFeed(feed),
+		core.WithLinkGraph(linkGraph),
+	}
+
+	// repositories that cannot search their notes get an index, kept up to date through the change feed like the
+	// link graph
+	var searchIndex *core.SearchIndex
+	if _, ok := storage.(core.Searcher); !ok {
+		searchIndex = core.NewSearchIndex()
+		middlewares = append(middlewares, core.WithSearchIndex(searchIndex))
+	}
+
+	// without a watcher nothing tells the cache about changes made outside the application
+	var cache *core.NoteCache
+	if watcher != nil {
+		cache = core.NewNoteCache()
+		middlewares = append(middlewares, core.WithCache(cache))
+	}
+	if faults := getFaults(); faults != nil {
+		middlewares = append(middlewares, core.WithFaults(faults))
+	}
+
+	repository := core.ChainRepository(storage, middlewares...)
+
+	state := ListState
+	var owner core.LockInfo
+	vaultLock, err := core.AcquireVaultLock(vaultDirectory)
+	var locked *core.LockedError
+	if errors.As(err, &locked) {
+		// stay read-only until the user chose how to share the vault
+		state = LockState
+		owner = locked.Owner
+		readOnly.SetReadOnly(true)
+	} else if err != nil {
+		slog.Error("failed to lock the notes directory, continuing without the lock", "error", err)
+	}
+
+	listComponent := list.NewComponent(repository)
+	viewComponent := view.NewComponent(repository)
+	editComponent := edit.NewComponent(repository)
+	addComponent := add.NewComponent(repository)
+	dialogComponent := dialog.NewComponent(repository)
+	trashComponent := trash.NewComponent(repository, getTrashMaxAge())
+	conflictComponent := conflict.NewComponent(repository)
+	lockComponent := lock.NewComponent(owner)
+	historyComponent := history.NewComponent(repository)
+	searchComponent := search.NewComponent(repository)
+	tasksComponent := tasks.NewComponent(repository)
+	agendaComponent := agenda.NewComponent(repository)
 
-	repository := core.NewNoteRepository(notesDirectory)
-	listComponent := list.NewComponent(&repository)
-	viewComponent := view.NewComponent(&repository)
-	editComponent := edit.NewComponent(&repository)
-	addComponent := add.NewComponent(&repository)
+	if watcher != nil {
+		go followWatcher(watcher, cache, feed)
+	}
+	changes, _ := feed.Subscribe()
+	linkChanges, _ := feed.Subscribe()
+	go linkGraph.Follow(linkChanges)
+	go func() { _ = linkGraph.Build(context.Background(), storage) }()
+	if searchIndex != nil {
+		searchChanges, _ := feed.Subscribe()
+		go searchIndex.Follow(searchChanges)
+		go func() { _ = searchIndex.Build(context.Background(), storage) }()
+	}
 
 	return NotesFeature{
-		State:         ListState,
-		listComponent: &listComponent,
-		viewComponent: &viewComponent,
-		editComponent: &editComponent,
-		addComponent:  &addComponent,
+		State:             state,
+		listComponent:     &listComponent,
+		viewComponent:     &viewComponent,
+		editComponent:     &editComponent,
+		addComponent:      &addComponent,
+		dialogComponent:   &dialogComponent,
+		trashComponent:    &trashComponent,
+		conflictComponent: &conflictComponent,
+		lockComponent:     &lockComponent,
+		historyComponent:  &historyComponent,
+		searchComponent:   &searchComponent,
+		tasksComponent:    &tasksComponent,
+		agendaComponent:   &agendaComponent,
+		repository:        repository,
+		readOnly:          readOnly,
+		cache:             cache,
+		counters:          counters,
+		git:               gitRepository,
+		database:          database,
+		vaultLock:         vaultLock,
+		noteLocks:         noteLocks,
+		watcher:           watcher,
+		feed:              feed,
+		changes:           changes,
+	}, nil
+}
+
+// Close - stop watching the vault, finish pending commits and release the locks held on it
+func (nf *NotesFeature) Close() error {
+	var errs []error
+
+	if nf.watcher != nil {
+		errs = append(errs, nf.watcher.Close())
+	}
+	nf.feed.Close()
+	if nf.git != nil {
+		errs = append(errs, nf.git.Close())
 	}
+	if nf.database != nil {
+		errs = append(errs, nf.database.Close())
+	}
+	if nf.vaultLock != nil {
+		errs = append(errs, nf.vaultLock.Release())
+	}
+	errs = append(errs, nf.noteLocks.Close())
+
+	slog.Info("repository calls", "counts", nf.counters)
+
+	return errors.Join(errs...)
 }
 
 func (nf *NotesFeature) Init() tea.Cmd {
@@ -52,6 +242,15 @@ func (nf *NotesFeature) Init() tea.Cmd {
 		nf.viewComponent.Init(),
 		nf.editComponent.Init(),
 		nf.addComponent.Init(),
+		nf.dialogComponent.Init(),
+		nf.trashComponent.Init(),
+		nf.conflictComponent.Init(),
+		nf.lockComponent.Init(),
+		nf.historyComponent.Init(),
+		nf.searchComponent.Init(),
+		nf.tasksComponent.Init(),
+		nf.agendaComponent.Init(),
+		commands.ListenForChanges(nf.changes),
 	)
 }
 
@@ -77,6 +276,73 @@ func (nf *NotesFeature) Update(msg tea.Msg) tea.Cmd {
 	if _, ok := msg.(commands.QuitAddNoteMsg); ok {
 		nf.State = ListState
 	}
+	if _, ok := msg.(commands.DeleteNotePromptMsg); ok {
+		nf.State = DialogState
+	}
+	if _, ok := msg.(commands.RenameNotePromptMsg); ok {
+		nf.State = DialogState
+	}
+	if _, ok := msg.(commands.MoveNotePromptMsg); ok {
+		nf.State = DialogState
+	}
+	if _, ok := msg.(commands.QuitDialogMsg); ok {
+		nf.State = ListState
+	}
+	if _, ok := msg.(commands.DeleteNoteMsg); ok {
+		nf.State = ListState
+	}
+	if _, ok := msg.(commands.RenameNoteMsg); ok {
+		nf.State = ListState
+	}
+	if _, ok := msg.(commands.MoveNoteMsg); ok {
+		nf.State = ListState
+	}
+	if _, ok := msg.(commands.ShowTrashMsg); ok {
+		nf.State = TrashState
+	}
+	if _, ok := msg.(commands.QuitTrashMsg); ok {
+		nf.State = ListState
+	}
+	if _, ok := msg.(commands.ConflictMsg); ok {
+		nf.State = ConflictState
+	}
+	if _, ok := msg.(commands.ResumeEditNoteMsg); ok {
+		nf.State = EditState
+	}
+	if _, ok := msg.(commands.ShowHistoryMsg); ok {
+		nf.State = HistoryState
+	}
+	if _, ok := msg.(commands.QuitHistoryMsg); ok {
+		nf.State = ViewState
+	}
+	if _, ok := msg.(commands.RestoreRevisionMsg); ok {
+		nf.State = ViewState
+	}
+	if _, ok := msg.(commands.ShowSearchMsg); ok {
+		nf.State = SearchState
+	}
+	if _, ok := msg.(commands.QuitSearchMsg); ok {
+		nf.State = ListState
+	}
+	if _, ok := msg.(commands.ShowTasksMsg); ok {
+		nf.State = TasksState
+	}
+	if _, ok := msg.(commands.QuitTasksMsg); ok {
+		nf.State = ListState
+	}
+	if _, ok := msg.(commands.ShowAgendaMsg); ok {
+		nf.State = AgendaState
+	}
+	if _, ok := msg.(commands.QuitAgendaMsg); ok {
+		nf.State = ListState
+	}
+	if msg, ok := msg.(commands.OpenVaultMsg); ok {
+		nf.readOnly.SetReadOnly(msg.ReadOnly)
+		nf.State = ListState
+	}
+	if _, ok := msg.(commands.NotesChangedMsg); ok {
+		cmds = append(cmds, commands.ListenForChanges(nf.changes))
+	}
 
 	switch nf.State {
 	case ListState:
@@ -91,6 +357,30 @@ func (nf *NotesFeature) Update(msg tea.Msg) tea.Cmd {
 	case AddState:
 		cmd = nf.addComponent.ForegroundUpdate(msg)
 		cmds = append(cmds, cmd)
+	case DialogState:
+		cmd = nf.dialogComponent.ForegroundUpdate(msg)
+		cmds = append(cmds, cmd)
+	case TrashState:
+		cmd = nf.trashComponent.ForegroundUpdate(msg)
+		cmds = append(cmds, cmd)
+	case ConflictState:
+		cmd = nf.conflictComponent.ForegroundUpdate(msg)
+		cmds = append(cmds, cmd)
+	case LockState:
+		cmd = nf.lockComponent.ForegroundUpdate(msg)
+		cmds = append(cmds, cmd)
+	case HistoryState:
+		cmd = nf.historyComponent.ForegroundUpdate(msg)
+		cmds = append(cmds, cmd)
+	case SearchState:
+		cmd = nf.searchComponent.ForegroundUpdate(msg)
+		cmds = append(cmds, cmd)
+	case TasksState:
+		cmd = nf.tasksComponent.ForegroundUpdate(msg)
+		cmds = append(cmds, cmd)
+	case AgendaState:
+		cmd = nf.agendaComponent.ForegroundUpdate(msg)
+		cmds = append(cmds, cmd)
 	}
 
 	cmd = nf.listComponent.BackgroundUpdate(msg)
@@ -105,6 +395,30 @@ func (nf *NotesFeature) Update(msg tea.Msg) tea.Cmd {
 	cmd = nf.addComponent.BackgroundUpdate(msg)
 	cmds = append(cmds, cmd)
 
+	cmd = nf.dialogComponent.BackgroundUpdate(msg)
+	cmds = append(cmds, cmd)
+
+	cmd = nf.trashComponent.BackgroundUpdate(msg)
+	cmds = append(cmds, cmd)
+
+	cmd = nf.conflictComponent.BackgroundUpdate(msg)
+	cmds = append(cmds, cmd)
+
+	cmd = nf.lockComponent.BackgroundUpdate(msg)
+	cmds = append(cmds, cmd)
+
+	cmd = nf.historyComponent.BackgroundUpdate(msg)
+	cmds = append(cmds, cmd)
+
+	cmd = nf.searchComponent.BackgroundUpdate(msg)
+	cmds = append(cmds, cmd)
+
+	cmd = nf.tasksComponent.BackgroundUpdate(msg)
+	cmds = append(cmds, cmd)
+
+	cmd = nf.agendaComponent.BackgroundUpdate(msg)
+	cmds = append(cmds, cmd)
+
 	return tea.Batch(cmds...)
 }
 
@@ -118,14 +432,163 @@ func (nf *NotesFeature) View() string {
 		return nf.editComponent.View()
 	case AddState:
 		return nf.addComponent.View()
+	case DialogState:
+		return nf.dialogComponent.View()
+	case TrashState:
+		return nf.trashComponent.View()
+	case ConflictState:
+		return nf.conflictComponent.View()
+	case LockState:
+		return nf.lockComponent.View()
+	case HistoryState:
+		return nf.historyComponent.View()
+	case SearchState:
+		return nf.searchComponent.View()
+	case TasksState:
+		return nf.tasksComponent.View()
+	case AgendaState:
+		return nf.agendaComponent.View()
 	default:
 		return "Could not render application"
 	}
 }
 
-func getNotesDirectory() string {
+// NotesLocation - where the notes are stored, given by ELEPHANT_NOTES_DIR as a path or a URL such as
+// sqlite:///home/me/notes.db, where plain paths use the ELEPHANT_BACKEND backend (file or sqlite)
+func NotesLocation() (core.Location, error) {
+	backend := core.BackendFile
+	if value := os.Getenv("ELEPHANT_BACKEND"); value != "" {
+		backend = core.Backend(value)
+	}
+
+	location := ".elephant"
 	if dir := os.Getenv("ELEPHANT_NOTES_DIR"); dir != "" {
-		return dir
+		location = dir
+	}
+
+	return core.ParseLocation(location, backend)
+}
+
+// getTrashMaxAge - how long deleted notes are kept before emptying the trash purges them
+func getTrashMaxAge() time.Duration {
+	if value := os.Getenv("ELEPHANT_TRASH_MAX_AGE"); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err == nil && maxAge >= 0 {
+			return maxAge
+		}
+		slog.Warn("invalid trash max age, using the default", "value", value, "error", err)
+	}
+	return defaultTrashMaxAge
+}
+
+// getOperationTimeout - how long a single repository call may take before the UI reports it as failed
+func getOperationTimeout() time.Duration {
+	if value := os.Getenv("ELEPHANT_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err == nil && timeout > 0 {
+			return timeout
+		}
+		slog.Warn("invalid timeout, using the default", "value", value, "error", err)
+	}
+	return defaultOperationTimeout
+}
+
+// followWatcher - publish the changes the watcher finds outside the application, dropping the cached notes first
+// since the cache only sees changes made through the repository
+func followWatcher(watcher *core.Watcher, cache *core.NoteCache, feed *core.ChangeFeed) {
+	for changes := range watcher.Changes() {
+		if cache != nil {
+			cache.Invalidate()
+		}
+		feed.Publish(changes...)
+	}
+}
+
+// newWatcher - watch the notes directory for changes made outside the application as configured by
+// ELEPHANT_WATCH (auto, poll or off), nil when watching is off or not possible
+func newWatcher(notesDirectory string) *core.Watcher {
+	mode := core.WatchAuto
+	switch value := os.Getenv("ELEPHANT_WATCH"); value {
+	case "", "auto":
+	case "poll":
+		mode = core.WatchPoll
+	case "off":
+		return nil
+	default:
+		slog.Warn("invalid watch mode, using auto", "value", value)
+	}
+
+	watcher, err := core.NewWatcher(notesDirectory, mode, getWatchInterval())
+	if err != nil {
+		slog.Error("failed to watch the notes directory", "error", err)
+		return nil
+	}
+
+	return watcher
+}
+
+// newGitRepository - commit every change to git when ELEPHANT_GIT is on, nil when it is off or git is not usable
+func newGitRepository(repository core.Repository, notesDirectory string) *core.GitRepository {
+	switch value := os.Getenv("ELEPHANT_GIT"); value {
+	case "", "off":
+		return nil
+	case "on":
+	default:
+		slog.Warn("invalid git mode, git is off", "value", value)
+		return nil
+	}
+
+	gitRepository, err := core.NewGitRepository(repository, notesDirectory)
+	if err != nil {
+		slog.Error("failed to use git for the notes directory, git is off", "error", err)
+		return nil
+	}
+
+	return gitRepository
+}
+
+// getFaults - the faults injected into repository calls as given by ELEPHANT_FAULTS, a comma separated list such as
+// SaveNote=fail,StreamNotes=2s where an op of * means every call, a duration delays the call and fail fails it;
+// nil when no faults are given
+func getFaults() *core.FaultInjector {
+	value := os.Getenv("ELEPHANT_FAULTS")
+	if value == "" {
+		return nil
+	}
+
+	faults := core.NewFaultInjector()
+	for _, spec := range strings.Split(value, ",") {
+		op, effect, _ := strings.Cut(strings.TrimSpace(spec), "=")
+		if op == "" {
+			slog.Warn("invalid fault, ignoring it", "value", spec)
+			continue
+		}
+
+		if effect == "fail" {
+			faults.Inject(op, core.Fault{Err: core.ErrInjectedFault})
+			continue
+		}
+
+		delay, err := time.ParseDuration(effect)
+		if err != nil {
+			slog.Warn("invalid fault, ignoring it", "value", spec, "error", err)
+			continue
+		}
+		faults.Inject(op, core.Fault{Delay: delay})
+	}
+
+	slog.Warn("injecting faults into repository calls", "faults", value)
+	return faults
+}
+
+// getWatchInterval - how often the notes directory is polled when filesystem notifications are not used
+func getWatchInterval() time.Duration {
+	if value := os.Getenv("ELEPHANT_WATCH_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err == nil && interval > 0 {
+			return interval
+		}
+		slog.Warn("invalid watch interval, using the default", "value", value, "error", err)
 	}
-	return ".elephant"
+	return defaultWatchInterval
 }
@@ -0,0 +1,281 @@
+package search
+
+import (
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	"elephant/internal/theme"
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxResults - how many of the best ranked notes are shown for a query
+	maxResults = 50
+	// snippetContext - how many bytes of the line around the first match a snippet shows on either side
+	snippetContext = 40
+)
+
+// searchResultsMsg - the notes found for query, best ranked first
+type searchResultsMsg struct {
+	query   string
+	results []result
+}
+
+// searchFailedMsg - the notes could not be searched
+type searchFailedMsg struct{ err error }
+
+// result - a note found by a search, with the text it matched first and a snippet of its content around it
+type result struct {
+	note    core.Note
+	match   string
+	snippet string
+}
+
+func (r result) Title() string {
+	if folder := r.note.Folder(); folder != "" {
+		return folder + "/" + r.note.Title()
+	}
+	return r.note.Title()
+}
+
+func (r result) Description() string {
+	if r.snippet != "" {
+		return r.snippet
+	}
+	return r.note.Description()
+}
+
+func (r result) FilterValue() string {
+	return r.note.Title()
+}
+
+type Component struct {
+	width, height int
+	textInput     textinput.Model
+	list          list.Model
+	keys          componentKeyMap
+	repository    core.Repository
+
+	query     string
+	searching commands.Operation
+}
+
+func NewComponent(repository core.Repository) Component {
+	keys := newComponentKeyMap()
+
+	ti := textinput.New()
+	ti.Placeholder = `Search notes: words, "a phrase", prefix*, OR, -excluded`
+	ti.Focus()
+
+	itemList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	itemList.Title = "Search"
+	itemList.SetFilteringEnabled(false)
+	itemList.SetShowStatusBar(false)
+	itemList.AdditionalFullHelpKeys = keys.getListOfBindings
+
+	return Component{
+		textInput:  ti,
+		list:       itemList,
+		keys:       keys,
+		repository: repository,
+	}
+}
+
+func (sc *Component) Init() tea.Cmd {
+	return nil
+}
+
+func (sc *Component) BackgroundUpdate(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := theme.Style.GetFrameSize()
+
+		sc.width = msg.Width - h
+		sc.height = msg.Height - v
+
+		sc.textInput.Width = sc.width
+		// the query takes up a line of its own and another one to set it apart from the results
+		sc.list.SetSize(sc.width, max(sc.height-2, 0))
+
+	case commands.ShowSearchMsg:
+		sc.textInput.Focus()
+		if sc.query != "" {
+			return sc.search(sc.query)
+		}
+
+	case commands.NotesChangedMsg:
+		if sc.query != "" {
+			return sc.search(sc.query)
+		}
+
+	case searchResultsMsg:
+		if msg.query != sc.query {
+			return nil
+		}
+
+		items := make([]list.Item, len(msg.results))
+		for i, result := range msg.results {
+			items[i] = result
+		}
+
+		return sc.list.SetItems(items)
+
+	case searchFailedMsg:
+		return sc.list.NewStatusMessage("Error: " + commands.DescribeError(msg.err))
+	}
+
+	return nil
+}
+
+func (sc *Component) ForegroundUpdate(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		sc.list, cmd = sc.list.Update(msg)
+		return cmd
+	}
+
+	switch {
+	case key.Matches(keyMsg, sc.keys.quitSearch):
+		sc.searching.Cancel()
+		return func() tea.Msg {
+			return commands.QuitSearchMsg{}
+		}
+	case key.Matches(keyMsg, sc.keys.openResult):
+		if selected, ok := sc.list.SelectedItem().(result); ok {
+			return func() tea.Msg {
+				return commands.ViewNoteMsg{Note: selected.note, Match: selected.match}
+			}
+		}
+		return nil
+	case key.Matches(keyMsg, sc.keys.moveCursor):
+		var cmd tea.Cmd
+		sc.list, cmd = sc.list.Update(msg)
+		return cmd
+	}
+
+	var cmd tea.Cmd
+	sc.textInput, cmd = sc.textInput.Update(msg)
+
+	if query := strings.TrimSpace(sc.textInput.Value()); query != sc.query {
+		sc.query = query
+		return tea.Batch(cmd, sc.search(query))
+	}
+
+	return cmd
+}
+
+// search - look for the notes matching query, reading the content of the best ranked ones for their snippets
+func (sc *Component) search(query string) tea.Cmd {
+	if query == "" {
+		sc.searching.Cancel()
+		return sc.list.SetItems(nil)
+	}
+
+	searcher, ok := sc.repository.(core.Searcher)
+	if !ok {
+		return func() tea.Msg {
+			return searchFailedMsg{err: fmt.Errorf("repository %T cannot search", sc.repository)}
+		}
+	}
+
+	ctx := sc.searching.Start()
+
+	return func() tea.Msg {
+		parsed, err := core.ParseQuery(query)
+		if err != nil {
+			return searchFailedMsg{err: err}
+		}
+
+		notes, err := searcher.SearchNotes(ctx, query)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to search notes", "query", query, "error", err)
+			return searchFailedMsg{err: err}
+		}
+
+		results := make([]result, 0, min(len(notes), maxResults))
+		for _, note := range notes[:min(len(notes), maxResults)] {
+			loaded, err := core.LoadNote(ctx, sc.repository, note)
+			if commands.IsSuperseded(err) {
+				return nil
+			}
+			if err != nil {
+				slog.Warn("failed to load search result", "file", note.RelativePath(), "error", err)
+				continue
+			}
+
+			body := loaded.Body()
+			matches := parsed.Matches(body)
+
+			found := result{note: loaded, snippet: snippet(body, matches)}
+			if len(matches) > 0 {
+				found.match = body[matches[0].Start:matches[0].End]
+			}
+			results = append(results, found)
+		}
+
+		return searchResultsMsg{query: query, results: results}
+	}
+}
+
+// snippet - the line of content holding the first match, cut down to snippetContext bytes on either side of it,
+// with the matches on it highlighted; empty when nothing matched
+func snippet(content string, matches []core.Match) string {
+	if len(matches) == 0 {
+		return ""
+	}
+
+	first := matches[0]
+	lineStart := strings.LastIndexByte(content[:first.Start], '\n') + 1
+	lineEnd := len(content)
+	if end := strings.IndexByte(content[first.Start:], '\n'); end >= 0 {
+		lineEnd = first.Start + end
+	}
+
+	start := max(lineStart, first.Start-snippetContext)
+	for start > lineStart && !utf8.RuneStart(content[start]) {
+		start--
+	}
+	end := min(lineEnd, first.End+snippetContext)
+	for end < lineEnd && !utf8.RuneStart(content[end]) {
+		end++
+	}
+
+	var b strings.Builder
+	if start > lineStart {
+		b.WriteString("…")
+	}
+
+	position := start
+	for _, match := range matches {
+		if match.Start < position || match.Start >= end {
+			continue
+		}
+
+		b.WriteString(content[position:match.Start])
+		b.WriteString(theme.MatchStyle.Render(content[match.Start:min(match.End, end)]))
+		position = min(match.End, end)
+	}
+	b.WriteString(content[position:end])
+
+	if end < lineEnd {
+		b.WriteString("…")
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+func (sc *Component) View() string {
+	content := sc.textInput.View() + "\n\n" + sc.list.View()
+
+	return theme.Style.Width(sc.width).Height(sc.height).Render(content)
+}
@@ -0,0 +1,167 @@
+package search
+
+import (
+	"context"
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	"elephant/internal/theme"
+	"github.com/charmbracelet/bubbles/cursor"
+	tea "github.com/charmbracelet/bubbletea"
+	"strings"
+	"testing"
+)
+
+func newSearchableRepository(t *testing.T, notes ...core.Note) core.RepositoryWrapper {
+	t.Helper()
+
+	index := core.NewSearchIndex()
+	repository := core.ChainRepository(core.NewMemoryRepository(notes...), core.WithSearchIndex(index))
+	if err := index.Build(context.Background(), repository); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	return repository
+}
+
+// typeQuery - type text into the search field, returning the message the search it started ends with
+func typeQuery(component *Component, text string) tea.Msg {
+	// a blinking cursor would hold up the batch the search runs in
+	component.textInput.Cursor.SetMode(cursor.CursorStatic)
+
+	cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+	if cmd == nil {
+		return nil
+	}
+
+	return findMsg(cmd())
+}
+
+// findMsg - the search message among msg and the messages of a batch, nil when there is none
+func findMsg(msg tea.Msg) tea.Msg {
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return msg
+	}
+
+	for _, cmd := range batch {
+		if cmd == nil {
+			continue
+		}
+		switch msg := cmd().(type) {
+		case searchResultsMsg, searchFailedMsg:
+			return msg
+		}
+	}
+
+	return nil
+}
+
+func TestSearchComponent(t *testing.T) {
+	repository := newSearchableRepository(t,
+		core.NewNote("elephants.md", "# Elephants\nElephants are large and grey, with long memories."),
+		core.NewNote("zoo/mice.md", "# Mice\nMice are small and grey."),
+		core.NewNote("cats.md", "# Cats\nCats ignore everything."),
+	)
+
+	t.Run("typing searches the notes, showing highlighted snippets", func(t *testing.T) {
+		component := NewComponent(repository)
+		component.BackgroundUpdate(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+		msg := typeQuery(&component, "grey")
+		results, ok := msg.(searchResultsMsg)
+		if !ok {
+			t.Fatalf("Expected searchResultsMsg, got %T", msg)
+		}
+		component.BackgroundUpdate(results)
+
+		items := component.list.Items()
+		if len(items) != 2 {
+			t.Fatalf("Expected 2 results, got %d", len(items))
+		}
+
+		for _, item := range items {
+			found := item.(result)
+			if !found.note.Loaded() {
+				t.Errorf("Expected %s to be loaded", found.note.RelativePath())
+			}
+			if found.match != "grey" || !strings.Contains(found.Description(), theme.MatchStyle.Render("grey")) {
+				t.Errorf("Expected the snippet of %s to highlight grey, got %q", found.Title(), found.Description())
+			}
+		}
+
+		titles := map[string]bool{}
+		for _, item := range items {
+			titles[item.(result).Title()] = true
+		}
+		if !titles["elephants"] || !titles["zoo/mice"] {
+			t.Errorf("Expected elephants and zoo/mice, got %v", titles)
+		}
+	})
+
+	t.Run("enter opens the selected note at its match", func(t *testing.T) {
+		component := NewComponent(repository)
+		component.BackgroundUpdate(typeQuery(&component, "\"long memories\""))
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEnter})
+		if cmd == nil {
+			t.Fatal("Expected enter to open the result")
+		}
+
+		viewMsg, ok := cmd().(commands.ViewNoteMsg)
+		if !ok {
+			t.Fatal("Expected ViewNoteMsg")
+		}
+		if viewMsg.Note.Title() != "elephants" || viewMsg.Match != "long memories" || !viewMsg.Note.Loaded() {
+			t.Errorf("Expected elephants opened at long memories, got %s at %q", viewMsg.Note.Title(), viewMsg.Match)
+		}
+	})
+
+	t.Run("results of an earlier query are ignored", func(t *testing.T) {
+		component := NewComponent(repository)
+
+		stale := typeQuery(&component, "grey")
+		component.BackgroundUpdate(typeQuery(&component, " cats"))
+		component.BackgroundUpdate(stale)
+
+		if items := component.list.Items(); len(items) != 0 {
+			t.Errorf("Expected no results for grey cats, got %d", len(items))
+		}
+	})
+
+	t.Run("invalid queries are reported", func(t *testing.T) {
+		component := NewComponent(repository)
+
+		if _, ok := typeQuery(&component, "-grey").(searchFailedMsg); !ok {
+			t.Error("Expected searchFailedMsg for a query only excluding notes")
+		}
+	})
+
+	t.Run("escape quits the search", func(t *testing.T) {
+		component := NewComponent(repository)
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEsc})
+		if cmd == nil {
+			t.Fatal("Expected escape to return a command")
+		}
+		if _, ok := cmd().(commands.QuitSearchMsg); !ok {
+			t.Error("Expected QuitSearchMsg")
+		}
+	})
+}
+
+func TestSnippet(t *testing.T) {
+	content := "# Title\n" + strings.Repeat("filler ", 20) + "the needle is here" + strings.Repeat(" filler", 20) + "\nneedle"
+
+	parsed, _ := core.ParseQuery("needle")
+	got := snippet(content, parsed.Matches(content))
+
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("Expected the snippet to be cut on both sides, got %q", got)
+	}
+	if strings.Count(got, theme.MatchStyle.Render("needle")) != 1 || strings.Contains(got, "\n") {
+		t.Errorf("Expected the snippet to highlight the needle on its own line once, got %q", got)
+	}
+	if snippet(content, nil) != "" {
+		t.Error("Expected no snippet without matches")
+	}
+}
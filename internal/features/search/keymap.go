@@ -0,0 +1,36 @@
+package search
+
+import "github.com/charmbracelet/bubbles/key"
+
+type componentKeyMap struct {
+	openResult key.Binding
+	moveCursor key.Binding
+	quitSearch key.Binding
+}
+
+func newComponentKeyMap() componentKeyMap {
+	km := componentKeyMap{
+		openResult: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "open note at match"),
+		),
+		moveCursor: key.NewBinding(
+			key.WithKeys("up", "down", "pgup", "pgdown"),
+			key.WithHelp("↑/↓", "choose result"),
+		),
+		quitSearch: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back to list"),
+		),
+	}
+
+	return km
+}
+
+func (a componentKeyMap) getListOfBindings() []key.Binding {
+	return []key.Binding{
+		a.openResult,
+		a.moveCursor,
+		a.quitSearch,
+	}
+}
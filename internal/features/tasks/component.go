@@ -0,0 +1,270 @@
+package tasks
+
+import (
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	"elephant/internal/theme"
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"log/slog"
+	"strings"
+)
+
+// status - which tasks the screen lists by their state
+type status int
+
+const (
+	openTasks status = iota
+	doneTasks
+	allTasks
+)
+
+func (s status) String() string {
+	switch s {
+	case doneTasks:
+		return "done"
+	case allTasks:
+		return "all"
+	default:
+		return "open"
+	}
+}
+
+// tasksMsg - the tasks of the vault, ordered by note and line
+type tasksMsg struct{ tasks []core.Task }
+
+// taskToggledMsg - task was checked or unchecked and its note saved
+type taskToggledMsg struct{ task core.Task }
+
+// tasksFailedMsg - a task operation failed
+type tasksFailedMsg struct{ err error }
+
+// taskItem - a task shown in the task list
+type taskItem struct {
+	core.Task
+}
+
+func (t taskItem) Title() string {
+	if t.Done {
+		return "[x] " + t.Text
+	}
+	return "[ ] " + t.Text
+}
+
+func (t taskItem) Description() string {
+	return fmt.Sprintf("%s:%d", t.Note.RelativePath(), t.Line)
+}
+
+// FilterValue - the text of the task with its note and tags, so filtering finds tasks by any of them
+func (t taskItem) FilterValue() string {
+	values := []string{t.Text, t.Note.RelativePath()}
+	for _, tag := range t.Note.Tags() {
+		values = append(values, "#"+tag)
+	}
+
+	return strings.Join(values, " ")
+}
+
+type Component struct {
+	width, height int
+	list          list.Model
+	keys          componentKeyMap
+	repository    core.Repository
+
+	// open - whether the screen is shown, when changes to the vault reload the tasks
+	open bool
+	// tasks - every task of the vault, which status and note narrow down to the listed ones
+	tasks  []core.Task
+	status status
+	note   string
+
+	loading   commands.Operation
+	operation commands.Operation
+}
+
+func NewComponent(repository core.Repository) Component {
+	keys := newComponentKeyMap()
+	itemList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	itemList.Title = "Tasks"
+	itemList.AdditionalFullHelpKeys = keys.getListOfBindings
+
+	return Component{
+		width:      itemList.Width(),
+		height:     itemList.Height(),
+		list:       itemList,
+		keys:       keys,
+		repository: repository,
+	}
+}
+
+func (tc *Component) Init() tea.Cmd {
+	return nil
+}
+
+func (tc *Component) loadTasks() tea.Cmd {
+	ctx := tc.loading.Start()
+
+	return func() tea.Msg {
+		notes, err := tc.repository.GetAllNotes(ctx)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to load tasks", "error", err)
+			return tasksFailedMsg{err: err}
+		}
+
+		return tasksMsg{tasks: core.CollectTasks(notes)}
+	}
+}
+
+func (tc *Component) BackgroundUpdate(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := theme.Style.GetFrameSize()
+
+		tc.width = msg.Width - h
+		tc.height = msg.Height - v
+
+		tc.list.SetSize(tc.width, tc.height)
+
+	case commands.ShowTasksMsg:
+		tc.open = true
+		return tc.loadTasks()
+
+	case commands.QuitTasksMsg:
+		tc.open = false
+
+	case commands.NotesChangedMsg:
+		if tc.open {
+			return tc.loadTasks()
+		}
+
+	case tasksMsg:
+		tc.tasks = msg.tasks
+		return tc.showTasks()
+
+	case taskToggledMsg:
+		for i, task := range tc.tasks {
+			if task.Note.RelativePath() == msg.task.Note.RelativePath() {
+				tc.tasks[i].Note = msg.task.Note
+				if task.Line == msg.task.Line {
+					tc.tasks[i].Done = msg.task.Done
+				}
+			}
+		}
+
+		verb := "Checked"
+		if !msg.task.Done {
+			verb = "Unchecked"
+		}
+		return tea.Batch(tc.showTasks(), tc.list.NewStatusMessage(verb+" "+msg.task.Text))
+
+	case tasksFailedMsg:
+		return tc.list.NewStatusMessage("Error: " + commands.DescribeError(msg.err))
+	}
+
+	return nil
+}
+
+// showTasks - list the tasks with the status and, when one is picked, in the note shown
+func (tc *Component) showTasks() tea.Cmd {
+	var items []list.Item
+	for _, task := range tc.tasks {
+		if tc.note != "" && task.Note.RelativePath() != tc.note {
+			continue
+		}
+		if (tc.status == openTasks && task.Done) || (tc.status == doneTasks && !task.Done) {
+			continue
+		}
+
+		items = append(items, taskItem{Task: task})
+	}
+
+	tc.list.Title = "Tasks: " + tc.status.String()
+	if tc.note != "" {
+		tc.list.Title += " in " + tc.note
+	}
+
+	return tc.list.SetItems(items)
+}
+
+func (tc *Component) ForegroundUpdate(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && tc.list.FilterState() != list.Filtering {
+		selectedItem, selected := tc.list.SelectedItem().(taskItem)
+
+		switch {
+		case key.Matches(keyMsg, tc.keys.toggleTask):
+			if selected {
+				return tc.toggleTask(selectedItem.Task)
+			}
+			return nil
+		case key.Matches(keyMsg, tc.keys.openTask):
+			if selected {
+				return tc.openTask(selectedItem.Task)
+			}
+			return nil
+		case key.Matches(keyMsg, tc.keys.cycleStatus):
+			tc.status = (tc.status + 1) % (allTasks + 1)
+			return tc.showTasks()
+		case key.Matches(keyMsg, tc.keys.filterNote):
+			switch {
+			case tc.note != "":
+				tc.note = ""
+			case selected:
+				tc.note = selectedItem.Note.RelativePath()
+			}
+			return tc.showTasks()
+		case key.Matches(keyMsg, tc.keys.quitTasks) && tc.list.FilterState() == list.Unfiltered:
+			tc.loading.Cancel()
+			return func() tea.Msg {
+				return commands.QuitTasksMsg{}
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	tc.list, cmd = tc.list.Update(msg)
+	return cmd
+}
+
+func (tc *Component) toggleTask(task core.Task) tea.Cmd {
+	ctx := tc.operation.Start()
+
+	return func() tea.Msg {
+		toggled, err := core.ToggleTask(ctx, tc.repository, task)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to toggle task", "error", err)
+			return tasksFailedMsg{err: err}
+		}
+
+		return taskToggledMsg{task: toggled}
+	}
+}
+
+// openTask - view the note of task, scrolled to the task
+func (tc *Component) openTask(task core.Task) tea.Cmd {
+	ctx := tc.operation.Start()
+
+	return func() tea.Msg {
+		loaded, err := core.LoadNote(ctx, tc.repository, task.Note)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to load note", "file", task.Note.FilePath(), "error", err)
+			return tasksFailedMsg{err: err}
+		}
+
+		return commands.ViewNoteMsg{Note: loaded, Match: task.Text}
+	}
+}
+
+func (tc *Component) View() string {
+	return theme.Style.Width(tc.width).Height(tc.height).Render(tc.list.View())
+}
@@ -0,0 +1,93 @@
+package tasks
+
+import (
+	"context"
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	tea "github.com/charmbracelet/bubbletea"
+	"strings"
+	"testing"
+)
+
+func newTestComponent(t *testing.T, notes ...core.Note) (Component, core.Repository) {
+	t.Helper()
+
+	repository := core.NewMemoryRepository(notes...)
+	component := NewComponent(repository)
+	component.BackgroundUpdate(tea.WindowSizeMsg{Width: 80, Height: 30})
+
+	cmd := component.BackgroundUpdate(commands.ShowTasksMsg{})
+	if cmd == nil {
+		t.Fatal("Expected ShowTasksMsg to load the tasks")
+	}
+	component.BackgroundUpdate(cmd())
+
+	return component, repository
+}
+
+func listedTasks(component Component) []string {
+	var texts []string
+	for _, item := range component.list.Items() {
+		texts = append(texts, item.(taskItem).Text)
+	}
+
+	return texts
+}
+
+func TestTasksComponentFilters(t *testing.T) {
+	component, _ := newTestComponent(t,
+		core.NewNote("menu.md", "---\ntags: [food]\n---\n- [ ] Soup\n- [x] Bread"),
+		core.NewNote("work.md", "- [ ] Report #urgent\n- [ ] Email"),
+	)
+
+	if texts := listedTasks(component); strings.Join(texts, ",") != "Soup,Report #urgent,Email" {
+		t.Errorf("Expected the open tasks of every note, got %v", texts)
+	}
+
+	component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if texts := listedTasks(component); strings.Join(texts, ",") != "Bread" {
+		t.Errorf("Expected the done tasks, got %v", texts)
+	}
+
+	component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if texts := listedTasks(component); strings.Join(texts, ",") != "Soup,Bread" || !strings.Contains(component.View(), "Tasks: all in menu.md") {
+		t.Errorf("Expected every task of the selected note, got %v", texts)
+	}
+
+	component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if texts := listedTasks(component); len(texts) != 4 {
+		t.Errorf("Expected n to show every note again, got %v", texts)
+	}
+
+	if item := component.list.Items()[0].(taskItem); !strings.Contains(item.FilterValue(), "#food") {
+		t.Errorf("Expected tasks to be filtered by the tags of their note, got %q", item.FilterValue())
+	}
+}
+
+func TestTasksComponentToggle(t *testing.T) {
+	component, repository := newTestComponent(t, core.NewNote("menu.md", "# Menu\n- [ ] Soup\n- [ ] Bread\n"))
+
+	cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if cmd == nil {
+		t.Fatal("Expected x to toggle the selected task")
+	}
+	component.BackgroundUpdate(cmd())
+
+	if texts := listedTasks(component); strings.Join(texts, ",") != "Bread" {
+		t.Errorf("Expected the checked task to leave the open tasks, got %v", texts)
+	}
+
+	menu, _ := repository.GetNoteByTitle(context.Background(), "menu")
+	if menu.FileContent() != "# Menu\n- [x] Soup\n- [ ] Bread\n" {
+		t.Errorf("Expected only the checkbox to be saved, got %q", menu.FileContent())
+	}
+
+	cmd = component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected enter to open the note of the task")
+	}
+	if viewMsg, ok := cmd().(commands.ViewNoteMsg); !ok || viewMsg.Note.Title() != "menu" || viewMsg.Match != "Bread" {
+		t.Errorf("Expected the note opened at the task, got %+v", viewMsg)
+	}
+}
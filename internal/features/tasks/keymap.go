@@ -0,0 +1,48 @@
+package tasks
+
+import "github.com/charmbracelet/bubbles/key"
+
+type componentKeyMap struct {
+	toggleTask  key.Binding
+	openTask    key.Binding
+	cycleStatus key.Binding
+	filterNote  key.Binding
+	quitTasks   key.Binding
+}
+
+func newComponentKeyMap() componentKeyMap {
+	km := componentKeyMap{
+		toggleTask: key.NewBinding(
+			key.WithKeys(" ", "x"),
+			key.WithHelp("space/x", "toggle task"),
+		),
+		openTask: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "open note at task"),
+		),
+		cycleStatus: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "open / done / all"),
+		),
+		filterNote: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "only this note / every note"),
+		),
+		quitTasks: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back to list note"),
+		),
+	}
+
+	return km
+}
+
+func (a componentKeyMap) getListOfBindings() []key.Binding {
+	return []key.Binding{
+		a.toggleTask,
+		a.openTask,
+		a.cycleStatus,
+		a.filterNote,
+		a.quitTasks,
+	}
+}
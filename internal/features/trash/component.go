@@ -0,0 +1,268 @@
+package trash
+
+import (
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	"elephant/internal/theme"
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"log/slog"
+	"time"
+)
+
+type action int
+
+const (
+	noAction action = iota
+	purgeAction
+	emptyAction
+)
+
+// trashFailedMsg - a trash operation failed
+type trashFailedMsg struct{ err error }
+
+// trashEmptiedMsg - the old notes were purged from the trash
+type trashEmptiedMsg struct{ count int }
+
+// trashItem - a trashed note shown in the trash list
+type trashItem struct {
+	core.TrashedNote
+}
+
+func (t trashItem) Title() string {
+	return t.OriginalPath
+}
+
+func (t trashItem) Description() string {
+	return "deleted " + t.DeletedAt.Local().Format("2006-01-02 15:04")
+}
+
+func (t trashItem) FilterValue() string {
+	return t.OriginalPath
+}
+
+type Component struct {
+	width, height int
+	list          list.Model
+	keys          componentKeyMap
+	trash         core.Trash
+	maxAge        time.Duration
+
+	pending   action
+	loading   commands.Operation
+	operation commands.Operation
+}
+
+// NewComponent - the trash screen, where emptying purges the notes deleted more than maxAge ago
+func NewComponent(trash core.Trash, maxAge time.Duration) Component {
+	keys := newComponentKeyMap()
+	itemList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	itemList.Title = "Trash"
+	itemList.AdditionalFullHelpKeys = keys.getListOfBindings
+
+	return Component{
+		width:  itemList.Width(),
+		height: itemList.Height(),
+		list:   itemList,
+		keys:   keys,
+		trash:  trash,
+		maxAge: maxAge,
+	}
+}
+
+func (tc *Component) Init() tea.Cmd {
+	return nil
+}
+
+func (tc *Component) loadTrash() tea.Cmd {
+	ctx := tc.loading.Start()
+
+	return func() tea.Msg {
+		notes, err := tc.trash.GetTrashedNotes(ctx)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to load trash", "error", err)
+			return trashFailedMsg{err: err}
+		}
+
+		return commands.ListTrashMsg{Notes: notes}
+	}
+}
+
+func (tc *Component) BackgroundUpdate(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := theme.Style.GetFrameSize()
+
+		tc.width = msg.Width - h
+		tc.height = msg.Height - v
+
+		tc.list.SetSize(tc.width, tc.height)
+
+	case commands.ShowTrashMsg:
+		tc.pending = noAction
+		return tc.loadTrash()
+
+	case commands.ListTrashMsg:
+		items := make([]list.Item, len(msg.Notes))
+
+		for i, note := range msg.Notes {
+			items[i] = trashItem{TrashedNote: note}
+		}
+
+		tc.list.SetItems(items)
+
+	case commands.RestoreNoteMsg:
+		return tea.Batch(tc.loadTrash(), tc.list.NewStatusMessage("Restored "+msg.Note.RelativePath()))
+
+	case trashEmptiedMsg:
+		return tea.Batch(tc.loadTrash(), tc.list.NewStatusMessage(formatPurged(msg.count)))
+
+	case trashFailedMsg:
+		return tc.list.NewStatusMessage("Error: " + commands.DescribeError(msg.err))
+	}
+
+	return nil
+}
+
+func (tc *Component) ForegroundUpdate(msg tea.Msg) tea.Cmd {
+	if tc.pending != noAction {
+		return tc.confirmForegroundUpdate(msg)
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && tc.list.FilterState() != list.Filtering {
+		switch {
+		case key.Matches(keyMsg, tc.keys.restoreNote):
+			if selectedItem, ok := tc.list.SelectedItem().(trashItem); ok {
+				return tc.restoreNote(selectedItem.ID)
+			}
+			return nil
+		case key.Matches(keyMsg, tc.keys.purgeNote):
+			if _, ok := tc.list.SelectedItem().(trashItem); ok {
+				tc.pending = purgeAction
+			}
+			return nil
+		case key.Matches(keyMsg, tc.keys.emptyTrash):
+			tc.pending = emptyAction
+			return nil
+		case key.Matches(keyMsg, tc.keys.quitTrash) && tc.list.FilterState() == list.Unfiltered:
+			tc.loading.Cancel()
+			return func() tea.Msg {
+				return commands.QuitTrashMsg{}
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	tc.list, cmd = tc.list.Update(msg)
+	return cmd
+}
+
+func (tc *Component) confirmForegroundUpdate(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, tc.keys.confirm):
+		pending := tc.pending
+		tc.pending = noAction
+
+		if pending == emptyAction {
+			return tc.emptyTrash()
+		}
+		if selectedItem, ok := tc.list.SelectedItem().(trashItem); ok {
+			return tc.purgeNote(selectedItem.ID)
+		}
+	case key.Matches(keyMsg, tc.keys.cancel):
+		tc.pending = noAction
+	}
+
+	return nil
+}
+
+func (tc *Component) restoreNote(id string) tea.Cmd {
+	ctx := tc.operation.Start()
+
+	return func() tea.Msg {
+		note, err := tc.trash.RestoreNote(ctx, id)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to restore note", "error", err)
+			return trashFailedMsg{err: err}
+		}
+
+		return commands.RestoreNoteMsg{Note: note}
+	}
+}
+
+func (tc *Component) purgeNote(id string) tea.Cmd {
+	ctx := tc.operation.Start()
+
+	return func() tea.Msg {
+		err := tc.trash.PurgeNote(ctx, id)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to purge note", "error", err)
+			return trashFailedMsg{err: err}
+		}
+
+		return trashEmptiedMsg{count: 1}
+	}
+}
+
+func (tc *Component) emptyTrash() tea.Cmd {
+	ctx := tc.operation.Start()
+
+	return func() tea.Msg {
+		count, err := tc.trash.EmptyTrash(ctx, tc.maxAge)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to empty trash", "error", err)
+			return trashFailedMsg{err: err}
+		}
+
+		return trashEmptiedMsg{count: count}
+	}
+}
+
+func (tc *Component) View() string {
+	var content string
+
+	switch tc.pending {
+	case purgeAction:
+		selectedItem, _ := tc.list.SelectedItem().(trashItem)
+		content = "Delete Forever\n\nPermanently delete '" + selectedItem.OriginalPath + "'? This cannot be undone.\n\nPress y to delete, n or Esc to cancel"
+	case emptyAction:
+		content = "Empty Trash\n\nPermanently delete every note deleted more than " + formatAge(tc.maxAge) + " ago?\n\nPress y to delete, n or Esc to cancel"
+	default:
+		content = tc.list.View()
+	}
+
+	return theme.Style.Width(tc.width).Height(tc.height).Render(content)
+}
+
+func formatPurged(count int) string {
+	if count == 1 {
+		return "Deleted 1 note forever"
+	}
+	return fmt.Sprintf("Deleted %d notes forever", count)
+}
+
+func formatAge(age time.Duration) string {
+	if age >= 24*time.Hour && age%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%d days", int(age/(24*time.Hour)))
+	}
+	return age.String()
+}
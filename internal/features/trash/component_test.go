@@ -0,0 +1,180 @@
+package trash
+
+import (
+	"context"
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	"errors"
+	tea "github.com/charmbracelet/bubbletea"
+	"testing"
+	"time"
+)
+
+type mockTrash struct {
+	notes   []core.TrashedNote
+	purged  []string
+	emptied time.Duration
+	err     error
+}
+
+func (m *mockTrash) GetTrashedNotes(_ context.Context) ([]core.TrashedNote, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.notes, nil
+}
+
+func (m *mockTrash) RestoreNote(_ context.Context, id string) (core.Note, error) {
+	if m.err != nil {
+		return core.Note{}, m.err
+	}
+	for _, note := range m.notes {
+		if note.ID == id {
+			return note.Note, nil
+		}
+	}
+	return core.Note{}, errors.New("note not found")
+}
+
+func (m *mockTrash) PurgeNote(_ context.Context, id string) error {
+	m.purged = append(m.purged, id)
+	return m.err
+}
+
+func (m *mockTrash) EmptyTrash(_ context.Context, olderThan time.Duration) (int, error) {
+	m.emptied = olderThan
+	return len(m.notes), m.err
+}
+
+func newTrashedNote(id, path string) core.TrashedNote {
+	return core.TrashedNote{ID: id, OriginalPath: path, DeletedAt: time.Now(), Note: core.NewNote(path, "")}
+}
+
+func TestNewTrashComponent(t *testing.T) {
+	mockTrash := &mockTrash{}
+	component := NewComponent(mockTrash, time.Hour)
+
+	if component.trash != mockTrash {
+		t.Error("Expected trash to be set correctly")
+	}
+
+	if component.maxAge != time.Hour {
+		t.Errorf("Expected max age to be 1h, got %s", component.maxAge)
+	}
+}
+
+func TestTrashComponentBackgroundUpdate(t *testing.T) {
+	t.Run("ShowTrashMsg loads the trashed notes", func(t *testing.T) {
+		mockTrash := &mockTrash{notes: []core.TrashedNote{newTrashedNote("1", "old.md")}}
+		component := NewComponent(mockTrash, time.Hour)
+
+		cmd := component.BackgroundUpdate(commands.ShowTrashMsg{})
+		if cmd == nil {
+			t.Fatal("Expected BackgroundUpdate to return a command for ShowTrashMsg")
+		}
+
+		listMsg, ok := cmd().(commands.ListTrashMsg)
+		if !ok {
+			t.Fatal("Expected ListTrashMsg from ShowTrashMsg command")
+		}
+
+		component.BackgroundUpdate(listMsg)
+
+		if len(component.list.Items()) != 1 {
+			t.Errorf("Expected 1 item in list, got %d", len(component.list.Items()))
+		}
+	})
+}
+
+func TestTrashComponentForegroundUpdate(t *testing.T) {
+	t.Run("'r' key restores the selected note", func(t *testing.T) {
+		mockTrash := &mockTrash{notes: []core.TrashedNote{newTrashedNote("1", "old.md")}}
+		component := NewComponent(mockTrash, time.Hour)
+		component.BackgroundUpdate(commands.ListTrashMsg{Notes: mockTrash.notes})
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for 'r' key")
+		}
+
+		restoreMsg, ok := cmd().(commands.RestoreNoteMsg)
+		if !ok {
+			t.Fatal("Expected RestoreNoteMsg from 'r' key command")
+		}
+
+		if restoreMsg.Note.Title() != "old" {
+			t.Errorf("Expected restored note 'old', got '%s'", restoreMsg.Note.Title())
+		}
+	})
+
+	t.Run("'p' key purges only after confirmation", func(t *testing.T) {
+		mockTrash := &mockTrash{notes: []core.TrashedNote{newTrashedNote("1", "old.md")}}
+		component := NewComponent(mockTrash, time.Hour)
+		component.BackgroundUpdate(commands.ListTrashMsg{Notes: mockTrash.notes})
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+		if cmd != nil {
+			t.Error("Expected no command before confirming")
+		}
+
+		if component.pending != purgeAction {
+			t.Fatal("Expected purge to wait for confirmation")
+		}
+
+		cmd = component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for 'y' key")
+		}
+
+		cmd()
+
+		if len(mockTrash.purged) != 1 || mockTrash.purged[0] != "1" {
+			t.Errorf("Expected note '1' to be purged, got %v", mockTrash.purged)
+		}
+	})
+
+	t.Run("'e' key empties the trash with the configured age", func(t *testing.T) {
+		mockTrash := &mockTrash{}
+		component := NewComponent(mockTrash, 48*time.Hour)
+
+		component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for 'y' key")
+		}
+
+		if _, ok := cmd().(trashEmptiedMsg); !ok {
+			t.Error("Expected trashEmptiedMsg from 'y' key command")
+		}
+
+		if mockTrash.emptied != 48*time.Hour {
+			t.Errorf("Expected trash to be emptied with 48h, got %s", mockTrash.emptied)
+		}
+	})
+
+	t.Run("'n' key cancels a pending action", func(t *testing.T) {
+		mockTrash := &mockTrash{}
+		component := NewComponent(mockTrash, time.Hour)
+
+		component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+		component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+
+		if component.pending != noAction {
+			t.Error("Expected pending action to be cancelled")
+		}
+	})
+
+	t.Run("Escape key creates QuitTrashMsg", func(t *testing.T) {
+		mockTrash := &mockTrash{}
+		component := NewComponent(mockTrash, time.Hour)
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEsc})
+		if cmd == nil {
+			t.Fatal("Expected ForegroundUpdate to return a command for Escape key")
+		}
+
+		if _, ok := cmd().(commands.QuitTrashMsg); !ok {
+			t.Error("Expected QuitTrashMsg from Escape key command")
+		}
+	})
+}
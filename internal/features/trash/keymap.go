@@ -0,0 +1,52 @@
+package trash
+
+import "github.com/charmbracelet/bubbles/key"
+
+type componentKeyMap struct {
+	restoreNote key.Binding
+	purgeNote   key.Binding
+	emptyTrash  key.Binding
+	confirm     key.Binding
+	cancel      key.Binding
+	quitTrash   key.Binding
+}
+
+func newComponentKeyMap() componentKeyMap {
+	km := componentKeyMap{
+		restoreNote: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "restore note"),
+		),
+		purgeNote: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "delete forever"),
+		),
+		emptyTrash: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "empty old notes"),
+		),
+		confirm: key.NewBinding(
+			key.WithKeys("y", "Y"),
+			key.WithHelp("y", "confirm"),
+		),
+		cancel: key.NewBinding(
+			key.WithKeys("n", "N", "esc"),
+			key.WithHelp("n", "cancel"),
+		),
+		quitTrash: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back to list note"),
+		),
+	}
+
+	return km
+}
+
+func (a componentKeyMap) getListOfBindings() []key.Binding {
+	return []key.Binding{
+		a.restoreNote,
+		a.purgeNote,
+		a.emptyTrash,
+		a.quitTrash,
+	}
+}
@@ -0,0 +1,172 @@
+package view
+
+import (
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	"elephant/internal/theme"
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"log/slog"
+	"strings"
+)
+
+// backlinksHeight - the lines the backlinks panel takes below the note, its border and title included
+const backlinksHeight = 8
+
+// backlinksMsg - the notes linking to the note at path
+type backlinksMsg struct {
+	path    string
+	entries []backlinkEntry
+}
+
+// backlinksFailedMsg - the backlinks of the note could not be loaded
+type backlinksFailedMsg struct{ err error }
+
+// backlinkEntry - a note linking to the viewed one, with the first of its links there and the line around it
+type backlinkEntry struct {
+	note    core.Note
+	link    core.Link
+	context string
+}
+
+// backlinks - the panel listing the notes linking to the viewed note
+type backlinks struct {
+	open     bool
+	entries  []backlinkEntry
+	selected int
+	err      error
+	loading  commands.Operation
+}
+
+// load - look up the notes linking to note, reading them for the lines around their links
+func (b *backlinks) load(repository core.Repository, note core.Note) tea.Cmd {
+	backlinker, ok := repository.(core.Backlinker)
+	if !ok {
+		return func() tea.Msg {
+			return backlinksFailedMsg{err: fmt.Errorf("repository %T has no link graph", repository)}
+		}
+	}
+
+	ctx := b.loading.Start()
+
+	return func() tea.Msg {
+		found, err := backlinker.Backlinks(ctx, note)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to load backlinks", "file", note.FilePath(), "error", err)
+			return backlinksFailedMsg{err: err}
+		}
+
+		entries := make([]backlinkEntry, 0, len(found))
+		for _, backlink := range found {
+			loaded, err := core.LoadNote(ctx, repository, backlink.Note)
+			if commands.IsSuperseded(err) {
+				return nil
+			}
+			if err != nil {
+				slog.Warn("failed to load backlink", "file", backlink.Note.RelativePath(), "error", err)
+				continue
+			}
+
+			link := currentLink(loaded, backlink.Links[0])
+			entries = append(entries, backlinkEntry{note: loaded, link: link, context: linkContext(loaded.FileContent(), link)})
+		}
+
+		return backlinksMsg{path: note.RelativePath(), entries: entries}
+	}
+}
+
+// update - handle the keys of the open panel, false for the keys it leaves to the note
+func (b *backlinks) update(keyMsg tea.KeyMsg, keys componentKeyMap) (tea.Cmd, bool) {
+	switch {
+	case key.Matches(keyMsg, keys.closeBacklinks):
+		b.close()
+	case key.Matches(keyMsg, keys.previousLink):
+		b.selected = max(b.selected-1, 0)
+	case key.Matches(keyMsg, keys.nextLink):
+		b.selected = max(min(b.selected+1, len(b.entries)-1), 0)
+	case key.Matches(keyMsg, keys.openLink):
+		if b.selected >= len(b.entries) {
+			return nil, true
+		}
+
+		entry := b.entries[b.selected]
+		return func() tea.Msg {
+			return commands.ViewNoteMsg{Note: entry.note, Match: entry.link.Label()}
+		}, true
+	default:
+		return nil, false
+	}
+
+	return nil, true
+}
+
+func (b *backlinks) close() {
+	b.open = false
+	b.entries = nil
+	b.err = nil
+	b.loading.Cancel()
+}
+
+func (b *backlinks) view(width int) string {
+	lines := []string{theme.PropertyKeyStyle.Render(fmt.Sprintf("Backlinks (%d)", len(b.entries)))}
+
+	rows := backlinksHeight - 2
+	switch {
+	case b.err != nil:
+		lines = append(lines, "Could not load backlinks: "+commands.DescribeError(b.err))
+	case len(b.entries) == 0:
+		lines = append(lines, "No notes link here")
+	default:
+		first := max(min(b.selected-rows/2, len(b.entries)-rows), 0)
+		for i := first; i < min(first+rows, len(b.entries)); i++ {
+			entry := b.entries[i]
+
+			title := entry.note.Title()
+			if folder := entry.note.Folder(); folder != "" {
+				title = folder + "/" + title
+			}
+
+			line := "  " + title + " — " + entry.context
+			if i == b.selected {
+				line = theme.SelectedStyle.Render("> "+title) + " — " + entry.context
+			}
+			lines = append(lines, ansi.Truncate(line, width, "…"))
+		}
+	}
+
+	return theme.PanelStyle.Width(width).Height(backlinksHeight - 1).Render(strings.Join(lines, "\n"))
+}
+
+// currentLink - link as it is found in the content of note, which may have changed since the link graph saw it
+func currentLink(note core.Note, link core.Link) core.Link {
+	for _, current := range note.Links() {
+		if current.Kind == link.Kind && current.Target == link.Target && current.Fragment == link.Fragment {
+			return current
+		}
+	}
+
+	return link
+}
+
+// linkContext - the line of content holding link, trimmed, with the link highlighted
+func linkContext(content string, link core.Link) string {
+	if link.Start < 0 || link.End > len(content) || link.Start > link.End {
+		return link.Label()
+	}
+
+	lineStart := strings.LastIndexByte(content[:link.Start], '\n') + 1
+	lineEnd := len(content)
+	if end := strings.IndexByte(content[link.End:], '\n'); end >= 0 {
+		lineEnd = link.End + end
+	}
+
+	before := strings.TrimLeft(content[lineStart:link.Start], " \t")
+	after := strings.TrimRight(content[link.End:lineEnd], " \t\r")
+
+	return before + theme.MatchStyle.Render(content[link.Start:link.End]) + after
+}
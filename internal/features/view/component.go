@@ -8,9 +8,14 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/x/ansi"
 	"log/slog"
+	"strings"
 )
 
+// lockNoteFailedMsg - the note could not be locked for editing and the view stays open to show why
+type lockNoteFailedMsg struct{ err error }
+
 type Component struct {
 	width, height int
 	markdown      viewport.Model
@@ -19,6 +24,15 @@ type Component struct {
 	repository    core.Repository
 
 	currentNote core.Note
+	locking     commands.Operation
+	err         error
+	backlinks   backlinks
+
+	// embeds, transcluding, pendingMatch - the embeds shown in the note, the expansion of them under way and the
+	// text to scroll to once it is done
+	embeds       []embedSpot
+	transcluding commands.Operation
+	pendingMatch string
 }
 
 func NewComponent(repository core.Repository) Component {
@@ -55,47 +69,116 @@ func (vc *Component) BackgroundUpdate(msg tea.Msg) tea.Cmd {
 		vc.width = msg.Width - h
 		vc.height = msg.Height - v
 
-		vc.markdown.Width = vc.width
-		vc.markdown.Height = vc.height
+		vc.resize()
 
 	case commands.ViewNoteMsg:
 		vc.currentNote = msg.Note
+		vc.err = nil
+		vc.scrollTo(vc.renderNote(), msg.Match)
 
-		content, err := vc.renderer.Render(msg.Note.FileContent())
-		if err != nil {
-			slog.Error("failed to render markdown", "error", err)
-			vc.markdown.SetContent("Could not render content.")
-			return nil
+		cmds := []tea.Cmd{vc.transclude()}
+		vc.pendingMatch = ""
+		if cmds[0] != nil {
+			vc.pendingMatch = msg.Match
+		}
+		if vc.backlinks.open {
+			vc.backlinks.selected = 0
+			cmds = append(cmds, vc.backlinks.load(vc.repository, vc.currentNote))
+		}
+		return tea.Batch(cmds...)
+
+	case transcludedMsg:
+		if msg.path == vc.currentNote.RelativePath() && msg.version == vc.currentNote.Version() {
+			content := vc.render(msg.markdown)
+			vc.embeds = locateEmbeds(content, msg.embeds)
+
+			if vc.pendingMatch != "" {
+				vc.scrollTo(content, vc.pendingMatch)
+				vc.pendingMatch = ""
+			}
 		}
 
-		vc.markdown.SetContent(content)
+	case backlinksMsg:
+		if msg.path == vc.currentNote.RelativePath() {
+			vc.backlinks.entries = msg.entries
+			vc.backlinks.selected = min(vc.backlinks.selected, max(len(msg.entries)-1, 0))
+			vc.backlinks.err = nil
+		}
+
+	case backlinksFailedMsg:
+		vc.backlinks.err = msg.err
+
+	case commands.RestoreRevisionMsg:
+		vc.currentNote = msg.Note
+		vc.renderNote()
+		return vc.transclude()
+
+	case commands.EditNoteMsg:
+		vc.err = nil
+
+	case lockNoteFailedMsg:
+		vc.err = msg.err
 
 	case commands.QuitEditNoteMsg:
 		vc.currentNote = msg.Note
+		vc.renderNote()
+		return vc.transclude()
 
-		content, err := vc.renderer.Render(msg.Note.FileContent())
-		if err != nil {
-			slog.Error("failed to render markdown", "error", err)
-			vc.markdown.SetContent("Could not render content.")
-			return nil
+	case commands.NotesChangedMsg:
+		for _, change := range msg.Changes {
+			switch {
+			case change.Kind == core.NoteUpdated && change.Note.FilePath() == vc.currentNote.FilePath(),
+				change.Kind == core.NoteRenamed && change.Previous.FilePath() == vc.currentNote.FilePath():
+				vc.currentNote = change.Note
+				vc.renderNote()
+			}
 		}
 
-		vc.markdown.SetContent(content)
+		// the notes embedded may have changed as well, so the embeds are expanded again
+		cmds := []tea.Cmd{vc.transclude()}
+		if vc.backlinks.open {
+			cmds = append(cmds, vc.backlinks.load(vc.repository, vc.currentNote))
+		}
+		return tea.Batch(cmds...)
 	}
 
 	return nil
 }
 
 func (vc *Component) ForegroundUpdate(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && vc.backlinks.open {
+		if cmd, handled := vc.backlinks.update(keyMsg, vc.keys); handled {
+			vc.resize()
+			return cmd
+		}
+	}
+
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		switch {
+		case key.Matches(keyMsg, vc.keys.toggleBacklinks):
+			vc.backlinks.open = true
+			vc.backlinks.selected = 0
+			vc.resize()
+			return vc.backlinks.load(vc.repository, vc.currentNote)
 		case key.Matches(keyMsg, vc.keys.quitViewNote):
+			vc.locking.Cancel()
 			return func() tea.Msg {
 				return commands.QuitViewNoteMsg{}
 			}
 		case key.Matches(keyMsg, vc.keys.editNote):
+			return vc.editNote()
+		case key.Matches(keyMsg, vc.keys.openEmbed):
+			embed, ok := vc.openEmbed()
+			if !ok {
+				return nil
+			}
 			return func() tea.Msg {
-				return commands.EditNoteMsg{}
+				return commands.ViewNoteMsg{Note: embed.Note, Match: embedMatch(embed)}
+			}
+		case key.Matches(keyMsg, vc.keys.showHistory):
+			note := vc.currentNote
+			return func() tea.Msg {
+				return commands.ShowHistoryMsg{Note: note}
 			}
 		}
 	}
@@ -105,7 +188,125 @@ func (vc *Component) ForegroundUpdate(msg tea.Msg) tea.Cmd {
 	return cmd
 }
 
+// resize - fit the note into the space the backlinks panel leaves, when it is open
+func (vc *Component) resize() {
+	vc.markdown.Width = vc.width
+	vc.markdown.Height = vc.height
+	if vc.backlinks.open {
+		vc.markdown.Height = max(vc.height-backlinksHeight, 0)
+	}
+}
+
+// editNote - lock the note against edits from other instances, when the repository supports it, and start editing
+func (vc *Component) editNote() tea.Cmd {
+	locker, ok := vc.repository.(core.NoteLocker)
+	if !ok {
+		return func() tea.Msg {
+			return commands.EditNoteMsg{}
+		}
+	}
+
+	note := vc.currentNote
+	ctx := vc.locking.Start()
+
+	return func() tea.Msg {
+		err := locker.LockNote(ctx, note)
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to lock note for editing", "file", note.FilePath(), "error", err)
+			return lockNoteFailedMsg{err: err}
+		}
+
+		return commands.EditNoteMsg{}
+	}
+}
+
+// renderNote - render the current note body without its embeds, which transclude expands, returning the rendered
+// content
+func (vc *Component) renderNote() string {
+	vc.embeds = nil
+	return vc.render(core.StripBlockIDs(vc.currentNote.Body()))
+}
+
+// render - render markdown as the body of the current note, showing its front matter as a separate properties block,
+// returning the rendered content
+func (vc *Component) render(markdown string) string {
+	content, err := vc.renderer.Render(markdown)
+	if err != nil {
+		slog.Error("failed to render markdown", "error", err)
+		content = "Could not render content."
+	} else if properties := renderProperties(vc.currentNote.Metadata()); properties != "" {
+		content = properties + "\n" + content
+	}
+
+	vc.markdown.SetContent(content)
+	return content
+}
+
+// scrollTo - scroll the rendered content to the first line holding match, ignoring case and falling back to its
+// first word when rendering wrapped the match across lines; the top of the note when match is empty or not found
+func (vc *Component) scrollTo(content, match string) {
+	vc.markdown.GotoTop()
+
+	words := strings.Fields(strings.ToLower(match))
+	if len(words) == 0 {
+		return
+	}
+
+	lines := strings.Split(strings.ToLower(ansi.Strip(content)), "\n")
+	for _, needle := range []string{strings.Join(words, " "), words[0]} {
+		for i, line := range lines {
+			if strings.Contains(strings.Join(strings.Fields(line), " "), needle) {
+				vc.markdown.SetYOffset(i)
+				return
+			}
+		}
+	}
+}
+
+func renderProperties(metadata core.Metadata) string {
+	if len(metadata) == 0 {
+		return ""
+	}
+
+	return theme.PropertiesStyle.Render(strings.Join(propertyLines("", metadata), "\n"))
+}
+
+// propertyLines - a line per property of metadata, with the keys of nested properties flattened as key.sub
+func propertyLines(prefix string, metadata core.Metadata) []string {
+	var lines []string
+	for _, key := range metadata.Keys() {
+		// YAML decodes nested properties into Metadata, TOML into plain maps
+		switch nested := metadata[key].(type) {
+		case core.Metadata:
+			lines = append(lines, propertyLines(prefix+key+".", nested)...)
+			continue
+		case map[string]any:
+			lines = append(lines, propertyLines(prefix+key+".", nested)...)
+			continue
+		}
+
+		value, ok := metadata.String(key)
+		if !ok {
+			value = strings.Join(metadata.Strings(key), ", ")
+		}
+
+		lines = append(lines, theme.PropertyKeyStyle.Render(prefix+key+":")+" "+value)
+	}
+
+	return lines
+}
+
 func (vc *Component) View() string {
 	markdownView := vc.markdown.View()
+	if vc.err != nil {
+		markdownView = "Could not edit note: " + commands.DescribeError(vc.err) + "\n\n" + markdownView
+	}
+	if vc.backlinks.open {
+		markdownView += "\n" + vc.backlinks.view(vc.width)
+	}
+
 	return theme.Style.Width(vc.width).Height(vc.height).Render(markdownView)
 }
@@ -1,53 +1,20 @@
 package view
 
 import (
+	"context"
 	"elephant/internal/core"
 	"elephant/internal/features/commands"
-	"errors"
+	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
+	"strings"
 	"testing"
 )
 
-type mockRepository struct {
-	notes []core.Note
-	err   error
-}
-
-func (m *mockRepository) GetAllNotes() ([]core.Note, error) {
-	if m.err != nil {
-		return nil, m.err
-	}
-	return m.notes, nil
-}
-
-func (m *mockRepository) GetNoteByTitle(title string) (core.Note, error) {
-	if m.err != nil {
-		return core.Note{}, m.err
-	}
-	for _, note := range m.notes {
-		if note.Title() == title {
-			return note, nil
-		}
-	}
-	return core.Note{}, errors.New("note not found")
-}
-
-func (m *mockRepository) SaveNote(_ core.Note) error {
-	return m.err
-}
-
-func (m *mockRepository) CreateEmptyNote(filename string) (core.Note, error) {
-	if m.err != nil {
-		return core.Note{}, m.err
-	}
-	return core.NewNote(filename+".md", ""), nil
-}
-
 func TestNewViewComponent(t *testing.T) {
-	mockRepo := &mockRepository{}
-	component := NewComponent(mockRepo)
+	repo := core.NewMemoryRepository()
+	component := NewComponent(repo)
 
-	if component.repository != mockRepo {
+	if component.repository != repo {
 		t.Error("Expected repository to be set correctly")
 	}
 
@@ -62,8 +29,8 @@ func TestNewViewComponent(t *testing.T) {
 
 func TestViewComponentBackgroundUpdate(t *testing.T) {
 	t.Run("ViewNoteMsg sets current note and renders content", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		note := core.NewNote("test.md", "# Test Note\nThis is test content")
 		msg := commands.ViewNoteMsg{Note: note}
@@ -84,8 +51,8 @@ func TestViewComponentBackgroundUpdate(t *testing.T) {
 	})
 
 	t.Run("QuitEditNoteMsg updates current note and re-renders content", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		originalNote := core.NewNote("test.md", "# Original Content\nOriginal text")
 		viewMsg := commands.ViewNoteMsg{Note: originalNote}
@@ -105,9 +72,29 @@ func TestViewComponentBackgroundUpdate(t *testing.T) {
 		}
 	})
 
+	t.Run("NotesChangedMsg re-renders the viewed note when it changed on disk", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+		component.BackgroundUpdate(tea.WindowSizeMsg{Width: 80, Height: 20})
+		component.BackgroundUpdate(commands.ViewNoteMsg{Note: core.NewNote("test.md", "Original text")})
+
+		component.BackgroundUpdate(commands.NotesChangedMsg{Changes: []core.NoteChange{
+			{Kind: core.NoteUpdated, Note: core.NewNote("other.md", "Other text")},
+			{Kind: core.NoteUpdated, Note: core.NewNote("test.md", "Reloaded text")},
+		}})
+
+		if component.currentNote.FileContent() != "Reloaded text" {
+			t.Errorf("Expected the viewed note to be reloaded, got '%s'", component.currentNote.FileContent())
+		}
+
+		if !strings.Contains(component.markdown.View(), "Reloaded text") {
+			t.Error("Expected the reloaded content to be rendered")
+		}
+	})
+
 	t.Run("handles markdown rendering errors gracefully", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		note := core.NewNote("test.md", "```\nunclosed code block")
 		msg := commands.ViewNoteMsg{Note: note}
@@ -124,10 +111,159 @@ func TestViewComponentBackgroundUpdate(t *testing.T) {
 	})
 }
 
+func TestViewComponentScrollsToMatch(t *testing.T) {
+	component := NewComponent(core.NewMemoryRepository())
+	component.BackgroundUpdate(tea.WindowSizeMsg{Width: 80, Height: 5})
+
+	var content strings.Builder
+	for i := range 40 {
+		fmt.Fprintf(&content, "Paragraph %d of filler.\n\n", i)
+	}
+	content.WriteString("The **hidden** treasure is here.\n\n")
+	for i := range 10 {
+		fmt.Fprintf(&content, "More filler %d.\n\n", i)
+	}
+	note := core.NewNote("long.md", content.String())
+
+	component.BackgroundUpdate(commands.ViewNoteMsg{Note: note, Match: "hidden treasure"})
+	if component.markdown.YOffset == 0 || !strings.Contains(component.markdown.View(), "treasure") {
+		t.Errorf("Expected the viewport to show the match, got offset %d:\n%s", component.markdown.YOffset, component.markdown.View())
+	}
+
+	component.BackgroundUpdate(commands.ViewNoteMsg{Note: note})
+	if component.markdown.YOffset != 0 {
+		t.Errorf("Expected a note opened without a match to start at the top, got offset %d", component.markdown.YOffset)
+	}
+}
+
+func TestViewComponentBacklinks(t *testing.T) {
+	ctx := context.Background()
+	graph := core.NewLinkGraph()
+	repository := core.ChainRepository(core.NewMemoryRepository(
+		core.NewNote("target.md", "# Target"),
+		core.NewNote("first.md", "# First\nIt builds on [[Target]] a lot."),
+		core.NewNote("second.md", "# Second\nSee [the target](target.md)."),
+	), core.WithLinkGraph(graph))
+	if err := graph.Build(ctx, repository); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	target, _ := repository.GetNoteByTitle(ctx, "target")
+
+	component := NewComponent(repository)
+	component.BackgroundUpdate(tea.WindowSizeMsg{Width: 80, Height: 30})
+	component.BackgroundUpdate(commands.ViewNoteMsg{Note: target})
+
+	component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	if component.backlinks.open {
+		t.Fatal("Expected b to be left to the viewport to page up")
+	}
+
+	cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("B")})
+	if cmd == nil {
+		t.Fatal("Expected B to load the backlinks")
+	}
+	component.BackgroundUpdate(cmd())
+
+	if len(component.backlinks.entries) != 2 || component.markdown.Height != 30-backlinksHeight {
+		t.Fatalf("Expected the panel with 2 backlinks below the note, got %d with height %d", len(component.backlinks.entries), component.markdown.Height)
+	}
+	if view := component.View(); !strings.Contains(view, "Backlinks (2)") || !strings.Contains(view, "It builds on") {
+		t.Errorf("Expected the panel to list the backlinks with their context, got:\n%s", view)
+	}
+
+	component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyDown})
+	cmd = component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected enter to open the selected backlink")
+	}
+	viewMsg, ok := cmd().(commands.ViewNoteMsg)
+	if !ok || viewMsg.Note.Title() != "second" || viewMsg.Match != "the target" || !viewMsg.Note.Loaded() {
+		t.Fatalf("Expected the second note opened at its link, got %+v", viewMsg)
+	}
+
+	if cmd := component.BackgroundUpdate(viewMsg); cmd == nil {
+		t.Error("Expected the open panel to load the backlinks of the opened note")
+	}
+
+	component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEsc})
+	if component.backlinks.open || component.markdown.Height != 30 {
+		t.Errorf("Expected escape to close the panel before leaving the note")
+	}
+}
+
+type mockLockingRepository struct {
+	*core.MemoryRepository
+	lockErr error
+	locked  []core.Note
+}
+
+func (m *mockLockingRepository) LockNote(_ context.Context, note core.Note) error {
+	if m.lockErr != nil {
+		return m.lockErr
+	}
+	m.locked = append(m.locked, note)
+	return nil
+}
+
+func (m *mockLockingRepository) UnlockNote(_ context.Context, _ core.Note) error {
+	return nil
+}
+
+func TestViewComponentLocking(t *testing.T) {
+	t.Run("editing locks the note first", func(t *testing.T) {
+		repo := &mockLockingRepository{MemoryRepository: core.NewMemoryRepository()}
+		component := NewComponent(repo)
+		component.BackgroundUpdate(commands.ViewNoteMsg{Note: core.NewNote("test.md", "content")})
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEnter})
+		if _, ok := cmd().(commands.EditNoteMsg); !ok {
+			t.Fatal("Expected EditNoteMsg once the note is locked")
+		}
+
+		if len(repo.locked) != 1 || repo.locked[0].Title() != "test" {
+			t.Errorf("Expected the viewed note to be locked, got %v", repo.locked)
+		}
+	})
+
+	t.Run("a note locked elsewhere stays in the view with the reason", func(t *testing.T) {
+		repo := &mockLockingRepository{MemoryRepository: core.NewMemoryRepository(), lockErr: &core.LockedError{Path: "test.md", Owner: core.LockInfo{PID: 42, Host: "laptop"}}}
+		component := NewComponent(repo)
+		component.BackgroundUpdate(tea.WindowSizeMsg{Width: 120, Height: 20})
+		component.BackgroundUpdate(commands.ViewNoteMsg{Note: core.NewNote("test.md", "content")})
+
+		msg := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyEnter})()
+		if _, ok := msg.(commands.EditNoteMsg); ok {
+			t.Fatal("Expected no EditNoteMsg for a note locked elsewhere")
+		}
+
+		component.BackgroundUpdate(msg)
+
+		if !strings.Contains(component.View(), "pid 42 on laptop") {
+			t.Errorf("Expected the lock owner to be shown, got '%s'", component.View())
+		}
+	})
+}
+
 func TestViewComponentForegroundUpdate(t *testing.T) {
+	t.Run("'H' key shows the history of the note", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+		component.BackgroundUpdate(commands.ViewNoteMsg{Note: core.NewNote("test.md", "content")})
+
+		cmd := component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'H'}})
+		if cmd == nil {
+			t.Fatal("Expected a command for 'H' key")
+		}
+
+		msg, ok := cmd().(commands.ShowHistoryMsg)
+		if !ok || msg.Note.Title() != "test" {
+			t.Errorf("Expected ShowHistoryMsg for the viewed note, got %v", msg)
+		}
+	})
+
 	t.Run("Escape key creates QuitViewNoteMsg", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		keyMsg := tea.KeyMsg{Type: tea.KeyEsc}
 		cmd := component.ForegroundUpdate(keyMsg)
@@ -144,8 +280,8 @@ func TestViewComponentForegroundUpdate(t *testing.T) {
 	})
 
 	t.Run("Enter key creates EditNoteMsg", func(t *testing.T) {
-		mockRepo := &mockRepository{}
-		component := NewComponent(mockRepo)
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
 
 		keyMsg := tea.KeyMsg{Type: tea.KeyEnter}
 		cmd := component.ForegroundUpdate(keyMsg)
@@ -161,3 +297,75 @@ func TestViewComponentForegroundUpdate(t *testing.T) {
 		}
 	})
 }
+
+func TestViewComponentFrontMatter(t *testing.T) {
+	t.Run("front matter is shown as properties instead of markdown", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+		component.BackgroundUpdate(tea.WindowSizeMsg{Width: 80, Height: 40})
+
+		note := core.NewNote("test.md", "---\nauthor: Karen\n---\n# Test Note")
+		component.BackgroundUpdate(commands.ViewNoteMsg{Note: note})
+
+		view := component.View()
+		if !strings.Contains(view, "author:") || !strings.Contains(view, "Karen") {
+			t.Errorf("Expected view to contain the author property, got '%s'", view)
+		}
+
+		if strings.Contains(view, "---") {
+			t.Error("Expected front matter delimiters to be hidden from the render")
+		}
+	})
+
+	t.Run("nested properties are shown under their flattened keys", func(t *testing.T) {
+		repo := core.NewMemoryRepository()
+		component := NewComponent(repo)
+		component.BackgroundUpdate(tea.WindowSizeMsg{Width: 80, Height: 40})
+
+		note := core.NewNote("test.md", "---\nreview:\n  by: Karen\n  status:\n    state: done\n---\n# Test Note")
+		component.BackgroundUpdate(commands.ViewNoteMsg{Note: note})
+
+		view := component.View()
+		if !strings.Contains(view, "review.by: Karen") || !strings.Contains(view, "review.status.state: done") {
+			t.Errorf("Expected view to contain the nested properties, got '%s'", view)
+		}
+	})
+}
+
+func TestViewComponentEmbeds(t *testing.T) {
+	ctx := context.Background()
+	graph := core.NewLinkGraph()
+	repository := core.ChainRepository(core.NewMemoryRepository(
+		core.NewNote("index.md", "# Index\nBefore the embed.\n\n![[Plan#Goals]]\n\nAfter the embed. ^after"),
+		core.NewNote("Plan.md", "# Plan\nIntro.\n## Goals\nShip the elephant.\n## Risks\nNone."),
+	), core.WithLinkGraph(graph))
+	if err := graph.Build(ctx, repository); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	index, _ := repository.GetNoteByTitle(ctx, "index")
+
+	component := NewComponent(repository)
+	component.BackgroundUpdate(tea.WindowSizeMsg{Width: 80, Height: 40})
+
+	cmd := component.BackgroundUpdate(commands.ViewNoteMsg{Note: index})
+	if cmd == nil {
+		t.Fatal("Expected the embeds of the note to be expanded")
+	}
+	component.BackgroundUpdate(cmd())
+
+	view := component.View()
+	if !strings.Contains(view, "↳ Plan › Goals") || !strings.Contains(view, "Ship the elephant.") || strings.Contains(view, "None.") {
+		t.Errorf("Expected the Goals section to be embedded, got:\n%s", view)
+	}
+	if strings.Contains(view, "^after") {
+		t.Errorf("Expected the block id to be hidden, got:\n%s", view)
+	}
+
+	cmd = component.ForegroundUpdate(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	if cmd == nil {
+		t.Fatal("Expected o to open the embed on screen")
+	}
+	if viewMsg, ok := cmd().(commands.ViewNoteMsg); !ok || viewMsg.Note.Title() != "Plan" || viewMsg.Match != "Goals" {
+		t.Errorf("Expected the embedded note opened at its section, got %+v", viewMsg)
+	}
+}
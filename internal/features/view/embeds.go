@@ -0,0 +1,159 @@
+package view
+
+import (
+	"elephant/internal/core"
+	"elephant/internal/features/commands"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// maxEmbedDepth - how many levels of embeds inside embedded notes the view follows
+const maxEmbedDepth = 4
+
+// transcludedMsg - the body of the note at path, at version, with its embeds expanded
+type transcludedMsg struct {
+	path, version string
+	markdown      string
+	embeds        []core.Embed
+}
+
+// embedSpot - an embed shown in the rendered note and the line its heading was rendered on, -1 when not found
+type embedSpot struct {
+	embed core.Embed
+	line  int
+}
+
+// transclude - expand the embeds of the current note, if it has any, in the background
+func (vc *Component) transclude() tea.Cmd {
+	note := vc.currentNote
+
+	hasEmbeds := false
+	for _, link := range note.Links() {
+		hasEmbeds = hasEmbeds || link.Embed
+	}
+	if !hasEmbeds {
+		vc.transcluding.Cancel()
+		return nil
+	}
+
+	repository := vc.repository
+	ctx := vc.transcluding.Start()
+
+	return func() tea.Msg {
+		var embeds []core.Embed
+		markdown, err := core.Transclude(ctx, repository, note, maxEmbedDepth, func(embed core.Embed) string {
+			embeds = append(embeds, embed)
+			return quoteEmbed(embed)
+		})
+		if commands.IsSuperseded(err) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("failed to expand embeds", "file", note.FilePath(), "error", err)
+			return nil
+		}
+
+		return transcludedMsg{path: note.RelativePath(), version: note.Version(), markdown: markdown, embeds: embeds}
+	}
+}
+
+// locateEmbeds - the lines of content the headings of embeds were rendered on, ordered by line; embeds come nested
+// ones first, so each takes the first line with its heading not taken yet
+func locateEmbeds(content string, embeds []core.Embed) []embedSpot {
+	lines := strings.Split(ansi.Strip(content), "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+
+	taken := map[int]bool{}
+	spots := make([]embedSpot, 0, len(embeds))
+	for _, embed := range embeds {
+		spot := embedSpot{embed: embed, line: -1}
+		heading := strings.Join(strings.Fields(embedHeading(embed)), " ")
+		for i, line := range lines {
+			if !taken[i] && strings.Contains(line, heading) {
+				spot.line = i
+				taken[i] = true
+				break
+			}
+		}
+		spots = append(spots, spot)
+	}
+
+	sort.SliceStable(spots, func(i, j int) bool { return spots[i].line < spots[j].line })
+	return spots
+}
+
+// embedHeading - the line heading an embedded part, naming where it comes from
+func embedHeading(embed core.Embed) string {
+	label := embed.Link.Target
+	if label == "" && embed.Note.RelativePath() != "" {
+		label = embed.Note.Title()
+	}
+	if embed.Link.Fragment != "" {
+		label += " › " + strings.TrimPrefix(embed.Link.Fragment, "^")
+	}
+
+	return "↳ " + strings.TrimSpace(label)
+}
+
+// quoteEmbed - embed as a block quote headed by where it comes from, which quotes nested embeds once more
+func quoteEmbed(embed core.Embed) string {
+	if embed.Err != nil {
+		return "\n\n> *" + embedHeading(embed) + " — could not embed: " + commands.DescribeError(embed.Err) + "*\n\n"
+	}
+
+	lines := []string{"**" + embedHeading(embed) + "**", ""}
+	lines = append(lines, strings.Split(strings.TrimSpace(embed.Content), "\n")...)
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+
+	return "\n\n" + strings.Join(lines, "\n") + "\n\n"
+}
+
+// embedMatch - the text to scroll to when opening embed at its source: the heading it shows, or the first words of
+// its block
+func embedMatch(embed core.Embed) string {
+	fragment := embed.Link.Fragment
+	if fragment != "" && !strings.HasPrefix(fragment, "^") {
+		return fragment
+	}
+	if fragment == "" {
+		return ""
+	}
+
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(embed.Content), "\n")
+	words := strings.Fields(strings.TrimLeft(firstLine, "-*+>#[] \t"))
+
+	return strings.Join(words[:min(len(words), 6)], " ")
+}
+
+// openEmbed - the embed on screen, or the last one above it when none is, to open at its source
+func (vc *Component) openEmbed() (core.Embed, bool) {
+	top := vc.markdown.YOffset
+	bottom := top + vc.markdown.Height
+
+	var above *embedSpot
+	for i := range vc.embeds {
+		spot := &vc.embeds[i]
+		if spot.line < 0 || spot.embed.Err != nil {
+			continue
+		}
+		if spot.line >= top && spot.line < bottom {
+			return spot.embed, true
+		}
+		if spot.line < top {
+			above = spot
+		}
+	}
+
+	if above == nil {
+		return core.Embed{}, false
+	}
+
+	return above.embed, true
+}
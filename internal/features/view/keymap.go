@@ -6,7 +6,15 @@ import (
 
 type componentKeyMap struct {
 	editNote     key.Binding
+	showHistory  key.Binding
+	openEmbed    key.Binding
 	quitViewNote key.Binding
+
+	toggleBacklinks key.Binding
+	previousLink    key.Binding
+	nextLink        key.Binding
+	openLink        key.Binding
+	closeBacklinks  key.Binding
 }
 
 func newComponentKeyMap() componentKeyMap {
@@ -15,10 +23,38 @@ func newComponentKeyMap() componentKeyMap {
 			key.WithKeys("enter", " "),
 			key.WithHelp("enter/space", "edit note"),
 		),
+		showHistory: key.NewBinding(
+			key.WithKeys("H"),
+			key.WithHelp("H", "note history"),
+		),
+		openEmbed: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "open embed"),
+		),
 		quitViewNote: key.NewBinding(
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "back to list note"),
 		),
+		toggleBacklinks: key.NewBinding(
+			key.WithKeys("B"),
+			key.WithHelp("B", "backlinks"),
+		),
+		previousLink: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "previous backlink"),
+		),
+		nextLink: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "next backlink"),
+		),
+		openLink: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "open backlink"),
+		),
+		closeBacklinks: key.NewBinding(
+			key.WithKeys("B", "esc"),
+			key.WithHelp("B/esc", "close backlinks"),
+		),
 	}
 
 	return km
@@ -0,0 +1,24 @@
+package theme
+
+import (
+	"elephant/internal/core"
+	"strings"
+)
+
+// RenderDiff - a line diff with inserted and deleted lines marked and coloured
+func RenderDiff(diff []core.DiffLine) string {
+	var lines []string
+
+	for _, line := range diff {
+		switch line.Kind {
+		case core.DiffInsert:
+			lines = append(lines, DiffInsertStyle.Render("+ "+line.Text))
+		case core.DiffDelete:
+			lines = append(lines, DiffDeleteStyle.Render("- "+line.Text))
+		default:
+			lines = append(lines, "  "+line.Text)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
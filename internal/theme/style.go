@@ -3,3 +3,37 @@ package theme
 import "github.com/charmbracelet/lipgloss"
 
 var Style = lipgloss.NewStyle()
+
+// PropertiesStyle - the box around the front matter properties of a note
+var PropertiesStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"}).
+	Padding(0, 1).
+	MarginLeft(2)
+
+// PropertyKeyStyle - the name of a single front matter property
+var PropertyKeyStyle = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(lipgloss.AdaptiveColor{Light: "#EE6FF8", Dark: "#EE6FF8"})
+
+// DiffInsertStyle - a line added in a diff
+var DiffInsertStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#00875F", Dark: "#5FD787"})
+
+// DiffDeleteStyle - a line removed in a diff
+var DiffDeleteStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#D70000", Dark: "#FF5F5F"})
+
+// MatchStyle - the words matching a search query in a result snippet
+var MatchStyle = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(lipgloss.AdaptiveColor{Light: "#1A1A1A", Dark: "#1A1A1A"}).
+	Background(lipgloss.AdaptiveColor{Light: "#FFD75F", Dark: "#FFD75F"})
+
+// PanelStyle - a panel shown below the note, such as its backlinks
+var PanelStyle = lipgloss.NewStyle().
+	Border(lipgloss.NormalBorder(), true, false, false, false).
+	BorderForeground(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"})
+
+// SelectedStyle - the selected entry of a panel
+var SelectedStyle = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(lipgloss.AdaptiveColor{Light: "#EE6FF8", Dark: "#EE6FF8"})